@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/latoulicious/siresto-backend/internal/config"
+	"github.com/latoulicious/siresto-backend/internal/service"
+)
+
+func main() {
+	// Parse command flags
+	dryRun := flag.Bool("dry-run", false, "List orphaned images without deleting them")
+	minAge := flag.Duration("min-age", 24*time.Hour, "Skip images uploaded more recently than this")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// Connect to database
+	db, err := config.NewGormDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get sql.DB instance: %v", err)
+	}
+	defer sqlDB.Close()
+
+	// Connect to file storage
+	uploader, err := config.NewUploaderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	gc := &service.ImageGCService{
+		DB:       db,
+		Uploader: uploader,
+		MinAge:   *minAge,
+	}
+
+	result, err := gc.Run(*dryRun)
+	if err != nil {
+		log.Fatalf("Image garbage collection failed: %v", err)
+	}
+
+	for _, object := range result.Orphaned {
+		if *dryRun {
+			log.Printf("Would delete %s (%d bytes)", object.Key, object.Size)
+		} else if err, failed := result.Failed[object.Key]; failed {
+			log.Printf("Failed to delete %s: %v", object.Key, err)
+		} else {
+			log.Printf("Deleted %s (%d bytes)", object.Key, object.Size)
+		}
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d of %d images are orphaned", len(result.Orphaned), result.Scanned)
+		return
+	}
+	log.Printf("Deleted %d of %d orphaned images (%d scanned)", result.Deleted, len(result.Orphaned), result.Scanned)
+	if len(result.Failed) > 0 {
+		log.Fatalf("%d images could not be deleted", len(result.Failed))
+	}
+}
@@ -1,13 +1,39 @@
 package main
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
 )
 
 func main() {
+	alg := flag.String("alg", jwt.AlgHS256, "signing algorithm: HS256 for a shared secret, RS256 or EdDSA for a keypair")
+	bits := flag.Int("bits", 3072, "RSA key size for RS256")
+	out := flag.String("out", "keys", "directory RS256 and EdDSA keypairs are written to")
+	flag.Parse()
+
+	switch *alg {
+	case jwt.AlgHS256:
+		generateSecret()
+	case jwt.AlgRS256, jwt.AlgEdDSA:
+		generateKeypair(*alg, *bits, *out)
+	default:
+		log.Fatalf("Unsupported algorithm %q, use %s, %s or %s", *alg, jwt.AlgHS256, jwt.AlgRS256, jwt.AlgEdDSA)
+	}
+}
+
+func generateSecret() {
 	// Generate 32 random bytes (256 bits)
 	bytes := make([]byte, 32)
 	_, err := rand.Read(bytes)
@@ -25,3 +51,55 @@ func main() {
 	fmt.Println("Add this to your .env file as:")
 	fmt.Println("JWT_SECRET_KEY=" + key)
 }
+
+// generateKeypair writes <kid>.key and <kid>.pub, named after the key ID tokens will carry
+func generateKeypair(alg string, bits int, dir string) {
+	var private crypto.Signer
+	var err error
+	if alg == jwt.AlgRS256 {
+		private, err = rsa.GenerateKey(rand.Reader, bits)
+	} else {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		log.Fatal("Error generating key:", err)
+	}
+
+	keyID, err := jwt.KeyID(private.Public())
+	if err != nil {
+		log.Fatal("Error deriving key ID:", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		log.Fatal("Error encoding private key:", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		log.Fatal("Error encoding public key:", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		log.Fatal("Error creating key directory:", err)
+	}
+	privatePath := filepath.Join(dir, keyID+".key")
+	publicPath := filepath.Join(dir, keyID+".pub")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		log.Fatal("Error writing private key:", err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644); err != nil {
+		log.Fatal("Error writing public key:", err)
+	}
+
+	fmt.Printf("Generated %s keypair with key ID %s\n", alg, keyID)
+	fmt.Println("-------------------------")
+	fmt.Println("Private key: " + privatePath)
+	fmt.Println("Public key:  " + publicPath)
+	fmt.Println("-------------------------")
+	fmt.Println("Sign with it by adding this to your .env file:")
+	fmt.Println("JWT_SIGNING_ALG=" + alg)
+	fmt.Println("JWT_PRIVATE_KEY_FILE=" + privatePath)
+	fmt.Println()
+	fmt.Println("When rotating, add the public key of the previous signing key to JWT_PUBLIC_KEY_FILES")
+	fmt.Println("until the tokens it signed have expired.")
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,6 +14,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/latoulicious/siresto-backend/internal/config"
 	"github.com/latoulicious/siresto-backend/internal/routes"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
 	"github.com/latoulicious/siresto-backend/pkg/logger"
 	"github.com/latoulicious/siresto-backend/pkg/logutil"
 )
@@ -44,14 +46,40 @@ func main() {
 	}
 	defer sqlDB.Close()
 
-	// Init logger persister and logger
-	persister := logger.NewLogServicePersister(db)
+	// Init logger persister and logger; logs are written to the database in batches
+	// by a background worker, which is flushed on shutdown
+	batchConfig, err := config.NewLogBatchConfigFromEnv()
+	if err != nil {
+		logger.NewLogger(nil).LogError("Invalid log batching settings, using defaults", logutil.MainCall("init", "logger", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		batchConfig = logger.DefaultBatchConfig
+	}
+	persister := logger.NewBatchPersister(db, batchConfig)
 	appLogger := logger.NewLogger(persister)
 
 	appLogger.LogInfo("Connected to DB successfully", logutil.MainCall("connect", "database", nil))
 
-	// Setup Fiber app
-	app := fiber.New()
+	// Refuse to start with signing keys that cannot issue tokens
+	if err := jwt.CheckKeys(); err != nil {
+		appLogger.LogError("Invalid JWT signing keys", logutil.MainCall("init", "jwt", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		panic("Invalid JWT signing keys: " + err.Error())
+	}
+
+	// Setup Fiber app; the body limit leaves room for product image uploads plus form fields
+	fiberConfig := fiber.Config{
+		BodyLimit: 10 * 1024 * 1024,
+	}
+	// Login lockouts are kept per client IP, which must not be the reverse proxy's
+	if err := config.ApplyProxyConfigFromEnv(&fiberConfig); err != nil {
+		appLogger.LogError("Invalid proxy settings", logutil.MainCall("init", "server", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		panic("Invalid proxy settings: " + err.Error())
+	}
+	app := fiber.New(fiberConfig)
 
 	// Load allowed origins from env
 	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
@@ -67,7 +95,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Terminal-Key, X-API-Key, X-Request-ID",
 		AllowCredentials: true,
 	}))
 
@@ -80,34 +108,53 @@ func main() {
 		port = "3000"
 	}
 
-	// Start Fiber in goroutine
+	// Start Fiber in goroutine; a failure is handed to the main goroutine, so the
+	// logs explaining it are flushed like on a normal shutdown
+	serverErr := make(chan error, 1)
 	go func() {
 		appLogger.LogInfo("Server starting...", logutil.MainCall("start", "server", map[string]interface{}{
 			"port": port,
 		}))
 		if err := app.Listen(":" + port); err != nil {
-			appLogger.LogError("Failed to start server", logutil.MainCall("start", "server", map[string]interface{}{
-				"error": err.Error(),
-			}))
-			os.Exit(1)
+			serverErr <- err
 		}
 	}()
 
 	// Graceful shutdown on interrupt signals
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
 
-	appLogger.LogInfo("Gracefully shutting down server...", logutil.MainCall("shutdown", "server", nil))
+	failed := false
+	select {
+	case <-c:
+		appLogger.LogInfo("Gracefully shutting down server...", logutil.MainCall("shutdown", "server", nil))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 
-	if err := app.ShutdownWithContext(ctx); err != nil {
-		appLogger.LogError("Shutdown error", logutil.MainCall("shutdown", "server", map[string]interface{}{
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			appLogger.LogError("Shutdown error", logutil.MainCall("shutdown", "server", map[string]interface{}{
+				"error": err.Error(),
+			}))
+		} else {
+			appLogger.LogInfo("Server shut down successfully", logutil.MainCall("shutdown", "server", nil))
+		}
+	case err := <-serverErr:
+		appLogger.LogError("Failed to start server", logutil.MainCall("start", "server", map[string]interface{}{
 			"error": err.Error(),
 		}))
-	} else {
-		appLogger.LogInfo("Server shut down successfully", logutil.MainCall("shutdown", "server", nil))
+		failed = true
+	}
+
+	// Write the logs still buffered, including the ones above
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := persister.Close(flushCtx); err != nil {
+		log.Printf("Failed to flush logs: %v", err)
+	}
+
+	if failed {
+		sqlDB.Close()
+		os.Exit(1)
 	}
 }
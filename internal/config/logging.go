@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/pkg/logger"
+)
+
+// NewLogBatchConfigFromEnv reads LOG_BUFFER_SIZE, LOG_BATCH_SIZE, LOG_FLUSH_INTERVAL and
+// LOG_OVERFLOW (drop or block). Unset values keep the logger's defaults.
+func NewLogBatchConfigFromEnv() (logger.BatchConfig, error) {
+	cfg := logger.DefaultBatchConfig
+
+	for _, setting := range []struct {
+		name string
+		dest *int
+	}{
+		{"LOG_BUFFER_SIZE", &cfg.BufferSize},
+		{"LOG_BATCH_SIZE", &cfg.BatchSize},
+	} {
+		if raw := os.Getenv(setting.name); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q", setting.name, raw)
+			}
+			*setting.dest = value
+		}
+	}
+
+	if raw := os.Getenv("LOG_FLUSH_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("invalid LOG_FLUSH_INTERVAL %q", raw)
+		}
+		cfg.FlushInterval = interval
+	}
+
+	if raw := os.Getenv("LOG_OVERFLOW"); raw != "" {
+		switch policy := logger.OverflowPolicy(strings.ToLower(raw)); policy {
+		case logger.OverflowDrop, logger.OverflowBlock:
+			cfg.Overflow = policy
+		default:
+			return cfg, fmt.Errorf("invalid LOG_OVERFLOW %q, use %s or %s", raw, logger.OverflowDrop, logger.OverflowBlock)
+		}
+	}
+
+	return cfg, nil
+}
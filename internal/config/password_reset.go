@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const defaultPasswordResetTTL = time.Hour
+
+// PasswordResetConfig controls the links sent to users to choose a new password
+type PasswordResetConfig struct {
+	URL string        // Frontend page the reset token is appended to as ?token=
+	TTL time.Duration // How long a reset link stays valid
+}
+
+// NewPasswordResetConfigFromEnv reads PASSWORD_RESET_URL and PASSWORD_RESET_TOKEN_TTL
+func NewPasswordResetConfigFromEnv() (*PasswordResetConfig, error) {
+	cfg := &PasswordResetConfig{
+		URL: os.Getenv("PASSWORD_RESET_URL"),
+		TTL: defaultPasswordResetTTL,
+	}
+
+	if raw := os.Getenv("PASSWORD_RESET_TOKEN_TTL"); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid PASSWORD_RESET_TOKEN_TTL %q", raw)
+		}
+		cfg.TTL = ttl
+	}
+	return cfg, nil
+}
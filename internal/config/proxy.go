@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ApplyProxyConfigFromEnv makes c.IP() report the client behind a reverse proxy, so
+// per-IP limits do not treat every client as the proxy. TRUSTED_PROXIES lists the
+// proxies' addresses or CIDR ranges, comma separated; only requests from them have
+// their client IP read from PROXY_HEADER (X-Real-IP by default). The proxy must
+// overwrite that header with the address it saw. Unset, the remote address is used.
+func ApplyProxyConfigFromEnv(cfg *fiber.Config) error {
+	raw := os.Getenv("TRUSTED_PROXIES")
+	if raw == "" {
+		return nil
+	}
+
+	var proxies []string
+	for _, proxy := range strings.Split(raw, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("invalid TRUSTED_PROXIES entry %q", proxy)
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	header := os.Getenv("PROXY_HEADER")
+	if header == "" {
+		header = "X-Real-IP"
+	}
+
+	cfg.ProxyHeader = header
+	cfg.EnableTrustedProxyCheck = true
+	cfg.TrustedProxies = proxies
+	cfg.EnableIPValidation = true
+	return nil
+}
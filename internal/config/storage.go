@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/latoulicious/siresto-backend/internal/utils"
+)
+
+const (
+	defaultLocalStorageDir     = "./uploads"
+	defaultLocalStorageBaseURL = "/uploads"
+)
+
+// NewUploaderFromEnv creates the file storage backend selected by STORAGE_DRIVER:
+// "r2" (default), "local" for files on disk or "memory" for tests.
+// The local and memory backends read STORAGE_LOCAL_DIR and STORAGE_BASE_URL.
+func NewUploaderFromEnv() (utils.Uploader, error) {
+	baseURL := os.Getenv("STORAGE_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultLocalStorageBaseURL
+	}
+
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	switch driver {
+	case "", "r2":
+		uploader, err := NewR2UploaderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return uploader, nil
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = defaultLocalStorageDir
+		}
+		uploader, err := utils.NewLocalUploader(dir, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		return uploader, nil
+	case "memory":
+		return utils.NewMemoryUploader(baseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
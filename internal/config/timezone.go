@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	// Embed the timezone database so RESTAURANT_TIMEZONE resolves on minimal images
+	_ "time/tzdata"
+)
+
+// NewRestaurantLocationFromEnv returns the timezone used to evaluate menu schedules.
+// It reads an IANA name such as "Asia/Jakarta" from RESTAURANT_TIMEZONE and defaults to UTC.
+func NewRestaurantLocationFromEnv() (*time.Location, error) {
+	name := os.Getenv("RESTAURANT_TIMEZONE")
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RESTAURANT_TIMEZONE %q: %w", name, err)
+	}
+
+	return loc, nil
+}
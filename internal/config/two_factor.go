@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const defaultTOTPIssuer = "Siresto"
+
+// TwoFactorConfig controls TOTP two-factor authentication for management roles
+type TwoFactorConfig struct {
+	Issuer   string // Account label shown in authenticator apps
+	Enforced bool   // Management roles must enroll before their first full login
+}
+
+// NewTwoFactorConfigFromEnv reads TOTP_ISSUER and TOTP_ENFORCED
+func NewTwoFactorConfigFromEnv() (*TwoFactorConfig, error) {
+	cfg := &TwoFactorConfig{
+		Issuer: os.Getenv("TOTP_ISSUER"),
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = defaultTOTPIssuer
+	}
+
+	if raw := os.Getenv("TOTP_ENFORCED"); raw != "" {
+		enforced, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOTP_ENFORCED %q", raw)
+		}
+		cfg.Enforced = enforced
+	}
+	return cfg, nil
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+)
+
+// APIKey lets a machine client, such as an accounting sync, call the API on behalf of
+// the user who created it. Its scopes narrow that user's permissions; they never add to
+// them. Only a hash of the key is stored.
+type APIKey struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name       string        `gorm:"type:varchar(100);not null"`
+	Prefix     string        `gorm:"type:varchar(20);not null;index"` // Start of the key, to tell keys apart without revealing them
+	KeyHash    string        `gorm:"type:text;not null;uniqueIndex"`
+	UserID     uuid.UUID     `gorm:"type:uuid;not null;index"`
+	Scopes     db.StringList `gorm:"type:jsonb;not null"`
+	ExpiresAt  *time.Time    // Never expires when unset
+	CreatedAt  time.Time     `gorm:"default:now()"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+// BundleItem is one slot of a bundle product. It is either a fixed component
+// (ProductID set) or a choice group the customer picks one product from (Choices set).
+type BundleItem struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	BundleID  uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Name      string         `gorm:"type:text;not null"` // e.g. "Main", "Drink"
+	ProductID *uuid.UUID     `gorm:"type:uuid"`
+	Product   *Product       `gorm:"foreignKey:ProductID"`
+	Quantity  int            `gorm:"not null;default:1"`
+	Position  int            `gorm:"default:0"`
+	Choices   []BundleChoice `gorm:"foreignKey:BundleItemID;constraint:OnDelete:CASCADE"`
+}
+
+// IsChoiceGroup reports whether the customer has to pick the product for this slot
+func (b *BundleItem) IsChoiceGroup() bool {
+	return b.ProductID == nil
+}
+
+// BundleChoice is a product that can be picked for a bundle choice group
+type BundleChoice struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	BundleItemID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null"`
+	Product       *Product  `gorm:"foreignKey:ProductID"`
+	PriceModifier float64   `gorm:"type:numeric(10,2);default:0"` // Surcharge added to the bundle price
+}
+
+// OrderDetailComponent is a product a bundle order line expands into for the kitchen.
+// Quantity is the total for the whole line, i.e. already multiplied by the line quantity.
+type OrderDetailComponent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	OrderDetailID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BundleItemID  *uuid.UUID `gorm:"type:uuid"`
+	ProductID     *uuid.UUID `gorm:"type:uuid"`
+	Product       *Product   `gorm:"foreignKey:ProductID"`
+	ProductName   string     `gorm:"type:text;not null"`
+	Quantity      int        `gorm:"not null"`
+	PriceModifier float64    `gorm:"type:numeric(10,2);default:0"`
+}
@@ -2,16 +2,19 @@ package domain
 
 import (
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 	"gorm.io/gorm"
 )
 
 type Category struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name         string    `gorm:"type:text;not null"`
-	IsActive     bool      `gorm:"default:true"`
-	Position     int       `gorm:"default:0"`
-	Products     []Product `gorm:"foreignKey:CategoryID"`
-	CategoryName string    `gorm:"-" json:"-"`
+	ID           uuid.UUID                `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name         string                   `gorm:"type:text;not null"`
+	IsActive     bool                     `gorm:"default:true"`
+	Position     int                      `gorm:"default:0"`
+	Schedule     *db.AvailabilitySchedule `gorm:"type:jsonb"` // nil means always available
+	Products     []Product                `gorm:"foreignKey:CategoryID"`
+	CategoryName string                   `gorm:"-" json:"-"`
+	DeletedAt    gorm.DeletedAt           `gorm:"index" json:"-"`
 }
 
 func (c *Category) AfterFind(tx *gorm.DB) error {
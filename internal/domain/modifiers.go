@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+)
+
+// ModifierGroup is a set of additive add-ons for a product, e.g. "Toppings".
+// Unlike a Variation, several options can be picked, between MinSelect and MaxSelect.
+type ModifierGroup struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ProductID   uuid.UUID          `gorm:"type:uuid;not null;index"`
+	Product     *Product           `gorm:"foreignKey:ProductID" json:"-"`
+	Name        string             `gorm:"type:text;not null"`
+	MinSelect   int                `gorm:"default:0"`
+	MaxSelect   int                `gorm:"default:0"` // 0 means no upper limit
+	IsAvailable bool               `gorm:"default:true"`
+	Position    int                `gorm:"default:0"`
+	Options     db.ModifierOptions `gorm:"type:jsonb;not null"`
+}
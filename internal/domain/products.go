@@ -2,21 +2,28 @@ package domain
 
 import (
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 	"gorm.io/gorm"
 )
 
 type Product struct {
-	ID           uuid.UUID   `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	CategoryID   *uuid.UUID  `gorm:"type:uuid"`
-	Category     *Category   `gorm:"foreignKey:CategoryID" json:"-"` // Hide full category object
-	CategoryName string      `gorm:"-"`                              // Virtual field for JSON
-	Name         string      `gorm:"type:text;not null"`
-	Description  string      `gorm:"type:text"`
-	ImageURL     string      `gorm:"type:text"`
-	BasePrice    float64     `gorm:"type:numeric(10,2)"`
-	IsAvailable  bool        `gorm:"default:true"`
-	Position     int         `gorm:"default:0"`
-	Variations   []Variation `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ID             uuid.UUID                `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	CategoryID     *uuid.UUID               `gorm:"type:uuid"`
+	Category       *Category                `gorm:"foreignKey:CategoryID" json:"-"` // Hide full category object
+	CategoryName   string                   `gorm:"-"`                              // Virtual field for JSON
+	Name           string                   `gorm:"type:text;not null"`
+	Description    string                   `gorm:"type:text"`
+	ImageURL       string                   `gorm:"type:text"`
+	Images         db.ImageRenditions       `gorm:"type:jsonb"` // Processed renditions keyed by name; ImageURL holds the full one
+	BasePrice      float64                  `gorm:"type:numeric(10,2)"`
+	IsAvailable    bool                     `gorm:"default:true"`
+	Position       int                      `gorm:"default:0"`
+	Schedule       *db.AvailabilitySchedule `gorm:"type:jsonb"` // nil means always available
+	IsBundle       bool                     `gorm:"default:false"`
+	BundleItems    []BundleItem             `gorm:"foreignKey:BundleID;constraint:OnDelete:CASCADE"`
+	ModifierGroups []ModifierGroup          `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variations     []Variation              `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	DeletedAt      gorm.DeletedAt           `gorm:"index" json:"-"` // Past orders keep referring to deleted products
 }
 
 // AfterFind is called by GORM after loading the entity from the database
@@ -4,16 +4,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Role struct {
-	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name        string        `gorm:"unique;not null"`
-	Description string        `gorm:"type:text"`
-	Position    int           `gorm:"not null;default:100"`   // Lower numbers = higher privilege
-	IsSystem    bool          `gorm:"not null;default:false"` // System roles can't be modified by non-system roles
-	IsStaff     bool          `gorm:"not null;default:true"`  // Whether users with this role are considered staff
-	Permissions []*Permission `gorm:"many2many:role_permissions"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name              string        `gorm:"not null;uniqueIndex:idx_roles_name,where:deleted_at IS NULL"`
+	Description       string        `gorm:"type:text"`
+	Position          int           `gorm:"not null;default:100"`   // Lower numbers = higher privilege
+	IsSystem          bool          `gorm:"not null;default:false"` // System roles can't be modified by non-system roles
+	IsStaff           bool          `gorm:"not null;default:true"`  // Whether users with this role are considered staff
+	PermissionsSeeded bool          `gorm:"not null;default:false"` // Built-in role defaults are seeded only once
+	Permissions       []*Permission `gorm:"many2many:role_permissions"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"` // Deleted roles keep their permissions for a restore
 }
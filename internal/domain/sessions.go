@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is one link in the rotating chain of refresh tokens started by a login.
+// Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;not null;index"` // Shared by every token rotated from the same login
+	TokenHash string     `gorm:"type:text;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	CreatedAt time.Time  `gorm:"default:now()"`
+	RevokedAt *time.Time // Set once the token is used, logged out or revoked
+}
+
+// RevokedToken is an access token that must be rejected before it expires
+type RevokedToken struct {
+	JTI       string    `gorm:"type:text;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	ExpiresAt time.Time `gorm:"not null;index"` // The entry can be dropped once the token expires
+	CreatedAt time.Time `gorm:"default:now()"`
+}
+
+// LoginFailure counts recent failed logins for an account or a client IP
+type LoginFailure struct {
+	Key          string     `gorm:"type:varchar(320);primaryKey"` // "account:<email>" or "ip:<address>"
+	Failures     int        `gorm:"not null;default:0"`
+	LastFailedAt time.Time  `gorm:"not null"`
+	LockedUntil  *time.Time // Logins are refused until then
+}
+
+// PasswordResetToken lets a user choose a new password once, before it expires.
+// Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash string     `gorm:"type:text;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	CreatedAt time.Time  `gorm:"default:now()"`
+	UsedAt    *time.Time // Set once the token is used or superseded by a newer one
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Terminal is a shared device, such as a POS tablet, that restaurant staff sign in to with a PIN
+type Terminal struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name       string    `gorm:"type:varchar(100);not null"`
+	KeyHash    string    `gorm:"type:text;not null;uniqueIndex"` // The device key itself is only shown at registration
+	CreatedAt  time.Time `gorm:"default:now()"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time // Revoking a terminal also invalidates the tokens issued on it
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the authenticator
+// is lost. Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash  string    `gorm:"type:text;not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"default:now()"`
+	UsedAt    *time.Time
+}
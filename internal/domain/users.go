@@ -4,16 +4,27 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type User struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	Name        string    `gorm:"type:text;not null"`
-	Email       string    `gorm:"type:text;unique;not null"`
+	Email       string    `gorm:"type:text;not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL"` // Free again once the user is deleted
 	Password    string    `gorm:"type:text" json:"-"`
+	PinHash     *string   `gorm:"type:text" json:"-"` // Short numeric PIN for terminal logins, hashed like the password
 	IsStaff     bool      `gorm:"default:false"`
 	RoleID      uuid.UUID
 	Role        *Role     `gorm:"foreignKey:RoleID"`
 	CreatedAt   time.Time `gorm:"default:now()"`
 	LastLoginAt *time.Time
+	// Access tokens issued before this time are rejected, e.g. after a role change
+	SessionsRevokedAt *time.Time
+	// Two-factor authentication: the secret is stored at enrollment and required at
+	// login once TOTPEnabledAt is set
+	TOTPSecret    *string `gorm:"type:text" json:"-"`
+	TOTPEnabledAt *time.Time
+	TOTPLastStep  int64 `gorm:"not null;default:0" json:"-"` // Last accepted time step, so each code works once
+	// Deleted users are kept so the records they created still resolve, and can be restored
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -3,6 +3,7 @@ package domain
 import (
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/pkg/db"
+	"gorm.io/gorm"
 )
 
 type Variation struct {
@@ -14,4 +15,5 @@ type Variation struct {
 	IsRequired    bool                `gorm:"default:false"`
 	VariationType string              `gorm:"type:text;not null"`
 	Options       db.VariationOptions `gorm:"type:jsonb;not null"`
+	DeletedAt     gorm.DeletedAt      `gorm:"index" json:"-"` // Past orders keep referring to deleted variations
 }
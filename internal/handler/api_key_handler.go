@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type APIKeyHandler struct {
+	Service  *service.APIKeyService
+	Validate *validator.Validate
+}
+
+// CreateAPIKey issues a key acting for the caller and returns it once
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	permissions, ok := middleware.GetUserPermissions(c)
+	if !ok {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to resolve permissions", fiber.StatusInternalServerError))
+	}
+
+	created, err := h.Service.CreateAPIKey(userID, permissions, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKeyScope):
+			errInfo := utils.NewErrorInfo("INVALID_SCOPE", err.Error(), "scopes", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid API key scope", fiber.StatusBadRequest, errInfo))
+		case errors.Is(err, service.ErrInvalidAPIKeyExpiry):
+			errInfo := utils.NewErrorInfo("INVALID_EXPIRY", err.Error(), "expires_at", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid API key expiry", fiber.StatusBadRequest, errInfo))
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to create API key", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.Success("API key created successfully", created))
+}
+
+// ListAPIKeys lists the caller's keys
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	keys, err := h.Service.ListAPIKeys(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve API keys", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("API keys retrieved successfully", keys))
+}
+
+// ListAllAPIKeys lists the keys of every user
+func (h *APIKeyHandler) ListAllAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.Service.ListAllAPIKeys()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve API keys", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("API keys retrieved successfully", keys))
+}
+
+// RevokeAPIKey revokes one of the caller's keys
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	return h.revokeAPIKey(c, func(id uuid.UUID) error {
+		return h.Service.RevokeAPIKey(userID, id)
+	})
+}
+
+// RevokeAnyAPIKey revokes a key of any user
+func (h *APIKeyHandler) RevokeAnyAPIKey(c *fiber.Ctx) error {
+	return h.revokeAPIKey(c, h.Service.RevokeAnyAPIKey)
+}
+
+func (h *APIKeyHandler) revokeAPIKey(c *fiber.Ctx, revoke func(id uuid.UUID) error) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid API key ID", fiber.StatusBadRequest))
+	}
+
+	if err := revoke(id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("API key not found", fiber.StatusNotFound))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to revoke API key", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("API key revoked successfully", nil))
+}
@@ -104,3 +104,28 @@ func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusNoContent).JSON(utils.Success("Category deleted successfully", nil))
 }
+
+func (h *CategoryHandler) ListDeletedCategories(c *fiber.Ctx) error {
+	categories, err := h.Service.ListDeletedCategories()
+	if err != nil {
+		errInfo := utils.NewErrorInfo("FETCH_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to fetch deleted categories", fiber.StatusInternalServerError, errInfo))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted categories retrieved successfully", categories))
+}
+
+func (h *CategoryHandler) RestoreCategory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errInfo := utils.NewErrorInfo("INVALID_ID", "The provided ID is not a valid UUID", "id", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid category ID", fiber.StatusBadRequest, errInfo))
+	}
+
+	category, err := h.Service.RestoreCategory(id)
+	if err != nil {
+		return restoreError(c, err, "category")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Category restored successfully", category))
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+)
+
+// GetJWKS publishes the public keys our tokens can be verified with. The body is a
+// plain JWKS document rather than the usual response envelope, since JWT libraries
+// of other services fetch it directly.
+func GetJWKS(c *fiber.Ctx) error {
+	keys, err := jwt.PublicKeySet()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to load signing keys", fiber.StatusInternalServerError))
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
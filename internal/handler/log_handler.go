@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type LogHandler struct {
+	Service *service.LogService
+}
+
+// ListLogs searches the logs. Besides the column filters it accepts metadata.<key>=<value>
+// to match a top-level metadata field, e.g. metadata.route=/api/v1/products/:id.
+// from and to are RFC 3339 times; pass next_cursor back as cursor for the next page.
+func (h *LogHandler) ListLogs(c *fiber.Ctx) error {
+	filter := dto.LogFilter{
+		Level:     c.Query("level"),
+		Source:    c.Query("source"),
+		Action:    c.Query("action"),
+		Entity:    c.Query("entity"),
+		RequestID: c.Query("request_id"),
+		Cursor:    c.Query("cursor"),
+		Limit:     c.QueryInt("limit", service.DefaultLogPageSize),
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **uuid.UUID
+	}{
+		{"entity_id", &filter.EntityID},
+		{"user_id", &filter.UserID},
+	} {
+		if value := c.Query(param.name); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				errInfo := utils.NewErrorInfo("INVALID_ID", "The provided ID is not a valid UUID", param.name, nil)
+				return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid log filter", fiber.StatusBadRequest, errInfo))
+			}
+			*param.dest = &id
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		if value := c.Query(param.name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				errInfo := utils.NewErrorInfo("INVALID_TIME", "Times must be in RFC 3339 format", param.name, nil)
+				return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid log filter", fiber.StatusBadRequest, errInfo))
+			}
+			*param.dest = &t
+		}
+	}
+
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if name, ok := strings.CutPrefix(string(key), "metadata."); ok {
+			if filter.Metadata == nil {
+				filter.Metadata = make(map[string]string)
+			}
+			filter.Metadata[name] = string(value)
+		}
+	})
+
+	page, err := h.Service.ListLogs(filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLogFilter) {
+			errInfo := utils.NewErrorInfo("INVALID_FILTER", err.Error(), "", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid log filter", fiber.StatusBadRequest, errInfo))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to fetch logs", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Logs fetched successfully", page))
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type MeHandler struct {
+	Service  *service.MeService
+	Validate *validator.Validate
+}
+
+// GetMe returns the authenticated user, their role and effective permissions
+func (h *MeHandler) GetMe(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	permissions, ok := middleware.GetUserPermissions(c)
+	if !ok {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to resolve permissions", fiber.StatusInternalServerError))
+	}
+
+	me, err := h.Service.GetMe(userID, permissions)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve current user", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Current user retrieved successfully", me))
+}
+
+// Can checks a batch of permissions for the authenticated user
+func (h *MeHandler) Can(c *fiber.Ctx) error {
+	var req dto.PermissionCheckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		errInfo := utils.NewErrorInfo("VALIDATION_ERROR", err.Error(), "permissions", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Validation failed", fiber.StatusBadRequest, errInfo))
+	}
+
+	permissions, ok := middleware.GetUserPermissions(c)
+	if !ok {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to resolve permissions", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Permissions checked successfully", h.Service.CheckPermissions(permissions, req.Permissions)))
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type MenuHandler struct {
+	Service *service.MenuService
+}
+
+// GetPublicMenu returns the customer-facing menu: active categories with their
+// available products and variations. Clients can revalidate with If-None-Match.
+func (h *MenuHandler) GetPublicMenu(c *fiber.Ctx) error {
+	menu, etag, err := h.Service.GetPublicMenu()
+	if err != nil {
+		errInfo := utils.NewErrorInfo("DB_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve menu", fiber.StatusInternalServerError, errInfo))
+	}
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "public, no-cache")
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Menu retrieved successfully", menu))
+}
+
+// ImportMenu imports categories, products and variations from a JSON or CSV payload.
+// CSV may be sent as a raw text/csv body or as a multipart "file" field.
+// Pass ?dry_run=true to validate without applying any changes.
+func (h *MenuHandler) ImportMenu(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+
+	var (
+		result *dto.MenuImportResult
+		err    error
+	)
+
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		fileHeader, ferr := c.FormFile("file")
+		if ferr != nil {
+			errInfo := utils.NewErrorInfo("MISSING_FILE", "A CSV file is required in the form", "file", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Menu file required", fiber.StatusBadRequest, errInfo))
+		}
+
+		file, ferr := fileHeader.Open()
+		if ferr != nil {
+			errInfo := utils.NewErrorInfo("FILE_ERROR", "Failed to process uploaded file", "file", nil)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to open menu file", fiber.StatusInternalServerError, errInfo))
+		}
+		defer file.Close()
+
+		result, err = h.Service.ImportMenuCSV(file, dryRun)
+
+	case strings.HasPrefix(contentType, "text/csv"):
+		result, err = h.Service.ImportMenuCSV(bytes.NewReader(c.Body()), dryRun)
+
+	default:
+		var body dto.MenuImportRequest
+		if perr := c.BodyParser(&body); perr != nil {
+			errInfo := utils.NewErrorInfo("INVALID_BODY", "Failed to parse request body", "", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest, errInfo))
+		}
+		result, err = h.Service.ImportMenu(&body, dryRun)
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyMenuImport) || errors.Is(err, service.ErrInvalidMenuCSV) {
+			errInfo := utils.NewErrorInfo("INVALID_IMPORT", err.Error(), "", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid menu import", fiber.StatusBadRequest, errInfo))
+		}
+		errInfo := utils.NewErrorInfo("IMPORT_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to import menu", fiber.StatusInternalServerError, errInfo))
+	}
+
+	if len(result.Errors) > 0 {
+		response := utils.Error("Menu import has validation errors", fiber.StatusUnprocessableEntity,
+			utils.NewErrorInfo("VALIDATION_ERROR", "One or more rows failed validation", "", nil))
+		response.Data = result
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(response)
+	}
+
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(utils.Success("Menu import validated successfully", result))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.Success("Menu imported successfully", result))
+}
+
+// ExportMenu exports the whole menu as JSON (default) or CSV with ?format=csv
+func (h *MenuHandler) ExportMenu(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		errInfo := utils.NewErrorInfo("INVALID_FORMAT", "Format must be either json or csv", "format", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid export format", fiber.StatusBadRequest, errInfo))
+	}
+
+	menu, err := h.Service.ExportMenu()
+	if err != nil {
+		errInfo := utils.NewErrorInfo("EXPORT_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to export menu", fiber.StatusInternalServerError, errInfo))
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := service.WriteMenuCSV(&buf, menu); err != nil {
+			errInfo := utils.NewErrorInfo("EXPORT_ERROR", err.Error(), "", nil)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to export menu", fiber.StatusInternalServerError, errInfo))
+		}
+
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="menu.csv"`)
+		return c.Status(fiber.StatusOK).Send(buf.Bytes())
+	}
+
+	metadata := &utils.Metadata{
+		CustomData: map[string]interface{}{
+			"category_count": len(menu.Categories),
+		},
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Menu exported successfully", menu, metadata))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using weak comparison
+func etagMatches(header, etag string) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+
+	current := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
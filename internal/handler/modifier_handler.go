@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type ModifierHandler struct {
+	Service *service.ModifierService
+}
+
+// GetProductModifierGroups lists all modifier groups for a specific product
+func (h *ModifierHandler) GetProductModifierGroups(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid product ID format", fiber.StatusBadRequest))
+	}
+
+	groups, err := h.Service.GetModifierGroupsByProductID(productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error(err.Error(), fiber.StatusInternalServerError))
+	}
+
+	response := dto.ToModifierGroupResponses(groups)
+	if response == nil {
+		response = []dto.ModifierGroupResponse{}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Modifier groups retrieved successfully", response))
+}
+
+// CreateProductModifierGroup creates a new modifier group for a specific product
+func (h *ModifierHandler) CreateProductModifierGroup(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid product ID format", fiber.StatusBadRequest))
+	}
+
+	var request dto.CreateModifierGroupRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request format", fiber.StatusBadRequest))
+	}
+
+	created, err := h.Service.CreateProductModifierGroup(productID, dto.ToModifierGroupDomain(&request))
+	if err != nil {
+		return modifierErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.Success("Modifier group created successfully", dto.ToModifierGroupResponse(created)))
+}
+
+// UpdateProductModifierGroup updates a modifier group, ensuring it belongs to the product
+func (h *ModifierHandler) UpdateProductModifierGroup(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid product ID format", fiber.StatusBadRequest))
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid modifier group ID format", fiber.StatusBadRequest))
+	}
+
+	var request dto.UpdateModifierGroupRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request format", fiber.StatusBadRequest))
+	}
+
+	updated, err := h.Service.UpdateProductModifierGroup(productID, id, &request)
+	if err != nil {
+		return modifierErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Modifier group updated successfully", dto.ToModifierGroupResponse(updated)))
+}
+
+// DeleteProductModifierGroup deletes a modifier group, ensuring it belongs to the product
+func (h *ModifierHandler) DeleteProductModifierGroup(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid product ID format", fiber.StatusBadRequest))
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid modifier group ID format", fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.DeleteProductModifierGroup(productID, id); err != nil {
+		return modifierErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusNoContent).JSON(utils.Success("Modifier group deleted successfully", nil))
+}
+
+func modifierErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrModifierProductNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(utils.Error("Product not found", fiber.StatusNotFound))
+	case errors.Is(err, service.ErrModifierGroupNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(utils.Error("Modifier group not found", fiber.StatusNotFound))
+	case errors.Is(err, service.ErrModifierGroupWrongProduct):
+		return c.Status(fiber.StatusForbidden).JSON(utils.Error(err.Error(), fiber.StatusForbidden))
+	case errors.Is(err, service.ErrInvalidModifierGroup):
+		errInfo := utils.NewErrorInfo("VALIDATION_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Validation failed", fiber.StatusBadRequest, errInfo))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error(err.Error(), fiber.StatusInternalServerError))
+	}
+}
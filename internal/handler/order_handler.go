@@ -9,8 +9,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/service"
 	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
 	"gorm.io/gorm"
 )
@@ -25,10 +27,22 @@ type OrderRequest struct {
 }
 
 type OrderDetailRequest struct {
-	ProductID   string `json:"product_id"`
-	VariationID string `json:"variation_id,omitempty"`
-	Quantity    int    `json:"quantity"`
-	Note        string `json:"note,omitempty"`
+	ProductID     string                `json:"product_id"`
+	VariationID   string                `json:"variation_id,omitempty"`
+	Quantity      int                   `json:"quantity"`
+	Note          string                `json:"note,omitempty"`
+	BundleChoices []BundleChoiceRequest `json:"bundle_choices,omitempty"` // Picks for bundle choice groups
+	Modifiers     []ModifierRequest     `json:"modifiers,omitempty"`
+}
+
+type ModifierRequest struct {
+	GroupID string `json:"group_id"`
+	Label   string `json:"label"`
+}
+
+type BundleChoiceRequest struct {
+	BundleItemID string `json:"bundle_item_id"`
+	ProductID    string `json:"product_id"`
 }
 
 type CreateOrderRequest struct {
@@ -102,9 +116,14 @@ func (handler *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 	// Call the service to create the order with details
 	createdOrder, err := handler.OrderService.CreateOrder(order, orderDetails)
 	if err != nil {
+		if isOrderItemError(err) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	middleware.SetAuditEntityID(c, createdOrder.ID)
+
 	// Map to DTO before returning response
 	responseDTO := dto.MapToOrderResponseDTO(createdOrder)
 
@@ -132,6 +151,13 @@ func (handler *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 }
 
 // Map request model to domain model
+// isOrderItemError reports whether err is caused by an order item the customer can fix
+func isOrderItemError(err error) bool {
+	return errors.Is(err, service.ErrProductNotOrderable) ||
+		errors.Is(err, service.ErrInvalidBundleSelection) ||
+		errors.Is(err, service.ErrInvalidModifierSelection)
+}
+
 func mapOrderRequestToDomain(req OrderRequest) *domain.Order {
 
 	return &domain.Order{
@@ -157,6 +183,24 @@ func mapOrderDetailsRequestToDomain(reqs []OrderDetailRequest) []domain.OrderDet
 			Quantity:    req.Quantity,
 			Note:        req.Note,
 		}
+
+		// Bundle picks are carried as components and expanded by the service
+		for _, choice := range req.BundleChoices {
+			bundleItemID, _ := uuid.Parse(choice.BundleItemID)
+			choiceProductID, _ := uuid.Parse(choice.ProductID)
+			details[i].Components = append(details[i].Components, domain.OrderDetailComponent{
+				BundleItemID: &bundleItemID,
+				ProductID:    &choiceProductID,
+			})
+		}
+
+		// Modifier picks are validated and priced by the service
+		for _, modifier := range req.Modifiers {
+			details[i].Modifiers = append(details[i].Modifiers, db.SelectedModifier{
+				GroupID: modifier.GroupID,
+				Label:   modifier.Label,
+			})
+		}
 	}
 
 	return details
@@ -327,6 +371,9 @@ func (h *OrderHandler) UpdateOrder(c *fiber.Ctx) error {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(utils.Error("Order not found", fiber.StatusNotFound))
 		}
+		if isOrderItemError(err) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.Error(err.Error(), fiber.StatusUnprocessableEntity))
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error(err.Error(), fiber.StatusInternalServerError))
 	}
 
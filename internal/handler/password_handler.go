@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type PasswordHandler struct {
+	Service  *service.PasswordService
+	Validate *validator.Validate
+}
+
+// ChangePassword changes the authenticated user's password
+func (h *PasswordHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.ChangePassword(userID, &req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrWeakPassword):
+			return weakPasswordResponse(c, err)
+		case errors.Is(err, service.ErrIncorrectPassword):
+			errInfo := utils.NewErrorInfo("INCORRECT_PASSWORD", err.Error(), "current_password", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Current password is incorrect", fiber.StatusBadRequest, errInfo))
+		case errors.Is(err, service.ErrPasswordUnchanged):
+			errInfo := utils.NewErrorInfo("PASSWORD_UNCHANGED", err.Error(), "new_password", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("New password must differ from the current one", fiber.StatusBadRequest, errInfo))
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to change password", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Password changed successfully, please log in again", nil))
+}
+
+// RequestPasswordReset sends a user a single-use link to choose a new password
+func (h *PasswordHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid user ID", fiber.StatusBadRequest))
+	}
+
+	position, err := callerPosition(c, h.Service.GetCallerPosition)
+	if err != nil {
+		return callerPositionErrorResponse(c, err)
+	}
+
+	reset, err := h.Service.RequestPasswordReset(position, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+		case errors.Is(err, service.ErrInsufficientRank):
+			return insufficientRankResponse(c, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to issue password reset", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Password reset link sent", reset))
+}
+
+// ResetPassword sets a new password with the token from a reset link
+func (h *PasswordHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.ResetPassword(&req); err != nil {
+		switch {
+		case errors.Is(err, service.ErrWeakPassword):
+			return weakPasswordResponse(c, err)
+		case errors.Is(err, service.ErrInvalidResetToken):
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid or expired password reset link", fiber.StatusBadRequest))
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to reset password", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Password reset successfully", nil))
+}
+
+// ResetUserID returns the user a reset request's token belongs to, so the reset is
+// audited against that user
+func (h *PasswordHandler) ResetUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return uuid.Nil, false
+	}
+	id, err := h.Service.ResetTokenUserID(req.Token)
+	return id, err == nil
+}
+
+func weakPasswordResponse(c *fiber.Ctx, err error) error {
+	errInfo := utils.NewErrorInfo("WEAK_PASSWORD", err.Error(), "new_password", nil)
+	return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Password is too weak", fiber.StatusBadRequest, errInfo))
+}
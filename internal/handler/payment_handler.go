@@ -6,6 +6,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/service"
 	"github.com/latoulicious/siresto-backend/internal/utils"
 	"gorm.io/gorm"
@@ -65,6 +66,7 @@ func (h *PaymentHandler) ProcessOrderPayment(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error(err.Error(), fiber.StatusInternalServerError))
 	}
 
+	middleware.SetAuditEntityID(c, processedPayment.ID)
 	return c.Status(fiber.StatusOK).JSON(utils.Success("Payment processed successfully", processedPayment))
 }
 
@@ -2,11 +2,12 @@ package handler
 
 import (
 	"encoding/json"
-	"path"
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/service"
 	"github.com/latoulicious/siresto-backend/internal/utils"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
@@ -95,6 +96,11 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		}
 
 		fileHeader := form.File["image"][0]
+		if fileHeader.Size > utils.DefaultProductImageLimits.MaxBytes {
+			errInfo := utils.NewErrorInfo("IMAGE_TOO_LARGE", utils.ErrImageTooLarge.Error(), "image", nil)
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(utils.Error("Image is too large", fiber.StatusRequestEntityTooLarge, errInfo))
+		}
+
 		file, err := fileHeader.Open()
 		if err != nil {
 			errInfo := utils.NewErrorInfo("FILE_ERROR", "Failed to process uploaded image", "image", nil)
@@ -102,20 +108,16 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		}
 		defer file.Close()
 
-		filename := uuid.New().String() + path.Ext(fileHeader.Filename)
+		// Renditions are stored under the product ID, so assign it before uploading
+		product.ID = uuid.New()
 
-		if h.Service.Uploader == nil {
-			errInfo := utils.NewErrorInfo("UPLOADER_ERROR", "Image uploading service is not available", "", nil)
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Image uploading service is not available", fiber.StatusInternalServerError, errInfo))
-		}
-
-		imageURL, err := h.Service.Uploader.Upload(file, "products/"+filename)
+		images, err := h.Service.UploadProductImage(product.ID, file)
 		if err != nil {
-			errInfo := utils.NewErrorInfo("UPLOAD_ERROR", err.Error(), "image", nil)
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to upload image", fiber.StatusInternalServerError, errInfo))
+			return imageErrorResponse(c, err)
 		}
 
-		product.ImageURL = imageURL
+		product.Images = images
+		product.ImageURL = images["full"]
 	}
 
 	createdProduct, variations, err := h.Service.CreateProductWithVariations(product, body.Variations)
@@ -124,6 +126,7 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to create product", fiber.StatusInternalServerError, errInfo))
 	}
 
+	middleware.SetAuditEntityID(c, createdProduct.ID)
 	response := dto.ToProductResponse(createdProduct)
 	response.Variations = dto.ToVariationResponses(variations)
 
@@ -207,3 +210,45 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(utils.Success("Product deleted successfully", nil))
 }
+
+func (h *ProductHandler) ListDeletedProducts(c *fiber.Ctx) error {
+	products, err := h.Service.ListDeletedProducts()
+	if err != nil {
+		errInfo := utils.NewErrorInfo("FETCH_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to fetch deleted products", fiber.StatusInternalServerError, errInfo))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted products retrieved successfully", products))
+}
+
+func (h *ProductHandler) RestoreProduct(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		errInfo := utils.NewErrorInfo("INVALID_ID", "The provided ID is not a valid UUID", "id", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid product ID", fiber.StatusBadRequest, errInfo))
+	}
+
+	product, err := h.Service.RestoreProduct(id)
+	if err != nil {
+		return restoreError(c, err, "product")
+	}
+
+	response := dto.ToProductResponse(product)
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Product restored successfully", response))
+}
+
+func imageErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, utils.ErrImageTooLarge):
+		errInfo := utils.NewErrorInfo("IMAGE_TOO_LARGE", err.Error(), "image", nil)
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(utils.Error("Image is too large", fiber.StatusRequestEntityTooLarge, errInfo))
+	case errors.Is(err, utils.ErrUnsupportedImage),
+		errors.Is(err, utils.ErrInvalidImageSize),
+		errors.Is(err, utils.ErrImageDecodingFailed):
+		errInfo := utils.NewErrorInfo("INVALID_IMAGE", err.Error(), "image", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid image", fiber.StatusBadRequest, errInfo))
+	default:
+		errInfo := utils.NewErrorInfo("UPLOAD_ERROR", err.Error(), "image", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to upload image", fiber.StatusInternalServerError, errInfo))
+	}
+}
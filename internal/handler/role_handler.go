@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/service"
 	"github.com/latoulicious/siresto-backend/internal/utils"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
@@ -58,6 +59,7 @@ func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 			"error": "Failed to create role: " + err.Error(),
 		})
 	}
+	middleware.SetAuditEntityID(c, role.ID)
 	return c.Status(fiber.StatusCreated).JSON(role)
 }
 
@@ -155,6 +157,29 @@ func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// ListDeletedRoles lists deleted roles that can be restored
+func (h *RoleHandler) ListDeletedRoles(c *fiber.Ctx) error {
+	roles, err := h.Service.ListDeletedRoles()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve deleted roles", fiber.StatusInternalServerError))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted roles retrieved successfully", roles))
+}
+
+// RestoreRole restores a deleted role together with its permissions
+func (h *RoleHandler) RestoreRole(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid role ID", fiber.StatusBadRequest))
+	}
+
+	role, err := h.Service.RestoreRole(roleID)
+	if err != nil {
+		return restoreError(c, err, "role")
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Role restored successfully", role))
+}
+
 // CreateComprehensiveRole creates a role with extensive permissions (but not system-level)
 func (h *RoleHandler) CreateComprehensiveRole(c *fiber.Ctx) error {
 	// Parse request
@@ -257,6 +282,7 @@ func (h *RoleHandler) CreateComprehensiveRole(c *fiber.Ctx) error {
 		))
 	}
 
+	middleware.SetAuditEntityID(c, role.ID)
 	return c.Status(fiber.StatusCreated).JSON(utils.Success(
 		fmt.Sprintf("Created comprehensive role '%s' with %d permissions",
 			req.Name, len(allPermissionIDs)),
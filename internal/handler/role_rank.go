@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+)
+
+// callerPosition resolves the role position of the authenticated caller, for services
+// that only manage users ranked below it
+func callerPosition(c *fiber.Ctx, resolve func(callerID uuid.UUID) (int, error)) (int, error) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return 0, service.ErrUserNotFound
+	}
+	return resolve(userID)
+}
+
+// callerPositionErrorResponse writes the response for a caller whose role could not be resolved
+func callerPositionErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to resolve your role", fiber.StatusInternalServerError))
+}
+
+// insufficientRankResponse writes the response for managing a user ranked at or above the caller
+func insufficientRankResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusForbidden).JSON(utils.Error(err.Error(), fiber.StatusForbidden))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type SessionHandler struct {
+	Service  *service.SessionService
+	Validate *validator.Validate
+}
+
+// RefreshToken exchanges a refresh token for a new token pair
+func (h *SessionHandler) RefreshToken(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	response, err := h.Service.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid or expired refresh token", fiber.StatusUnauthorized))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to refresh token", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Token refreshed successfully", response))
+}
+
+// Logout revokes the bearer access token and the login of the given refresh token
+func (h *SessionHandler) Logout(c *fiber.Ctx) error {
+	var req dto.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+		}
+	}
+
+	accessToken := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	if accessToken == "" && req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("An access token or refresh token is required", fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.Logout(accessToken, req.RefreshToken); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to log out", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Logged out successfully", nil))
+}
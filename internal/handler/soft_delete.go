@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+)
+
+// restoreError writes the response for a failed restore of a deleted record
+func restoreError(c *fiber.Ctx, err error, entity string) error {
+	switch {
+	case errors.Is(err, service.ErrNotDeleted):
+		return c.Status(fiber.StatusNotFound).JSON(utils.Error("Deleted "+entity+" not found", fiber.StatusNotFound))
+	case errors.Is(err, service.ErrRestoreConflict):
+		errInfo := utils.NewErrorInfo("RESTORE_CONFLICT", err.Error(), "", nil)
+		return c.Status(fiber.StatusConflict).JSON(utils.Error("Cannot restore "+entity, fiber.StatusConflict, errInfo))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to restore "+entity+": "+err.Error(), fiber.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type TerminalHandler struct {
+	Service  *service.TerminalService
+	Validate *validator.Validate
+}
+
+// RegisterTerminal registers a shared staff device and returns its key once
+func (h *TerminalHandler) RegisterTerminal(c *fiber.Ctx) error {
+	var req dto.RegisterTerminalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	registration, err := h.Service.RegisterTerminal(&req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to register terminal", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.Success("Terminal registered successfully", registration))
+}
+
+func (h *TerminalHandler) ListTerminals(c *fiber.Ctx) error {
+	terminals, err := h.Service.ListTerminals()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve terminals", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Terminals retrieved successfully", terminals))
+}
+
+// RevokeTerminal revokes a terminal and every token issued on it
+func (h *TerminalHandler) RevokeTerminal(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid terminal ID", fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.RevokeTerminal(id); err != nil {
+		if errors.Is(err, service.ErrTerminalNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("Terminal not found", fiber.StatusNotFound))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to revoke terminal", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Terminal revoked successfully", nil))
+}
+
+// ListStaff lists the staff who can sign in on the calling terminal
+func (h *TerminalHandler) ListStaff(c *fiber.Ctx) error {
+	staff, err := h.Service.ListStaff(c.Get(middleware.HeaderTerminalKey))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTerminal) {
+			return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Unknown or revoked terminal", fiber.StatusUnauthorized))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve staff", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Staff retrieved successfully", staff))
+}
+
+// PinLogin signs a staff member in on the calling terminal
+func (h *TerminalHandler) PinLogin(c *fiber.Ctx) error {
+	var req dto.PinLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	loginResponse, err := h.Service.PinLogin(c.Get(middleware.HeaderTerminalKey), &req, c.IP())
+	if err != nil {
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			retryAfter := int(math.Ceil(time.Until(locked.Until).Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+			errInfo := utils.NewErrorInfo("LOGIN_LOCKED", err.Error(), "", nil)
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.Error("Too many failed login attempts", fiber.StatusTooManyRequests, errInfo))
+		case errors.Is(err, service.ErrInvalidTerminal):
+			return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Unknown or revoked terminal", fiber.StatusUnauthorized))
+		case errors.Is(err, service.ErrPinLoginNotAllowed):
+			return c.Status(fiber.StatusForbidden).JSON(utils.Error(err.Error(), fiber.StatusForbidden))
+		case errors.Is(err, service.ErrInvalidCredentials):
+			return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid user or PIN", fiber.StatusUnauthorized))
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to sign in", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Login successful", loginResponse))
+}
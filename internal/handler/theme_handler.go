@@ -39,6 +39,27 @@ func (h *ThemeHandler) GetThemeByID(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(utils.Success("Theme retrieved successfully", theme))
 }
 
+func (h *ThemeHandler) ListDeletedThemes(c *fiber.Ctx) error {
+	themes, err := h.Service.ListDeletedThemes()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve deleted Themes", fiber.StatusInternalServerError))
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted Themes retrieved successfully", themes))
+}
+
+func (h *ThemeHandler) RestoreTheme(c *fiber.Ctx) error {
+	themeID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid Theme ID format", fiber.StatusBadRequest))
+	}
+
+	theme, err := h.Service.RestoreTheme(themeID)
+	if err != nil {
+		return restoreError(c, err, "Theme")
+	}
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Theme restored successfully", theme))
+}
+
 func (h *ThemeHandler) CreateTheme(c *fiber.Ctx) error {
 	// Parse form data directly (no DTO)
 	var req struct {
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+// TwoFactorHandler manages the authenticated user's two-factor authentication.
+// The login steps themselves are served by UserHandler.
+type TwoFactorHandler struct {
+	Service  *service.TwoFactorService
+	Validate *validator.Validate
+}
+
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	status, err := h.Service.Status(userID)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to retrieve two-factor status")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor status retrieved successfully", status))
+}
+
+// BeginEnrollment returns a new secret to add to an authenticator app
+func (h *TwoFactorHandler) BeginEnrollment(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	enrollment, err := h.Service.BeginEnrollment(userID)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to start two-factor enrollment")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor enrollment started", enrollment))
+}
+
+// ConfirmEnrollment enables two-factor authentication and returns the recovery codes
+func (h *TwoFactorHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	codes, err := h.Service.ConfirmEnrollment(userID, req.Code, c.IP())
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor authentication enabled", dto.RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	codes, err := h.Service.RegenerateRecoveryCodes(userID, req.Code, c.IP())
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to regenerate recovery codes")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Recovery codes regenerated", dto.RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Authentication required", fiber.StatusUnauthorized))
+	}
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	if err := h.Service.Disable(userID, &req, c.IP()); err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor authentication disabled", nil))
+}
+
+// Reset removes another user's two-factor authentication, e.g. after a lost phone
+func (h *TwoFactorHandler) Reset(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid user ID", fiber.StatusBadRequest))
+	}
+
+	position, err := callerPosition(c, h.Service.GetCallerPosition)
+	if err != nil {
+		return callerPositionErrorResponse(c, err)
+	}
+
+	if err := h.Service.Reset(position, id); err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to reset two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor authentication reset", nil))
+}
+
+// twoFactorErrorResponse maps two-factor errors, shared with the login steps in UserHandler
+func twoFactorErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	var locked *service.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		retryAfter := int(math.Ceil(time.Until(locked.Until).Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+		errInfo := utils.NewErrorInfo("LOGIN_LOCKED", err.Error(), "", nil)
+		return c.Status(fiber.StatusTooManyRequests).JSON(utils.Error("Too many failed attempts", fiber.StatusTooManyRequests, errInfo))
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		errInfo := utils.NewErrorInfo("INVALID_TWO_FACTOR_CODE", err.Error(), "code", nil)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid two-factor code", fiber.StatusUnauthorized, errInfo))
+	case errors.Is(err, service.ErrInvalidTwoFactorToken):
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid or expired two-factor token, please log in again", fiber.StatusUnauthorized))
+	case errors.Is(err, service.ErrIncorrectPassword):
+		errInfo := utils.NewErrorInfo("INCORRECT_PASSWORD", err.Error(), "password", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Password is incorrect", fiber.StatusBadRequest, errInfo))
+	case errors.Is(err, service.ErrTwoFactorNotAvailable), errors.Is(err, service.ErrTwoFactorRequired),
+		errors.Is(err, service.ErrInsufficientRank):
+		return c.Status(fiber.StatusForbidden).JSON(utils.Error(err.Error(), fiber.StatusForbidden))
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolling):
+		return c.Status(fiber.StatusConflict).JSON(utils.Error(err.Error(), fiber.StatusConflict))
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error(fallback, fiber.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+type UploadHandler struct {
+	Service *service.UploadService
+}
+
+// uploadTargetPermissions are required on top of the route permission, since one
+// route serves both product and theme images
+var uploadTargetPermissions = map[string]string{
+	service.UploadTargetProduct: middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceMenu),
+	service.UploadTargetTheme:   middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceSetting),
+}
+
+// canUploadTo reports whether the user may attach images to the target; unknown
+// targets are left to the service to reject
+func canUploadTo(c *fiber.Ctx, target string) bool {
+	permission, ok := uploadTargetPermissions[target]
+	if !ok {
+		return true
+	}
+	permissions, ok := middleware.GetUserPermissions(c)
+	return ok && middleware.HasPermission(permissions, permission)
+}
+
+// PresignUpload issues a URL the client uploads an image to directly
+func (h *UploadHandler) PresignUpload(c *fiber.Ctx) error {
+	var request dto.PresignUploadRequest
+	if err := c.BodyParser(&request); err != nil {
+		errInfo := utils.NewErrorInfo("INVALID_BODY", "Failed to parse request body", "", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest, errInfo))
+	}
+
+	if !canUploadTo(c, request.Target) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
+	}
+
+	response, err := h.Service.PresignUpload(&request)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Upload URL created successfully", response))
+}
+
+// ConfirmUpload attaches a directly uploaded image to its product or theme
+func (h *UploadHandler) ConfirmUpload(c *fiber.Ctx) error {
+	var request dto.ConfirmUploadRequest
+	if err := c.BodyParser(&request); err != nil {
+		errInfo := utils.NewErrorInfo("INVALID_BODY", "Failed to parse request body", "", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest, errInfo))
+	}
+
+	if !canUploadTo(c, request.Target) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
+	}
+
+	response, err := h.Service.ConfirmUpload(&request)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Upload confirmed successfully", response))
+}
+
+// ConfirmedProductID returns the product a confirm request attaches an image to, so
+// product images are audited; theme uploads report none
+func ConfirmedProductID(c *fiber.Ctx) (uuid.UUID, bool) {
+	var request dto.ConfirmUploadRequest
+	if err := c.BodyParser(&request); err != nil || request.Target != service.UploadTargetProduct {
+		return uuid.Nil, false
+	}
+	return request.TargetID, true
+}
+
+func uploadErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidUploadRequest):
+		errInfo := utils.NewErrorInfo("VALIDATION_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Validation failed", fiber.StatusBadRequest, errInfo))
+	case errors.Is(err, service.ErrUploadTargetNotFound):
+		errInfo := utils.NewErrorInfo("TARGET_NOT_FOUND", err.Error(), "target_id", nil)
+		return c.Status(fiber.StatusNotFound).JSON(utils.Error("Upload target not found", fiber.StatusNotFound, errInfo))
+	case errors.Is(err, service.ErrUploadNotFound):
+		errInfo := utils.NewErrorInfo("UPLOAD_NOT_FOUND", err.Error(), "key", nil)
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.Error("Uploaded file not found", fiber.StatusUnprocessableEntity, errInfo))
+	case errors.Is(err, service.ErrDirectUploadUnsupported):
+		errInfo := utils.NewErrorInfo("UPLOADER_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusNotImplemented).JSON(utils.Error("Direct uploads are not available", fiber.StatusNotImplemented, errInfo))
+	case errors.Is(err, utils.ErrImageTooLarge), errors.Is(err, utils.ErrUnsupportedImage),
+		errors.Is(err, utils.ErrInvalidImageSize), errors.Is(err, utils.ErrImageDecodingFailed):
+		return imageErrorResponse(c, err)
+	default:
+		errInfo := utils.NewErrorInfo("UPLOAD_ERROR", err.Error(), "", nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to process upload", fiber.StatusInternalServerError, errInfo))
+	}
+}
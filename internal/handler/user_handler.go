@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/service"
 	"github.com/latoulicious/siresto-backend/internal/utils"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
@@ -64,6 +70,10 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 
 	createdUser, err := h.Service.CreateUser(&req)
 	if err != nil {
+		if errors.Is(err, service.ErrWeakPassword) {
+			errInfo := utils.NewErrorInfo("WEAK_PASSWORD", err.Error(), "password", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Password is too weak", fiber.StatusBadRequest, errInfo))
+		}
 		switch err {
 		case service.ErrEmailAlreadyExists:
 			return c.Status(fiber.StatusConflict).JSON(utils.Error("Email already in use", fiber.StatusConflict))
@@ -72,6 +82,7 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		}
 	}
 
+	middleware.SetAuditEntityID(c, createdUser.ID)
 	return c.Status(fiber.StatusCreated).JSON(utils.Success("User created successfully", createdUser))
 }
 
@@ -94,6 +105,10 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 
 	updatedUser, err := h.Service.UpdateUser(id, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrWeakPassword) {
+			errInfo := utils.NewErrorInfo("WEAK_PASSWORD", err.Error(), "password", nil)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Password is too weak", fiber.StatusBadRequest, errInfo))
+		}
 		switch err {
 		case service.ErrUserNotFound:
 			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
@@ -127,6 +142,93 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(utils.Success("User deleted successfully", nil))
 }
 
+// ListDeletedUsers lists deleted users that can be restored
+func (h *UserHandler) ListDeletedUsers(c *fiber.Ctx) error {
+	users, err := h.Service.ListDeletedUsers()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve deleted users", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted users retrieved successfully", users))
+}
+
+// RestoreUser restores a deleted user
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid user ID", fiber.StatusBadRequest))
+	}
+
+	user, err := h.Service.RestoreUser(id)
+	if err != nil {
+		return restoreError(c, err, "user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("User restored successfully", user))
+}
+
+// SetUserPin sets a user's terminal PIN
+func (h *UserHandler) SetUserPin(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid user ID", fiber.StatusBadRequest))
+	}
+
+	var req dto.SetPinRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	position, err := callerPosition(c, h.Service.GetCallerPosition)
+	if err != nil {
+		return callerPositionErrorResponse(c, err)
+	}
+
+	if err := h.Service.SetUserPin(position, id, req.Pin); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+		case service.ErrInsufficientRank:
+			return insufficientRankResponse(c, err)
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to set PIN", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("PIN set successfully", nil))
+}
+
+// UnlockUser lifts a login lockout on a user's account
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid user ID", fiber.StatusBadRequest))
+	}
+
+	position, err := callerPosition(c, h.Service.GetCallerPosition)
+	if err != nil {
+		return callerPositionErrorResponse(c, err)
+	}
+
+	if err := h.Service.UnlockUser(position, id); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(utils.Error("User not found", fiber.StatusNotFound))
+		case service.ErrInsufficientRank:
+			return insufficientRankResponse(c, err)
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to unlock user", fiber.StatusInternalServerError))
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("User unlocked successfully", nil))
+}
+
 // LoginUser handles user login
 func (h *UserHandler) LoginUser(c *fiber.Ctx) error {
 	var req dto.LoginRequest
@@ -139,10 +241,81 @@ func (h *UserHandler) LoginUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
 	}
 
-	loginResponse, err := h.Service.LoginUser(&req)
+	loginResponse, err := h.Service.LoginUser(&req, c.IP())
 	if err != nil {
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			retryAfter := int(math.Ceil(time.Until(locked.Until).Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+			errInfo := utils.NewErrorInfo("LOGIN_LOCKED", err.Error(), "", nil)
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.Error("Too many failed login attempts", fiber.StatusTooManyRequests, errInfo))
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid email or password", fiber.StatusUnauthorized))
 	}
 
+	if loginResponse.TwoFactorRequired || loginResponse.TwoFactorEnrollmentRequired {
+		return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor authentication required", loginResponse))
+	}
+
 	return c.Status(fiber.StatusOK).JSON(utils.Success("Login successful", loginResponse))
 }
+
+// VerifyTwoFactor completes a login with a code from the authenticator app or a recovery code
+func (h *UserHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	var req dto.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	loginResponse, err := h.Service.CompleteTwoFactorLogin(&req, c.IP())
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to complete login")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Login successful", loginResponse))
+}
+
+// BeginTwoFactorEnrollment starts the enrollment required before a management user's first login
+func (h *UserHandler) BeginTwoFactorEnrollment(c *fiber.Ctx) error {
+	var req dto.TwoFactorEnrollmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	enrollment, err := h.Service.BeginTwoFactorEnrollment(&req)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to start two-factor enrollment")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor enrollment started", enrollment))
+}
+
+// CompleteTwoFactorEnrollment confirms the required enrollment and completes the login
+func (h *UserHandler) CompleteTwoFactorEnrollment(c *fiber.Ctx) error {
+	var req dto.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid request body", fiber.StatusBadRequest))
+	}
+
+	if err := h.Validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error(validationErrors.Error(), fiber.StatusBadRequest))
+	}
+
+	loginResponse, err := h.Service.CompleteTwoFactorEnrollment(&req, c.IP())
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to complete two-factor enrollment")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Two-factor authentication enabled, login successful", loginResponse))
+}
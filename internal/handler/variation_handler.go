@@ -191,6 +191,31 @@ func (h *VariationHandler) DeleteVariation(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusNoContent).JSON(utils.Success("Variation deleted successfully", nil))
 }
 
+// ListDeletedVariations lists deleted variations that can be restored
+func (h *VariationHandler) ListDeletedVariations(c *fiber.Ctx) error {
+	variations, err := h.Service.ListDeletedVariations()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve deleted variations", fiber.StatusInternalServerError))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Deleted variations retrieved successfully", variations))
+}
+
+// RestoreVariation restores a deleted variation
+func (h *VariationHandler) RestoreVariation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.Error("Invalid variation ID format", fiber.StatusBadRequest))
+	}
+
+	variation, err := h.Service.RestoreVariation(id)
+	if err != nil {
+		return restoreError(c, err, "variation")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.Success("Variation restored successfully", variation))
+}
+
 // DeleteProductVariation deletes a variation for a specific product, ensuring it belongs to that product
 func (h *VariationHandler) DeleteProductVariation(c *fiber.Ctx) error {
 	// Parse product ID from URL parameter
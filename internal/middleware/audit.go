@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries a client supplied ID that ties audit entries to a request
+const HeaderRequestID = "X-Request-ID"
+
+// Entities whose changes are audited
+const (
+	AuditOrder   = "order"
+	AuditPayment = "payment"
+	AuditProduct = "product"
+	AuditRole    = "role"
+	AuditUser    = "user"
+)
+
+// AuditEntry describes one change made through the API
+type AuditEntry struct {
+	Entity    string
+	EntityID  *uuid.UUID
+	Action    string     // e.g. "product.updated"
+	UserID    *uuid.UUID // Nil on public routes, e.g. placing an order from a table
+	APIKeyID  *uuid.UUID
+	IPAddress string
+	RequestID string
+	Method    string
+	Route     string // Route pattern, e.g. /api/v1/products/:id
+	Path      string
+	Status    int
+	Before    interface{} // Nil when the entity was created
+	After     interface{} // Nil when the entity was deleted
+}
+
+// AuditRecorder loads the state of audited entities and stores audit entries
+type AuditRecorder interface {
+	AuditSnapshot(entity string, id uuid.UUID) (interface{}, error)
+	RecordAudit(entry AuditEntry)
+}
+
+var auditRecorder AuditRecorder
+
+// SetAuditRecorder installs the recorder Audit reports changes to
+func SetAuditRecorder(recorder AuditRecorder) {
+	auditRecorder = recorder
+}
+
+// Audit records a successful change of entity with its state before and after the
+// request. idParam names the route parameter holding the entity ID; handlers that
+// create an entity report the new ID with SetAuditEntityID.
+func Audit(entity, action, idParam string) fiber.Handler {
+	if idParam == "" {
+		return AuditWith(entity, action, nil)
+	}
+	return AuditWith(entity, action, func(c *fiber.Ctx) (uuid.UUID, bool) {
+		id, err := uuid.Parse(c.Params(idParam))
+		return id, err == nil
+	})
+}
+
+// AuditWith is Audit for routes whose entity ID is not a route parameter, e.g.
+// GetUserID for the signed-in user. When entityID reports none and the handler does
+// not set one either, the request is not audited.
+func AuditWith(entity, action string, entityID func(c *fiber.Ctx) (uuid.UUID, bool)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if auditRecorder == nil {
+			return c.Next()
+		}
+
+		var id *uuid.UUID
+		var before interface{}
+		if entityID != nil {
+			if resolved, ok := entityID(c); ok {
+				id = &resolved
+				// An unknown ID is left to the handler to reject
+				before, _ = auditRecorder.AuditSnapshot(entity, resolved)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		status := c.Response().StatusCode()
+		if status < fiber.StatusOK || status >= fiber.StatusMultipleChoices {
+			return nil
+		}
+
+		if created, ok := c.Locals("auditEntityID").(uuid.UUID); ok {
+			id = &created
+		}
+		if id == nil && entityID != nil {
+			return nil
+		}
+		var after interface{}
+		if id != nil {
+			// Deleted entities are no longer found
+			after, _ = auditRecorder.AuditSnapshot(entity, *id)
+		}
+
+		entry := AuditEntry{
+			Entity:    entity,
+			EntityID:  id,
+			Action:    entity + "." + action,
+			IPAddress: c.IP(),
+			RequestID: c.Get(HeaderRequestID),
+			Method:    c.Method(),
+			Route:     c.Route().Path,
+			Path:      c.Path(),
+			Status:    status,
+			Before:    before,
+			After:     after,
+		}
+		if userID, ok := GetUserID(c); ok {
+			entry.UserID = &userID
+		}
+		if keyID, ok := GetAPIKeyID(c); ok {
+			entry.APIKeyID = &keyID
+		}
+		auditRecorder.RecordAudit(entry)
+
+		return nil
+	}
+}
+
+// SetAuditEntityID reports the ID of the entity a request created to Audit
+func SetAuditEntityID(c *fiber.Ctx, id uuid.UUID) {
+	c.Locals("auditEntityID", id)
+}
@@ -1,17 +1,67 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
 	"github.com/latoulicious/siresto-backend/pkg/jwt"
 )
 
-// Protected is a middleware that checks for a valid JWT token
+// HeaderTerminalKey carries the device key of a registered terminal
+const HeaderTerminalKey = "X-Terminal-Key"
+
+// HeaderAPIKey carries an API key. Keys are also accepted as Bearer tokens, told apart
+// from JWTs by APIKeyPrefix.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise
+const APIKeyPrefix = "srk_"
+
+// RevocationChecker reports whether an otherwise valid access token has been revoked
+type RevocationChecker interface {
+	IsRevoked(claims *jwt.Claims) (bool, error)
+}
+
+var revocationChecker RevocationChecker
+
+// SetRevocationChecker installs the checker Protected consults on every request
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationChecker = checker
+}
+
+// APIKeyPrincipal is the user a request authenticated with an API key acts for
+type APIKeyPrincipal struct {
+	KeyID    uuid.UUID
+	UserID   uuid.UUID
+	RoleID   uuid.UUID
+	RoleName string
+	IsStaff  bool
+	Scopes   []string // Narrow the role's permissions, see GetUserPermissions
+}
+
+// APIKeyAuthenticator resolves an API key to the user it acts for
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*APIKeyPrincipal, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator makes Protected accept API keys besides access tokens
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// Protected is a middleware that checks for a valid JWT token or API key
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if key := apiKeyFromRequest(c); key != "" {
+			return authenticateAPIKey(c, key)
+		}
+
 		// Get token from Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -32,6 +82,23 @@ func Protected() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid or expired token", fiber.StatusUnauthorized))
 		}
 
+		// Reject tokens revoked at logout or by a forced logout; fail closed if the check fails
+		if revocationChecker != nil {
+			revoked, err := revocationChecker.IsRevoked(claims)
+			if err != nil || revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Token has been revoked", fiber.StatusUnauthorized))
+			}
+		}
+
+		// Tokens from a terminal PIN login are only accepted from that terminal
+		if claims.TerminalID != uuid.Nil {
+			keyHash := crypto.HashToken(c.Get(HeaderTerminalKey))
+			if subtle.ConstantTimeCompare([]byte(keyHash), []byte(claims.TerminalKeyHash)) != 1 {
+				return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Token is bound to another terminal", fiber.StatusUnauthorized))
+			}
+			c.Locals("terminalID", claims.TerminalID)
+		}
+
 		// Store user ID and role information in context for later use
 		c.Locals("userID", claims.UserID)
 		c.Locals("roleID", claims.RoleID)
@@ -43,6 +110,55 @@ func Protected() fiber.Handler {
 	}
 }
 
+// apiKeyFromRequest returns the API key sent in the X-API-Key header or as a Bearer token
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := c.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+func authenticateAPIKey(c *fiber.Ctx, key string) error {
+	if apiKeyAuthenticator == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("API keys are not accepted", fiber.StatusUnauthorized))
+	}
+
+	// Fail closed if the key cannot be checked
+	principal, err := apiKeyAuthenticator.AuthenticateAPIKey(key)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.Error("Invalid, expired or revoked API key", fiber.StatusUnauthorized))
+	}
+
+	c.Locals("userID", principal.UserID)
+	c.Locals("roleID", principal.RoleID)
+	c.Locals("roleName", principal.RoleName)
+	c.Locals("isStaff", principal.IsStaff)
+	c.Locals("apiKeyID", principal.KeyID)
+	c.Locals("apiKeyScopes", principal.Scopes)
+
+	return c.Next()
+}
+
+// GetAPIKeyID retrieves the ID of the API key a request authenticated with, if any
+func GetAPIKeyID(c *fiber.Ctx) (uuid.UUID, bool) {
+	keyID, ok := c.Locals("apiKeyID").(uuid.UUID)
+	return keyID, ok
+}
+
+// RejectAPIKeys keeps a route to interactive sessions, e.g. changing the caller's own
+// credentials, which a leaked key must not be able to do
+func RejectAPIKeys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := GetAPIKeyID(c); ok {
+			return c.Status(fiber.StatusForbidden).JSON(utils.Error("Not available to API keys", fiber.StatusForbidden))
+		}
+		return c.Next()
+	}
+}
+
 // GetUserID retrieves the authenticated user's ID from the context
 func GetUserID(c *fiber.Ctx) (uuid.UUID, bool) {
 	userID, ok := c.Locals("userID").(uuid.UUID)
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/utils"
 )
 
@@ -25,6 +27,10 @@ const (
 	PermissionCreate = "create"
 	PermissionUpdate = "update"
 	PermissionDelete = "delete"
+	PermissionManage = "manage" // Implies read, create, update and delete on the same resource
+
+	// PermissionWildcard matches any action or resource, e.g. "*:order" or "read:*"
+	PermissionWildcard = "*"
 
 	// Prefixes for resource types
 	ResourceUser       = "user"
@@ -36,6 +42,7 @@ const (
 	ResourceInventory  = "inventory"
 	ResourceReport     = "report"
 	ResourceSetting    = "setting"
+	ResourceLog        = "log"
 
 	// Special permissions
 	PermissionManageUsers       = "manage:users"
@@ -50,7 +57,8 @@ func FormatPermission(action, resource string) string {
 	return fmt.Sprintf("%s:%s", action, resource)
 }
 
-// GetDefaultPermissionsForRole returns the default set of permissions for a standard role
+// GetDefaultPermissionsForRole returns the default set of permissions for a standard role.
+// Migrations seed these into role_permissions once; authorization only reads the stored set.
 func GetDefaultPermissionsForRole(roleName string) []string {
 	switch roleName {
 	case RoleSystem:
@@ -90,6 +98,7 @@ func GetDefaultPermissionsForRole(roleName string) []string {
 			FormatPermission(PermissionDelete, ResourceInventory),
 			FormatPermission(PermissionRead, ResourceSetting),
 			FormatPermission(PermissionUpdate, ResourceSetting),
+			FormatPermission(PermissionRead, ResourceLog),
 		}
 
 	case RoleAdmin:
@@ -154,23 +163,100 @@ func GetDefaultPermissionsForRole(roleName string) []string {
 	}
 }
 
-// HasPermission checks if a list of permissions includes a specific permission
+// HasPermission checks if any of the user's permissions grants a specific permission
 func HasPermission(userPermissions []string, requiredPermission string) bool {
-	// Full access permission grants access to everything
 	for _, p := range userPermissions {
-		if p == PermissionFullAccess {
+		if PermissionGrants(p, requiredPermission) {
 			return true
 		}
 	}
+	return false
+}
 
-	// Check for exact match
-	for _, p := range userPermissions {
-		if p == requiredPermission {
-			return true
+// PermissionGrants reports whether a granted permission covers the required one.
+// Besides an exact match, full:access covers everything, "*" matches any action or
+// resource, and manage:<resource> implies the CRUD actions on that resource. The
+// special permissions such as manage:users only share that form and imply nothing.
+func PermissionGrants(granted, required string) bool {
+	if granted == PermissionFullAccess || granted == required {
+		return true
+	}
+
+	grantedAction, grantedResource, ok := strings.Cut(granted, ":")
+	if !ok {
+		return false
+	}
+	requiredAction, requiredResource, ok := strings.Cut(required, ":")
+	if !ok {
+		return false
+	}
+
+	if grantedResource != PermissionWildcard && grantedResource != requiredResource {
+		return false
+	}
+
+	switch grantedAction {
+	case PermissionWildcard, requiredAction:
+		return true
+	case PermissionManage:
+		return !isSpecialPermission(granted) && isCRUDAction(requiredAction)
+	default:
+		return false
+	}
+}
+
+// ExpandPermissions lists the concrete permissions a set of granted permissions allows,
+// using the same matching as the RBAC checks. Wildcards are expanded against known,
+// typically every permission stored in the database, and manage:<resource> always
+// expands to the CRUD actions on that resource. Special permissions expand to nothing.
+func ExpandPermissions(granted, known []string) []string {
+	candidates := make(map[string]bool, len(known)+len(granted))
+	for _, name := range known {
+		candidates[name] = true
+	}
+	for _, name := range granted {
+		candidates[name] = true
+		if isSpecialPermission(name) {
+			continue
+		}
+		if action, resource, ok := strings.Cut(name, ":"); ok && action == PermissionManage && resource != PermissionWildcard {
+			for _, crud := range []string{PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete} {
+				candidates[FormatPermission(crud, resource)] = true
+			}
 		}
 	}
 
-	return false
+	effective := make([]string, 0, len(candidates))
+	for name := range candidates {
+		if strings.Contains(name, PermissionWildcard) {
+			continue
+		}
+		if HasPermission(granted, name) {
+			effective = append(effective, name)
+		}
+	}
+	sort.Strings(effective)
+	return effective
+}
+
+// isSpecialPermission reports whether a permission is one of the special permissions,
+// which guard whole features rather than actions on a resource
+func isSpecialPermission(name string) bool {
+	switch name {
+	case PermissionManageUsers, PermissionManageRoles, PermissionManagePermissions, PermissionAccessSystem, PermissionFullAccess:
+		return true
+	default:
+		return false
+	}
+}
+
+func isCRUDAction(action string) bool {
+	switch action {
+	case PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete:
+		return true
+	default:
+		return false
+	}
 }
 
 // RequireRole checks if the user has a specific role
@@ -258,23 +344,9 @@ func HasRolePrefix(prefix string) fiber.Handler {
 // RequirePermission checks if the user's role has a specific permission
 func RequirePermission(permissionName string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// System, Owner and Admin roles implicitly have all permissions
-		roleName, ok := GetRoleName(c)
-		if ok && (roleName == RoleSystem || roleName == RoleOwner || roleName == RoleAdmin) {
-			return c.Next()
-		}
-
-		// For other roles, check if user has specific permission
 		userPermissions, ok := GetUserPermissions(c)
-		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
-		}
-
-		// Check if the permission exists in the user's permissions
-		for _, p := range userPermissions {
-			if p == permissionName {
-				return c.Next()
-			}
+		if ok && HasPermission(userPermissions, permissionName) {
+			return c.Next()
 		}
 
 		return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
@@ -284,27 +356,14 @@ func RequirePermission(permissionName string) fiber.Handler {
 // RequireAnyPermission checks if the user's role has at least one of the specified permissions
 func RequireAnyPermission(permissionNames ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// System, Owner and Admin roles implicitly have all permissions
-		roleName, ok := GetRoleName(c)
-		if ok && (roleName == RoleSystem || roleName == RoleOwner || roleName == RoleAdmin) {
-			return c.Next()
-		}
-
-		// For other roles, check if user has any of the specified permissions
 		userPermissions, ok := GetUserPermissions(c)
 		if !ok {
 			return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
 		}
 
-		// Convert user permissions to a map for faster lookup
-		permMap := make(map[string]bool)
-		for _, p := range userPermissions {
-			permMap[p] = true
-		}
-
-		// Check if any required permission exists
+		// Check if any required permission is granted
 		for _, required := range permissionNames {
-			if permMap[required] {
+			if HasPermission(userPermissions, required) {
 				return c.Next()
 			}
 		}
@@ -316,27 +375,14 @@ func RequireAnyPermission(permissionNames ...string) fiber.Handler {
 // RequireAllPermissions checks if the user's role has all specified permissions
 func RequireAllPermissions(permissionNames ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// System, Owner and Admin roles implicitly have all permissions
-		roleName, ok := GetRoleName(c)
-		if ok && (roleName == RoleSystem || roleName == RoleOwner || roleName == RoleAdmin) {
-			return c.Next()
-		}
-
-		// For other roles, check if user has all the specified permissions
 		userPermissions, ok := GetUserPermissions(c)
 		if !ok {
 			return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
 		}
 
-		// Convert user permissions to a map for faster lookup
-		permMap := make(map[string]bool)
-		for _, p := range userPermissions {
-			permMap[p] = true
-		}
-
-		// Check if all required permissions exist
+		// Check if all required permissions are granted
 		for _, required := range permissionNames {
-			if !permMap[required] {
+			if !HasPermission(userPermissions, required) {
 				return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
 			}
 		}
@@ -351,15 +397,83 @@ func RequireResourcePermission(action, resource string) fiber.Handler {
 	return RequirePermission(permission)
 }
 
-// GetUserPermissions retrieves the authenticated user's permissions from context
+// PermissionResolver returns the current permissions of a role
+type PermissionResolver interface {
+	PermissionsForRole(roleID uuid.UUID) ([]string, error)
+}
+
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver makes the RBAC middleware read permissions from the resolver
+// instead of the token, so role changes apply without logging in again
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// GetUserPermissions retrieves the authenticated user's permissions, resolved once per request
 func GetUserPermissions(c *fiber.Ctx) ([]string, bool) {
-	perms, ok := c.Locals("permissions").([]string)
-	return perms, ok
+	if perms, ok := c.Locals("resolvedPermissions").([]string); ok {
+		return perms, true
+	}
+
+	scopes, isAPIKey := c.Locals("apiKeyScopes").([]string)
+	if permissionResolver == nil {
+		if isAPIKey {
+			return nil, false // API keys carry no permissions of their own
+		}
+		// Without a resolver, fall back to permissions carried by older tokens
+		perms, ok := c.Locals("permissions").([]string)
+		return perms, ok
+	}
+
+	roleID, ok := GetRoleID(c)
+	if !ok {
+		return nil, false
+	}
+	perms, err := permissionResolver.PermissionsForRole(roleID)
+	if err != nil {
+		return nil, false
+	}
+
+	// An API key only gets the part of its owner's permissions its scopes cover
+	if isAPIKey {
+		perms = RestrictPermissions(perms, scopes)
+	}
+
+	c.Locals("resolvedPermissions", perms)
+	return perms, true
+}
+
+// RestrictPermissions returns the permissions granted both by granted and by scopes:
+// the scopes granted covers, plus the permissions in granted a scope covers. For example
+// manage:order restricted to read:order gives read:order, and read:order restricted to
+// manage:order gives read:order as well.
+func RestrictPermissions(granted, scopes []string) []string {
+	restricted := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	add := func(permission string) {
+		if !seen[permission] {
+			seen[permission] = true
+			restricted = append(restricted, permission)
+		}
+	}
+
+	for _, scope := range scopes {
+		if HasPermission(granted, scope) {
+			add(scope)
+		}
+	}
+	for _, permission := range granted {
+		if HasPermission(scopes, permission) {
+			add(permission)
+		}
+	}
+	return restricted
 }
 
 // IsSameUserOrHigherRole ensures the authenticated user is either:
 // 1. The same user being accessed (based on ID)
-// 2. Has a higher privilege role (System, Owner, Admin)
+// 2. Granted the manage:users permission
 func IsSameUserOrHigherRole() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get target user ID from params
@@ -379,14 +493,9 @@ func IsSameUserOrHigherRole() fiber.Handler {
 			return c.Next()
 		}
 
-		// Otherwise, check if user has admin privileges
-		roleName, ok := GetRoleName(c)
-		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(utils.Error("Insufficient permissions", fiber.StatusForbidden))
-		}
-
-		// Allow if user has higher-level role
-		if roleName == RoleSystem || roleName == RoleOwner || roleName == RoleAdmin {
+		// Otherwise, the user must be allowed to manage other users
+		userPermissions, ok := GetUserPermissions(c)
+		if ok && HasPermission(userPermissions, PermissionManageUsers) {
 			return c.Next()
 		}
 
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// APIKeyTouchInterval limits how often a key's last use is written, since keys may be
+// used for every request of a busy integration
+const APIKeyTouchInterval = time.Minute
+
+type APIKeyRepository struct {
+	DB *gorm.DB
+}
+
+func (r *APIKeyRepository) CreateAPIKey(key *domain.APIKey) error {
+	return r.DB.Create(key).Error
+}
+
+func (r *APIKeyRepository) ListAPIKeys() ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	if err := r.DB.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListUserAPIKeys returns the keys that act for the given user
+func (r *APIKeyRepository) ListUserAPIKeys(userID uuid.UUID) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByID(id uuid.UUID) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.DB.First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindActiveAPIKeyByHash returns the key with the given hash unless it was revoked or expired
+func (r *APIKeyRepository) FindActiveAPIKeyByHash(keyHash string, now time.Time) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.DB.Where("key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", keyHash, now).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// TouchAPIKey records that the key was used, at most once per APIKeyTouchInterval
+func (r *APIKeyRepository) TouchAPIKey(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-APIKeyTouchInterval)).
+		Update("last_used_at", now).Error
+}
@@ -53,6 +53,24 @@ func (r *CategoryRepository) GetCategoryByIDWithProducts(id uuid.UUID) (*domain.
 	return &category, err
 }
 
+// ListActiveMenu fetches active categories with their available products and variations ordered by position
+func (r *CategoryRepository) ListActiveMenu() ([]domain.Category, error) {
+	var categories []domain.Category
+	err := r.DB.
+		Where("is_active = ?", true).
+		Order("position ASC").
+		Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_available = ?", true).Order("position ASC")
+		}).
+		Preload("Products.Variations", "is_available = ?", true).
+		Scopes(withBundleItems("Products.")).
+		Preload("Products.ModifierGroups", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_available = ?", true).Order("position ASC")
+		}).
+		Find(&categories).Error
+	return categories, err
+}
+
 // Create inserts a new category into the database
 func (r *CategoryRepository) CreateCategory(category *domain.Category) error {
 	return r.DB.Create(category).Error
@@ -63,11 +81,23 @@ func (r *CategoryRepository) UpdateCategory(category *domain.Category) error {
 	return r.DB.Save(category).Error
 }
 
-// Delete removes a category by its ID
+// Delete soft deletes a category by its ID
 func (r *CategoryRepository) DeleteCategory(id uuid.UUID) error {
 	return r.DB.Delete(&domain.Category{}, "id = ?", id).Error
 }
 
+func (r *CategoryRepository) ListDeletedCategories() ([]domain.Category, error) {
+	return listDeleted[domain.Category](r.DB)
+}
+
+func (r *CategoryRepository) GetDeletedCategory(id uuid.UUID) (*domain.Category, error) {
+	return findDeleted[domain.Category](r.DB, id)
+}
+
+func (r *CategoryRepository) RestoreCategory(id uuid.UUID) error {
+	return restoreDeleted[domain.Category](r.DB, id)
+}
+
 // Helper Function
 func (r *CategoryRepository) ExistsByName(name string) (bool, error) {
 	var count int64
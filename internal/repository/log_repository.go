@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type LogRepository struct {
+	DB *gorm.DB
+}
+
+// LogQuery selects logs; zero fields do not filter
+type LogQuery struct {
+	Level     string
+	Source    string
+	Action    string
+	Entity    string
+	EntityID  *uuid.UUID
+	UserID    *uuid.UUID
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	Metadata  map[string]string // Top-level metadata keys and their values as text
+	// Keyset pagination: continue after the log with this timestamp and ID
+	AfterTimestamp *time.Time
+	AfterID        uuid.UUID
+	Limit          int
+}
+
+func (r *LogRepository) CreateLog(log *domain.Log) error {
+	return r.DB.Create(log).Error
+}
+
+// ListLogs returns logs newest first. Metadata keys are interpolated into the query,
+// so callers must only pass keys they have validated.
+func (r *LogRepository) ListLogs(query LogQuery) ([]domain.Log, error) {
+	db := r.DB.Model(&domain.Log{})
+
+	for column, value := range map[string]string{
+		"level":      query.Level,
+		"source":     query.Source,
+		"action":     query.Action,
+		"entity":     query.Entity,
+		"request_id": query.RequestID,
+	} {
+		if value != "" {
+			db = db.Where(column+" = ?", value)
+		}
+	}
+	if query.EntityID != nil {
+		db = db.Where("entity_id = ?", *query.EntityID)
+	}
+	if query.UserID != nil {
+		db = db.Where("user_id = ?", *query.UserID)
+	}
+	if query.From != nil {
+		db = db.Where("timestamp >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("timestamp < ?", *query.To)
+	}
+	// ->> reads a key as text in both PostgreSQL and SQLite
+	for key, value := range query.Metadata {
+		db = db.Where("metadata->>'"+key+"' = ?", value)
+	}
+	if query.AfterTimestamp != nil {
+		db = db.Where("timestamp < ? OR (timestamp = ? AND id < ?)", *query.AfterTimestamp, *query.AfterTimestamp, query.AfterID)
+	}
+
+	var logs []domain.Log
+	err := db.Order("timestamp DESC").Order("id DESC").Limit(query.Limit).Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type LoginFailureRepository struct {
+	DB *gorm.DB
+}
+
+// LoginLockStep locks a key until Until once its failures reach Failures
+type LoginLockStep struct {
+	Failures int
+	Until    time.Time
+}
+
+// CountLoginAttempt adds an attempt to the failures of a key and sets the lock the new count
+// earns, in one statement so concurrent attempts cannot overwrite each other. steps must be
+// ordered by Failures, largest first. Failures older than windowStart are forgotten first.
+// counted is false, and nothing is counted, while the key is locked.
+func (r *LoginFailureRepository) CountLoginAttempt(key string, now, windowStart time.Time, steps []LoginLockStep) (failures int, counted bool, err error) {
+	if err := r.DB.Where("key = ? AND last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", key, windowStart, windowStart).
+		Delete(&domain.LoginFailure{}).Error; err != nil {
+		return 0, false, err
+	}
+
+	// A fresh key starts at one failure
+	var firstLock *time.Time
+	for _, step := range steps {
+		if step.Failures <= 1 {
+			firstLock = &step.Until
+			break
+		}
+	}
+
+	lock := "NULL"
+	var lockArgs []interface{}
+	if len(steps) > 0 {
+		var b strings.Builder
+		b.WriteString("CASE")
+		for _, step := range steps {
+			b.WriteString(" WHEN login_failures.failures + 1 >= ? THEN ?")
+			lockArgs = append(lockArgs, step.Failures, step.Until)
+		}
+		b.WriteString(" END")
+		lock = b.String()
+	}
+
+	args := []interface{}{key, now, firstLock, now}
+	args = append(args, lockArgs...)
+	args = append(args, now)
+
+	var rows []struct{ Failures int }
+	err = r.DB.Raw(`INSERT INTO login_failures ("key", failures, last_failed_at, locked_until) VALUES (?, 1, ?, ?)
+		ON CONFLICT ("key") DO UPDATE SET failures = login_failures.failures + 1, last_failed_at = ?, locked_until = `+lock+`
+		WHERE login_failures.locked_until IS NULL OR login_failures.locked_until <= ?
+		RETURNING failures`, args...).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return 0, false, err
+	}
+	return rows[0].Failures, true, nil
+}
+
+// ReleaseLoginAttempt takes back an attempt counted by CountLoginAttempt, along with the
+// lock it set, if any
+func (r *LoginFailureRepository) ReleaseLoginAttempt(key string, lockedUntil *time.Time) error {
+	updates := map[string]interface{}{"failures": gorm.Expr("failures - 1")}
+	if lockedUntil != nil {
+		updates["locked_until"] = gorm.Expr("CASE WHEN locked_until = ? THEN NULL ELSE locked_until END", *lockedUntil)
+	}
+	return r.DB.Model(&domain.LoginFailure{}).Where("key = ? AND failures > 0", key).Updates(updates).Error
+}
+
+// FindLoginFailure returns the failures tracked for a key, or nil if there are none
+func (r *LoginFailureRepository) FindLoginFailure(key string) (*domain.LoginFailure, error) {
+	var failures []domain.LoginFailure
+	if err := r.DB.Where("key = ?", key).Limit(1).Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	if len(failures) == 0 {
+		return nil, nil
+	}
+	return &failures[0], nil
+}
+
+func (r *LoginFailureRepository) DeleteLoginFailure(key string) error {
+	return r.DB.Where("key = ?", key).Delete(&domain.LoginFailure{}).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ModifierRepository struct {
+	DB *gorm.DB
+}
+
+// ListModifierGroupsByProductID fetches all modifier groups of a product ordered by position
+func (r *ModifierRepository) ListModifierGroupsByProductID(productID uuid.UUID) ([]domain.ModifierGroup, error) {
+	var groups []domain.ModifierGroup
+	err := r.DB.Where("product_id = ?", productID).Order("position ASC").Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetModifierGroupByID fetches a modifier group by its ID
+func (r *ModifierRepository) GetModifierGroupByID(id uuid.UUID) (*domain.ModifierGroup, error) {
+	var group domain.ModifierGroup
+	err := r.DB.Where("id = ?", id).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// CreateModifierGroup inserts a new modifier group
+func (r *ModifierRepository) CreateModifierGroup(group *domain.ModifierGroup) error {
+	return r.DB.Create(group).Error
+}
+
+// UpdateModifierGroup saves changes to an existing modifier group
+func (r *ModifierRepository) UpdateModifierGroup(group *domain.ModifierGroup) error {
+	return r.DB.Save(group).Error
+}
+
+// DeleteModifierGroup removes a modifier group by its ID
+func (r *ModifierRepository) DeleteModifierGroup(id uuid.UUID) error {
+	return r.DB.Delete(&domain.ModifierGroup{}, "id = ?", id).Error
+}
+
+// Helper Function
+func (r *ModifierRepository) ProductExists(productID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM products WHERE id = ?)", productID).Scan(&exists).Error
+	return exists, err
+}
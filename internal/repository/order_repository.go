@@ -16,8 +16,9 @@ func (repo *OrderRepository) ListAllOrders() ([]domain.Order, error) {
 	var orders []domain.Order
 	if err := repo.DB.
 		Preload("OrderDetails").
-		Preload("OrderDetails.Product").
-		Preload("OrderDetails.Variation").
+		Preload("OrderDetails.Product", unscoped).
+		Preload("OrderDetails.Variation", unscoped).
+		Preload("OrderDetails.Components").
 		Preload("Payments").
 		Preload("Invoice").
 		Find(&orders).Error; err != nil {
@@ -82,7 +83,7 @@ func (repo *OrderRepository) GetOrderWithDetails(orderID uuid.UUID) (*domain.Ord
 	// Use preload to retrieve the order with all its relationships
 	if err := repo.DB.
 		Preload("OrderDetails").
-		Preload("User").
+		Preload("User", unscoped).
 		Preload("Payments").
 		Preload("Invoice").
 		First(&order, "id = ?", orderID).Error; err != nil {
@@ -96,8 +97,9 @@ func (r *OrderRepository) GetOrderWithAssociations(orderID uuid.UUID) (*domain.O
 	var order domain.Order
 	if err := r.DB.
 		Preload("OrderDetails").
-		Preload("OrderDetails.Product").
-		Preload("OrderDetails.Variation").
+		Preload("OrderDetails.Product", unscoped).
+		Preload("OrderDetails.Variation", unscoped).
+		Preload("OrderDetails.Components").
 		Preload("Payments").
 		Preload("Invoice").
 		First(&order, "id = ?", orderID).Error; err != nil {
@@ -105,3 +107,9 @@ func (r *OrderRepository) GetOrderWithAssociations(orderID uuid.UUID) (*domain.O
 	}
 	return &order, nil
 }
+
+// unscoped makes a preload include soft-deleted rows, so orders keep showing the
+// users, products and variations they refer to after those are deleted
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	DB *gorm.DB
+}
+
+func (r *PasswordResetRepository) CreatePasswordResetToken(token *domain.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *PasswordResetRepository) FindPasswordResetTokenByHash(hash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	if err := r.DB.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks an unused token as used. It reports false when the
+// token was already used, so the same link cannot reset the password twice.
+func (r *PasswordResetRepository) ConsumePasswordResetToken(id uuid.UUID, now time.Time) (bool, error) {
+	result := r.DB.Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateUserPasswordResetTokens marks every unused token of a user as used
+func (r *PasswordResetRepository) InvalidateUserPasswordResetTokens(userID uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
@@ -23,6 +23,14 @@ func (r *PaymentRepository) Create(payment *domain.Payment) error {
 	return r.DB.Create(payment).Error
 }
 
+func (r *PaymentRepository) GetByID(id uuid.UUID) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.DB.First(&payment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
 // internal/repository/payment_repository.go - Add these methods
 func (r *PaymentRepository) GetByOrderID(orderID uuid.UUID) ([]domain.Payment, error) {
 	var payments []domain.Payment
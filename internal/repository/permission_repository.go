@@ -32,6 +32,13 @@ func (r *PermissionRepository) ListAllPermissions(page, limit int) ([]domain.Per
 	return permissions, totalCount, err
 }
 
+// ListPermissionNames returns the name of every stored permission
+func (r *PermissionRepository) ListPermissionNames() ([]string, error) {
+	var names []string
+	err := r.DB.Model(&domain.Permission{}).Order("name").Pluck("name", &names).Error
+	return names, err
+}
+
 func (r *PermissionRepository) GetPermissionByID(id uuid.UUID) (*domain.Permission, error) {
 	var permission domain.Permission
 	err := r.DB.Preload("Roles").First(&permission, "id = ?", id).Error
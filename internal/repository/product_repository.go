@@ -18,6 +18,7 @@ func (r *ProductRepository) ListAllProducts() ([]domain.Product, error) {
 	err := r.DB.
 		Preload("Category").
 		Preload("Variations").
+		Scopes(withBundleItems(""), withModifierGroups).
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -39,6 +40,7 @@ func (r *ProductRepository) ListProductsPaginated(offset, limit int) ([]domain.P
 	err := r.DB.
 		Preload("Category").
 		Preload("Variations").
+		Scopes(withBundleItems(""), withModifierGroups).
 		Offset(offset).
 		Limit(limit).
 		Find(&products).Error
@@ -53,7 +55,7 @@ func (r *ProductRepository) ListProductsPaginated(offset, limit int) ([]domain.P
 // GetProductByID fetches a product by its ID
 func (r *ProductRepository) GetProductByID(id uuid.UUID) (*domain.Product, error) {
 	var product domain.Product
-	err := r.DB.Preload("Category").Preload("Variations").Where("id = ?", id).First(&product).Error
+	err := r.DB.Preload("Category").Preload("Variations").Scopes(withBundleItems(""), withModifierGroups).Where("id = ?", id).First(&product).Error
 	if err != nil {
 		return nil, err
 	}
@@ -65,9 +67,30 @@ func (r *ProductRepository) LoadProductWithRelations(id uuid.UUID, dest *domain.
 		Model(&domain.Product{}).
 		Preload("Category").
 		Preload("Variations").
+		Scopes(withBundleItems(""), withModifierGroups).
 		First(dest, "id = ?", id).Error
 }
 
+// withBundleItems preloads bundle slots, ordered by position, with their component
+// and choice products. prefix is the association path leading to the product, e.g. "Products.".
+func withBundleItems(prefix string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Preload(prefix+"BundleItems", func(db *gorm.DB) *gorm.DB {
+				return db.Order("position ASC")
+			}).
+			Preload(prefix + "BundleItems.Product").
+			Preload(prefix + "BundleItems.Choices.Product")
+	}
+}
+
+// withModifierGroups preloads modifier groups ordered by position
+func withModifierGroups(db *gorm.DB) *gorm.DB {
+	return db.Preload("ModifierGroups", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	})
+}
+
 // CreateProduct inserts a new product into the database
 func (r *ProductRepository) CreateProduct(product *domain.Product) error {
 	return r.DB.Create(product).Error
@@ -78,11 +101,32 @@ func (r *ProductRepository) UpdateProduct(product *domain.Product) error {
 	return r.DB.Save(product).Error
 }
 
-// DeleteProduct removes a product by its ID
+// DeleteProduct soft deletes a product by its ID, so past orders keep resolving it
 func (r *ProductRepository) DeleteProduct(id uuid.UUID) error {
 	return r.DB.Delete(&domain.Product{}, "id = ?", id).Error
 }
 
+func (r *ProductRepository) ListDeletedProducts() ([]domain.Product, error) {
+	return listDeleted[domain.Product](r.DB)
+}
+
+func (r *ProductRepository) GetDeletedProduct(id uuid.UUID) (*domain.Product, error) {
+	return findDeleted[domain.Product](r.DB, id)
+}
+
+func (r *ProductRepository) RestoreProduct(id uuid.UUID) error {
+	return restoreDeleted[domain.Product](r.DB, id)
+}
+
+// ImageURLInUse reports whether any product, deleted ones included, still uses url as its image
+func (r *ProductRepository) ImageURLInUse(url string) (bool, error) {
+	var count int64
+	if err := r.DB.Unscoped().Model(&domain.Product{}).Where("image_url = ?", url).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("error checking image usage: %w", err)
+	}
+	return count > 0, nil
+}
+
 // Helper Function
 
 // CategoryExists checks if a category exists in the database by its ID.
@@ -22,6 +22,12 @@ func (r *RoleRepository) GetRoleByID(id uuid.UUID) (*domain.Role, error) {
 	return &role, err
 }
 
+func (r *RoleRepository) GetRoleByName(name string) (*domain.Role, error) {
+	var role domain.Role
+	err := r.DB.First(&role, "name = ?", name).Error
+	return &role, err
+}
+
 func (r *RoleRepository) CreateRole(role *domain.Role, tx *gorm.DB) error {
 	db := r.DB
 	if tx != nil {
@@ -50,22 +56,36 @@ func (r *RoleRepository) UpdateRole(role *domain.Role) error {
 	})
 }
 
+// DeleteRole soft deletes a role. Its permissions are kept, so a restored role
+// grants what it did before.
 func (r *RoleRepository) DeleteRole(id uuid.UUID) error {
-	return r.DB.Transaction(func(tx *gorm.DB) error {
-		// Get the role first
-		var role domain.Role
-		if err := tx.First(&role, "id = ?", id).Error; err != nil {
-			return err
-		}
+	result := r.DB.Delete(&domain.Role{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
 
-		// Clear permission associations
-		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
-			return err
-		}
+func (r *RoleRepository) ListDeletedRoles() ([]domain.Role, error) {
+	return listDeleted[domain.Role](r.DB)
+}
 
-		// Delete the role
-		return tx.Delete(&role).Error
-	})
+func (r *RoleRepository) GetDeletedRole(id uuid.UUID) (*domain.Role, error) {
+	return findDeleted[domain.Role](r.DB, id)
+}
+
+func (r *RoleRepository) RestoreRole(id uuid.UUID) error {
+	return restoreDeleted[domain.Role](r.DB, id)
+}
+
+// CountUsersWithRole counts the users, other than deleted ones, who have the role
+func (r *RoleRepository) CountUsersWithRole(id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.DB.Model(&domain.User{}).Where("role_id = ?", id).Count(&count).Error
+	return count, err
 }
 
 // New method to set role permissions
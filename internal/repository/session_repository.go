@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct {
+	DB *gorm.DB
+}
+
+func (r *SessionRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *SessionRepository) FindRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	if err := r.DB.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeRefreshToken revokes a token that has not been used yet. It reports false when
+// the token was already revoked, so concurrent refreshes cannot both succeed.
+func (r *SessionRepository) ConsumeRefreshToken(id uuid.UUID, now time.Time) (bool, error) {
+	result := r.DB.Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeRefreshTokenFamily revokes every token rotated from the same login
+func (r *SessionRepository) RevokeRefreshTokenFamily(familyID uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of a user
+func (r *SessionRepository) RevokeUserRefreshTokens(userID uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+func (r *SessionRepository) RevokeAccessToken(token *domain.RevokedToken) error {
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *SessionRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&domain.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteExpiredRevokedTokens drops revocation entries for tokens that have expired anyway
+func (r *SessionRepository) DeleteExpiredRevokedTokens(now time.Time) error {
+	return r.DB.Where("expires_at < ?", now).Delete(&domain.RevokedToken{}).Error
+}
+
+func (r *SessionRepository) SetUserSessionsRevokedAt(userID uuid.UUID, revokedAt time.Time) error {
+	return r.DB.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("sessions_revoked_at", revokedAt).Error
+}
+
+// GetUserSessionsRevokedAt returns the user's session cutoff; gorm.ErrRecordNotFound means the user no longer exists
+func (r *SessionRepository) GetUserSessionsRevokedAt(userID uuid.UUID) (*time.Time, error) {
+	var user domain.User
+	if err := r.DB.Select("id", "sessions_revoked_at").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return user.SessionsRevokedAt, nil
+}
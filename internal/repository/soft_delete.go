@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// listDeleted returns the soft-deleted rows of T, most recently deleted first
+func listDeleted[T any](db *gorm.DB) ([]T, error) {
+	var rows []T
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// findDeleted returns the soft-deleted row of T with the given ID
+func findDeleted[T any](db *gorm.DB, id uuid.UUID) (*T, error) {
+	var row T
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&row).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// restoreDeleted undeletes the soft-deleted row of T with the given ID, and returns
+// gorm.ErrRecordNotFound if there is none
+func restoreDeleted[T any](db *gorm.DB, id uuid.UUID) error {
+	result := db.Unscoped().Model(new(T)).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TerminalRepository struct {
+	DB *gorm.DB
+}
+
+func (r *TerminalRepository) CreateTerminal(terminal *domain.Terminal) error {
+	return r.DB.Create(terminal).Error
+}
+
+func (r *TerminalRepository) ListTerminals() ([]domain.Terminal, error) {
+	var terminals []domain.Terminal
+	if err := r.DB.Order("created_at DESC").Find(&terminals).Error; err != nil {
+		return nil, err
+	}
+	return terminals, nil
+}
+
+func (r *TerminalRepository) GetTerminalByID(id uuid.UUID) (*domain.Terminal, error) {
+	var terminal domain.Terminal
+	if err := r.DB.First(&terminal, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+// FindActiveTerminalByKeyHash returns the terminal with the given key unless it was revoked
+func (r *TerminalRepository) FindActiveTerminalByKeyHash(keyHash string) (*domain.Terminal, error) {
+	var terminal domain.Terminal
+	if err := r.DB.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&terminal).Error; err != nil {
+		return nil, err
+	}
+	return &terminal, nil
+}
+
+func (r *TerminalRepository) RevokeTerminal(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.Terminal{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+func (r *TerminalRepository) TouchTerminal(id uuid.UUID, now time.Time) error {
+	return r.DB.Model(&domain.Terminal{}).
+		Where("id = ?", id).
+		Update("last_used_at", now).Error
+}
+
+// IsTerminalRevoked reports whether a terminal was revoked or no longer exists
+func (r *TerminalRepository) IsTerminalRevoked(id uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&domain.Terminal{}).Where("id = ? AND revoked_at IS NULL", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
@@ -45,6 +45,16 @@ func (r *ThemeRepository) UpdateTheme(theme *domain.Theme) (*domain.Theme, error
 	return theme, nil
 }
 
+func (r *ThemeRepository) GetThemeByName(name string) (*domain.Theme, error) {
+	var theme domain.Theme
+	err := r.DB.First(&theme, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &theme, nil
+}
+
+// DeleteTheme soft deletes a theme; its logo and favicon stay in storage for a restore
 func (r *ThemeRepository) DeleteTheme(id uuid.UUID) error {
 	var theme domain.Theme
 	err := r.DB.First(&theme, "id = ?", id).Error
@@ -57,3 +67,15 @@ func (r *ThemeRepository) DeleteTheme(id uuid.UUID) error {
 	}
 	return nil
 }
+
+func (r *ThemeRepository) ListDeletedThemes() ([]domain.Theme, error) {
+	return listDeleted[domain.Theme](r.DB)
+}
+
+func (r *ThemeRepository) GetDeletedTheme(id uuid.UUID) (*domain.Theme, error) {
+	return findDeleted[domain.Theme](r.DB, id)
+}
+
+func (r *ThemeRepository) RestoreTheme(id uuid.UUID) error {
+	return restoreDeleted[domain.Theme](r.DB, id)
+}
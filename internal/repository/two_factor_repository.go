@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+// SetPendingTOTPSecret stores a secret that is not required at login until enrollment is confirmed
+func (r *TwoFactorRepository) SetPendingTOTPSecret(userID uuid.UUID, secret string) error {
+	return r.DB.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"totp_secret":     secret,
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+}
+
+// EnableTOTP confirms enrollment and replaces the user's recovery codes
+func (r *TwoFactorRepository) EnableTOTP(userID uuid.UUID, now time.Time, step int64, codes []domain.RecoveryCode) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled_at": now,
+				"totp_last_step":  step,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// DisableTOTP removes the user's secret and recovery codes
+func (r *TwoFactorRepository) DisableTOTP(userID uuid.UUID) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_secret":     nil,
+				"totp_enabled_at": nil,
+				"totp_last_step":  0,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+	})
+}
+
+// AdvanceTOTPStep records the time step of an accepted code. It reports false when a
+// code from that step or a later one was already used, so a code cannot be replayed.
+func (r *TwoFactorRepository) AdvanceTOTPStep(userID uuid.UUID, step int64) (bool, error) {
+	result := r.DB.Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []domain.RecoveryCode) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// ConsumeRecoveryCode marks an unused recovery code of the user as used. It reports
+// false when there is no such code.
+func (r *TwoFactorRepository) ConsumeRecoveryCode(userID uuid.UUID, codeHash string, now time.Time) (bool, error) {
+	result := r.DB.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.DB.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []domain.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
@@ -54,6 +54,7 @@ func (r *UserRepository) UpdateUser(user *domain.User) (*domain.User, error) {
 	return user, nil
 }
 
+// DeleteUser soft deletes a user; RestoreUser brings them back
 func (r *UserRepository) DeleteUser(id uuid.UUID) error {
 	err := r.DB.Delete(&domain.User{}, id).Error
 	if err != nil {
@@ -62,6 +63,25 @@ func (r *UserRepository) DeleteUser(id uuid.UUID) error {
 	return nil
 }
 
+func (r *UserRepository) ListDeletedUsers() ([]domain.User, error) {
+	return listDeleted[domain.User](r.DB)
+}
+
+func (r *UserRepository) GetDeletedUser(id uuid.UUID) (*domain.User, error) {
+	return findDeleted[domain.User](r.DB, id)
+}
+
+func (r *UserRepository) RestoreUser(id uuid.UUID) error {
+	return restoreDeleted[domain.User](r.DB, id)
+}
+
+// RoleExists reports whether a role exists and is not deleted
+func (r *UserRepository) RoleExists(roleID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.DB.Model(&domain.Role{}).Where("id = ?", roleID).Count(&count).Error
+	return count > 0, err
+}
+
 // LoginUser checks if the user exists and returns the user if found
 func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
 	var user domain.User
@@ -76,3 +96,29 @@ func (r *UserRepository) UpdateLastLogin(userID uuid.UUID, time time.Time) error
 		Where("id = ?", userID).
 		Update("last_login_at", time).Error
 }
+
+func (r *UserRepository) SetUserPassword(userID uuid.UUID, passwordHash string) error {
+	return r.DB.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("password", passwordHash).Error
+}
+
+func (r *UserRepository) SetUserPin(userID uuid.UUID, pinHash *string) error {
+	return r.DB.Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("pin_hash", pinHash).Error
+}
+
+// ListPinUsers returns the users with a PIN whose role is one of roleNames
+func (r *UserRepository) ListPinUsers(roleNames []string) ([]domain.User, error) {
+	var users []domain.User
+	err := r.DB.Preload("Role").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("users.pin_hash IS NOT NULL AND roles.name IN ?", roleNames).
+		Order("users.name").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
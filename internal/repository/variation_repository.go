@@ -42,17 +42,29 @@ func (r *VariationRepository) UpdateVariation(variation *domain.Variation) error
 	return r.DB.Save(variation).Error
 }
 
-// DeleteVariation deletes a variation by its ID
+// DeleteVariation soft deletes a variation by its ID
 func (r *VariationRepository) DeleteVariation(id uuid.UUID) error {
 	return r.DB.Delete(&domain.Variation{}, id).Error
 }
 
+func (r *VariationRepository) ListDeletedVariations() ([]domain.Variation, error) {
+	return listDeleted[domain.Variation](r.DB)
+}
+
+func (r *VariationRepository) GetDeletedVariation(id uuid.UUID) (*domain.Variation, error) {
+	return findDeleted[domain.Variation](r.DB, id)
+}
+
+func (r *VariationRepository) RestoreVariation(id uuid.UUID) error {
+	return restoreDeleted[domain.Variation](r.DB, id)
+}
+
 // TODO Implement Function for Product Tied Variations
 
 // Helper Function
 func (r *VariationRepository) ProductExists(productID uuid.UUID) (bool, error) {
 	var exists bool
-	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM products WHERE id = ?)", productID).Scan(&exists).Error
+	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM products WHERE id = ? AND deleted_at IS NULL)", productID).Scan(&exists).Error
 	return exists, err
 }
 
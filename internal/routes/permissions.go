@@ -0,0 +1,277 @@
+package routes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+)
+
+// apiPrefix is the path every versioned API route is registered under
+const apiPrefix = "/api/v1"
+
+var (
+	readMenu   = middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceMenu)
+	createMenu = middleware.FormatPermission(middleware.PermissionCreate, middleware.ResourceMenu)
+	updateMenu = middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceMenu)
+	deleteMenu = middleware.FormatPermission(middleware.PermissionDelete, middleware.ResourceMenu)
+
+	readOrder   = middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceOrder)
+	updateOrder = middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceOrder)
+
+	readTable   = middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceTable)
+	createTable = middleware.FormatPermission(middleware.PermissionCreate, middleware.ResourceTable)
+	deleteTable = middleware.FormatPermission(middleware.PermissionDelete, middleware.ResourceTable)
+
+	readSetting   = middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceSetting)
+	updateSetting = middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceSetting)
+)
+
+// publicRoutes are reachable without a token; every other API route must be listed in routePermissions
+var publicRoutes = map[string]bool{
+	"POST /api/v1/auth/login":              true,
+	"POST /api/v1/auth/refresh":            true,
+	"POST /api/v1/auth/logout":             true,
+	"POST /api/v1/auth/password-reset":     true, // Authenticated by the reset token
+	"POST /api/v1/auth/2fa/verify":         true, // Authenticated by the two-factor token
+	"POST /api/v1/auth/2fa/enroll":         true,
+	"POST /api/v1/auth/2fa/enroll/confirm": true,
+	"POST /api/v1/auth/pin-login":          true, // Terminals authenticate with their device key
+	"GET /api/v1/terminal/staff":           true,
+	"GET /api/v1/menu":                     true,
+	"GET /api/v1/categories":               true,
+	"POST /api/v1/orders":                  true, // Customers order from the table QR code
+	"GET /api/v1/themes":                   true,
+}
+
+// authenticatedRoutes only need a valid token, because they describe the caller's own account
+var authenticatedRoutes = map[string]bool{
+	"GET /api/v1/me":                     true,
+	"POST /api/v1/me/can":                true,
+	"POST /api/v1/me/password":           true,
+	"GET /api/v1/me/2fa":                 true,
+	"POST /api/v1/me/2fa/enroll":         true,
+	"POST /api/v1/me/2fa/confirm":        true,
+	"POST /api/v1/me/2fa/recovery-codes": true,
+	"POST /api/v1/me/2fa/disable":        true,
+}
+
+// routePermissions declares the permissions a protected route requires; holding any
+// one of them is enough. QR codes are managed as part of tables, payments as part
+// of orders and themes as settings.
+var routePermissions = map[string][]string{
+	"GET /api/v1/admin/dashboard": {middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceReport)},
+
+	// Users, roles and permissions
+	"GET /api/v1/users":                     {middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceUser)},
+	"POST /api/v1/users":                    {middleware.FormatPermission(middleware.PermissionCreate, middleware.ResourceUser)},
+	"PUT /api/v1/users/:id":                 {middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceUser)},
+	"DELETE /api/v1/users/:id":              {middleware.FormatPermission(middleware.PermissionDelete, middleware.ResourceUser)},
+	"GET /api/v1/users/deleted":             {middleware.FormatPermission(middleware.PermissionDelete, middleware.ResourceUser)},
+	"POST /api/v1/users/:id/restore":        {middleware.FormatPermission(middleware.PermissionDelete, middleware.ResourceUser)},
+	"POST /api/v1/users/:id/unlock":         {middleware.PermissionManageUsers},
+	"POST /api/v1/users/:id/password-reset": {middleware.PermissionManageUsers},
+	"DELETE /api/v1/users/:id/2fa":          {middleware.PermissionManageUsers},
+	"PUT /api/v1/users/:id/pin":             {middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceUser)},
+
+	// Shared staff terminals
+	"POST /api/v1/terminals":       {updateSetting},
+	"GET /api/v1/terminals":        {readSetting},
+	"DELETE /api/v1/terminals/:id": {updateSetting},
+
+	// API keys for integrations; callers see their own keys, administrators everyone's
+	"POST /api/v1/api-keys":             {updateSetting},
+	"GET /api/v1/api-keys":              {readSetting},
+	"DELETE /api/v1/api-keys/:id":       {updateSetting},
+	"GET /api/v1/admin/api-keys":        {middleware.PermissionManageUsers},
+	"DELETE /api/v1/admin/api-keys/:id": {middleware.PermissionManageUsers},
+
+	"GET /api/v1/roles":                    {middleware.PermissionManageRoles},
+	"GET /api/v1/roles/:id":                {middleware.PermissionManageRoles},
+	"POST /api/v1/roles":                   {middleware.PermissionManageRoles},
+	"PUT /api/v1/roles/:id":                {middleware.PermissionManageRoles},
+	"DELETE /api/v1/roles/:id":             {middleware.PermissionManageRoles},
+	"GET /api/v1/roles/deleted":            {middleware.PermissionManageRoles},
+	"POST /api/v1/roles/:id/restore":       {middleware.PermissionManageRoles},
+	"POST /api/v1/roles/comprehensive":     {middleware.PermissionManageRoles},
+	"POST /api/v1/roles/:id/permissions":   {middleware.PermissionManageRoles},
+	"DELETE /api/v1/roles/:id/permissions": {middleware.PermissionManageRoles},
+
+	"GET /api/v1/permissions":           {middleware.PermissionManagePermissions},
+	"GET /api/v1/permissions/:id":       {middleware.PermissionManagePermissions},
+	"POST /api/v1/permissions":          {middleware.PermissionManagePermissions},
+	"PUT /api/v1/permissions/:id":       {middleware.PermissionManagePermissions},
+	"DELETE /api/v1/permissions/:id":    {middleware.PermissionManagePermissions},
+	"POST /api/v1/permissions/generate": {middleware.PermissionManagePermissions},
+
+	// QR codes
+	"GET /api/v1/qr-codes":                 {readTable},
+	"GET /api/v1/qr-codes/:id":             {readTable},
+	"GET /api/v1/qr-codes/store/:store_id": {readTable},
+	"POST /api/v1/qr-codes":                {createTable},
+	"POST /api/v1/qr-codes/bulk":           {createTable},
+	"DELETE /api/v1/qr-codes/:id":          {deleteTable},
+
+	// Menu: categories, products, variations and modifier groups
+	"GET /api/v1/categories/:id":          {readMenu},
+	"POST /api/v1/categories":             {createMenu},
+	"PUT /api/v1/categories/:id":          {updateMenu},
+	"DELETE /api/v1/categories/:id":       {deleteMenu},
+	"GET /api/v1/categories/deleted":      {deleteMenu},
+	"POST /api/v1/categories/:id/restore": {deleteMenu},
+
+	"GET /api/v1/products":              {readMenu},
+	"GET /api/v1/products/:id":          {readMenu},
+	"POST /api/v1/products":             {createMenu},
+	"PUT /api/v1/products/:id":          {updateMenu},
+	"DELETE /api/v1/products/:id":       {deleteMenu},
+	"GET /api/v1/products/deleted":      {deleteMenu},
+	"POST /api/v1/products/:id/restore": {deleteMenu},
+
+	// The upload handler also checks the permission for the specific target
+	"POST /api/v1/uploads/presign": {updateMenu, updateSetting},
+	"POST /api/v1/uploads/confirm": {updateMenu, updateSetting},
+
+	"GET /api/v1/variations":              {readMenu},
+	"GET /api/v1/variations/:id":          {readMenu},
+	"POST /api/v1/variations":             {createMenu},
+	"PUT /api/v1/variations/:id":          {updateMenu},
+	"DELETE /api/v1/variations/:id":       {deleteMenu},
+	"GET /api/v1/variations/deleted":      {deleteMenu},
+	"POST /api/v1/variations/:id/restore": {deleteMenu},
+
+	"GET /api/v1/products/:product_id/variations":        {readMenu},
+	"POST /api/v1/products/:product_id/variations":       {createMenu},
+	"PUT /api/v1/products/:product_id/variations/:id":    {updateMenu},
+	"DELETE /api/v1/products/:product_id/variations/:id": {deleteMenu},
+
+	"GET /api/v1/products/:product_id/modifier-groups":        {readMenu},
+	"POST /api/v1/products/:product_id/modifier-groups":       {createMenu},
+	"PUT /api/v1/products/:product_id/modifier-groups/:id":    {updateMenu},
+	"DELETE /api/v1/products/:product_id/modifier-groups/:id": {deleteMenu},
+
+	"POST /api/v1/menu/import": {createMenu},
+	"GET /api/v1/menu/export":  {readMenu},
+
+	// Orders and payments
+	"GET /api/v1/orders":                    {readOrder},
+	"GET /api/v1/orders/:id":                {readOrder},
+	"PUT /api/v1/orders/:id":                {updateOrder},
+	"POST /api/v1/orders/:orderID/complete": {updateOrder},
+	"POST /api/v1/orders/:orderID/cancel":   {updateOrder},
+
+	"GET /api/v1/payments":                  {readOrder},
+	"GET /api/v1/orders/:orderID/payments":  {readOrder},
+	"POST /api/v1/orders/:orderID/payments": {updateOrder},
+
+	// Themes
+	"GET /api/v1/themes/:id":          {readSetting},
+	"POST /api/v1/themes":             {updateSetting},
+	"PUT /api/v1/themes/:id":          {updateSetting},
+	"DELETE /api/v1/themes/:id":       {updateSetting},
+	"GET /api/v1/themes/deleted":      {updateSetting},
+	"POST /api/v1/themes/:id/restore": {updateSetting},
+
+	// System logs
+	"GET /api/v1/logs": {middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceLog)},
+}
+
+// routeKey identifies a route in publicRoutes and routePermissions
+func routeKey(method, path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return method + " " + path
+}
+
+// guardedRouter registers protected routes behind the permission check declared for
+// them in routePermissions, and records which routes it guarded for checkRoutePermissions
+type guardedRouter struct {
+	router  fiber.Router
+	prefix  string
+	guarded map[string]bool
+}
+
+func newGuardedRouter(router fiber.Router, prefix string) *guardedRouter {
+	return &guardedRouter{router: router, prefix: prefix, guarded: make(map[string]bool)}
+}
+
+// Group returns a router for a sub path that shares the guarded route bookkeeping
+func (g *guardedRouter) Group(prefix string) *guardedRouter {
+	return &guardedRouter{router: g.router.Group(prefix), prefix: g.prefix + prefix, guarded: g.guarded}
+}
+
+func (g *guardedRouter) Get(path string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodGet, path, handlers)
+}
+
+func (g *guardedRouter) Post(path string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodPost, path, handlers)
+}
+
+func (g *guardedRouter) Put(path string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodPut, path, handlers)
+}
+
+func (g *guardedRouter) Delete(path string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodDelete, path, handlers)
+}
+
+func (g *guardedRouter) add(method, path string, handlers []fiber.Handler) {
+	key := routeKey(method, g.prefix+path)
+	if authenticatedRoutes[key] {
+		g.guarded[key] = true
+		g.router.Add(method, path, handlers...)
+		return
+	}
+
+	permissions, ok := routePermissions[key]
+	if !ok || len(permissions) == 0 {
+		// Registered unguarded so checkRoutePermissions reports it at startup
+		g.router.Add(method, path, handlers...)
+		return
+	}
+
+	g.guarded[key] = true
+	g.router.Add(method, path, append([]fiber.Handler{middleware.RequireAnyPermission(permissions...)}, handlers...)...)
+}
+
+// checkRoutePermissions fails if an API route is neither public nor guarded by a
+// permission, so a new route cannot ship open to every authenticated user
+func checkRoutePermissions(app *fiber.App, guarded map[string]bool) error {
+	var unguarded []string
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, apiPrefix) {
+			continue
+		}
+		key := routeKey(route.Method, route.Path)
+		if !publicRoutes[key] && !guarded[key] {
+			unguarded = append(unguarded, key)
+		}
+	}
+
+	if len(unguarded) > 0 {
+		sort.Strings(unguarded)
+		return fmt.Errorf("routes without a permission requirement: %s", strings.Join(unguarded, ", "))
+	}
+
+	// A declared route that was never registered is usually a typo in the tables
+	var unknown []string
+	for key := range routePermissions {
+		if !guarded[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	for key := range authenticatedRoutes {
+		if !guarded[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("permissions declared for unregistered routes: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
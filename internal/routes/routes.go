@@ -6,7 +6,6 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/latoulicious/siresto-backend/internal/config"
-	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/handler"
 	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/repository"
@@ -24,6 +23,18 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 
 	//* Menu domain
 
+	// Public menu cache, invalidated by every menu mutation
+	menuCache := &service.MenuCache{}
+
+	// Restaurant timezone for menu availability schedules
+	restaurantLocation, err := config.NewRestaurantLocationFromEnv()
+	if err != nil {
+		logger.LogError("Failed to load restaurant timezone, falling back to UTC", logutil.MainCall("init", "timezone", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		restaurantLocation = time.UTC
+	}
+
 	// QR Code domain
 	qrRepo := &repository.QRCodeRepository{DB: db}
 	qrService := &service.QRCodeService{Repo: qrRepo}
@@ -31,24 +42,26 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 
 	// Category domain
 	categoryRepo := &repository.CategoryRepository{DB: db}
-	categoryService := &service.CategoryService{Repo: categoryRepo}
+	categoryService := &service.CategoryService{
+		Repo:      categoryRepo,
+		MenuCache: menuCache,
+	}
 	categoryHandler := &handler.CategoryHandler{Service: categoryService}
 
-	// Initialize R2 Uploader
-	var r2Uploader *utils.R2Uploader
-	r2Uploader, err := config.NewR2UploaderFromEnv()
+	// Initialize file storage
+	uploader, err := config.NewUploaderFromEnv()
 	if err != nil {
-		logger.LogError("Failed to initialize R2 uploader", logutil.MainCall("init", "r2uploader", map[string]interface{}{
+		logger.LogError("Failed to initialize file storage", logutil.MainCall("init", "uploader", map[string]interface{}{
 			"error": err.Error(),
 		}))
 		logger.LogInfo("Products can be created but image upload functionality will be disabled",
-			logutil.MainCall("init", "r2uploader", map[string]interface{}{
-				"suggestion": "Check your R2 configuration in .env file",
+			logutil.MainCall("init", "uploader", map[string]interface{}{
+				"suggestion": "Check STORAGE_DRIVER and the storage configuration in .env file",
 			}))
-		// r2Uploader will remain nil, which is fine
+		// uploader will remain nil, which is fine
 	} else {
-		logger.LogInfo("R2 uploader initialized successfully",
-			logutil.MainCall("init", "r2uploader", map[string]interface{}{
+		logger.LogInfo("File storage initialized successfully",
+			logutil.MainCall("init", "uploader", map[string]interface{}{
 				"status": "ready",
 			}))
 	}
@@ -56,47 +69,177 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	// Product domain
 	productRepo := &repository.ProductRepository{DB: db}
 	productService := &service.ProductService{
-		Repo:     productRepo,
-		Uploader: r2Uploader,
+		Repo:      productRepo,
+		Uploader:  uploader,
+		MenuCache: menuCache,
 	}
 	productHandler := &handler.ProductHandler{Service: productService}
 
 	// Variation domain
 	variationRepo := &repository.VariationRepository{DB: db}
-	variationService := &service.VariationService{Repo: variationRepo}
+	variationService := &service.VariationService{
+		Repo:      variationRepo,
+		MenuCache: menuCache,
+	}
 	variationHandler := &handler.VariationHandler{
 		Service:        variationService,
 		ProductService: productService,
 	}
 
+	// Modifier domain
+	modifierRepo := &repository.ModifierRepository{DB: db}
+	modifierService := &service.ModifierService{
+		Repo:      modifierRepo,
+		MenuCache: menuCache,
+	}
+	modifierHandler := &handler.ModifierHandler{Service: modifierService}
+
+	// Menu import/export
+	menuService := &service.MenuService{
+		DB:           db,
+		CategoryRepo: categoryRepo,
+		Cache:        menuCache,
+		Location:     restaurantLocation,
+	}
+	menuHandler := &handler.MenuHandler{Service: menuService}
+
 	//* Core Domain
 
 	// User domain
 	validate := validator.New()
 	userRepo := &repository.UserRepository{DB: db}
-	userService := &service.UserService{Repo: userRepo}
+	sessionRepo := &repository.SessionRepository{DB: db}
+	terminalRepo := &repository.TerminalRepository{DB: db}
+	sessionService := &service.SessionService{
+		Repo:      sessionRepo,
+		UserRepo:  userRepo,
+		Terminals: terminalRepo,
+	}
+	sessionHandler := &handler.SessionHandler{
+		Service:  sessionService,
+		Validate: validate,
+	}
+	loginThrottle := &service.LoginThrottle{
+		Repo:   &repository.LoginFailureRepository{DB: db},
+		Logger: logger,
+	}
+
+	// TOTP two-factor authentication for management roles
+	twoFactorConfig, err := config.NewTwoFactorConfigFromEnv()
+	if err != nil {
+		logger.LogError("Failed to load two-factor configuration, using defaults", logutil.MainCall("init", "two_factor", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		twoFactorConfig = &config.TwoFactorConfig{Issuer: "Siresto"}
+	}
+	twoFactorService := &service.TwoFactorService{
+		Repo:     &repository.TwoFactorRepository{DB: db},
+		Users:    userRepo,
+		Roles:    &repository.RoleRepository{DB: db},
+		Throttle: loginThrottle,
+		Issuer:   twoFactorConfig.Issuer,
+		Enforced: twoFactorConfig.Enforced,
+	}
+	twoFactorHandler := &handler.TwoFactorHandler{
+		Service:  twoFactorService,
+		Validate: validate,
+	}
+
+	userService := &service.UserService{
+		Repo:      userRepo,
+		Sessions:  sessionService,
+		Throttle:  loginThrottle,
+		TwoFactor: twoFactorService,
+	}
 	userHandler := handler.NewUserHandler(userService, validate)
 
+	// Password changes and reset links, delivered through the log until a mail provider is configured
+	passwordResetConfig, err := config.NewPasswordResetConfigFromEnv()
+	if err != nil {
+		logger.LogError("Failed to load password reset configuration, using defaults", logutil.MainCall("init", "password_reset", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		passwordResetConfig = &config.PasswordResetConfig{TTL: time.Hour}
+	}
+	passwordService := &service.PasswordService{
+		Users:    userRepo,
+		Resets:   &repository.PasswordResetRepository{DB: db},
+		Sessions: sessionService,
+		Throttle: loginThrottle,
+		Notifier: &service.ConsoleNotifier{Logger: logger},
+		ResetURL: passwordResetConfig.URL,
+		ResetTTL: passwordResetConfig.TTL,
+	}
+	userService.Passwords = passwordService
+	passwordHandler := &handler.PasswordHandler{
+		Service:  passwordService,
+		Validate: validate,
+	}
+	terminalHandler := &handler.TerminalHandler{
+		Service: &service.TerminalService{
+			Repo:     terminalRepo,
+			UserRepo: userRepo,
+			Throttle: loginThrottle,
+		},
+		Validate: validate,
+	}
+
+	// Protected rejects access tokens revoked at logout or by a forced logout
+	middleware.SetRevocationChecker(sessionService)
+
+	// API keys for integrations, accepted by Protected in place of an access token
+	apiKeyService := &service.APIKeyService{
+		Repo:  &repository.APIKeyRepository{DB: db},
+		Users: userRepo,
+	}
+	apiKeyHandler := &handler.APIKeyHandler{
+		Service:  apiKeyService,
+		Validate: validate,
+	}
+	middleware.SetAPIKeyAuthenticator(apiKeyService)
+
+	// Role permissions are resolved per request from a cache kept in sync by the role and permission services
+	roleRepo := &repository.RoleRepository{DB: db}
+	permissionResolver := &service.PermissionResolver{
+		Repo: roleRepo,
+		TTL:  time.Minute,
+	}
+	middleware.SetPermissionResolver(permissionResolver)
+
 	// Permission domain
 	permissionRepo := &repository.PermissionRepository{DB: db}
-	permissionService := &service.PermissionService{Repo: permissionRepo}
+	permissionService := &service.PermissionService{
+		Repo:        permissionRepo,
+		Permissions: permissionResolver,
+	}
 	permissionHandler := &handler.PermissionHandler{Service: permissionService}
 
 	// Role domain
-	roleRepo := &repository.RoleRepository{DB: db}
 	roleService := &service.RoleService{
 		Repo:              roleRepo,
 		PermissionService: permissionService,
 		DB:                db,
+		Permissions:       permissionResolver,
 	}
 	roleHandler := &handler.RoleHandler{Service: roleService}
 
+	// Current user introspection for frontends
+	meService := &service.MeService{
+		Users:          userService,
+		PermissionRepo: permissionRepo,
+	}
+	meHandler := &handler.MeHandler{
+		Service:  meService,
+		Validate: validate,
+	}
+
 	// Order domain
 	orderRepo := &repository.OrderRepository{DB: db}
 	orderService := &service.OrderService{
 		Repo:          orderRepo,
 		ProductRepo:   productRepo,
 		VariationRepo: variationRepo,
+		Location:      restaurantLocation,
 	}
 	orderHandler := &handler.OrderHandler{OrderService: orderService}
 
@@ -112,6 +255,29 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	themeService := &service.ThemeService{Repo: themeRepo}
 	themeHandler := &handler.ThemeHandler{Service: themeService}
 
+	// Direct-to-storage uploads
+	uploadService := &service.UploadService{
+		Uploader:       uploader,
+		ProductService: productService,
+		ThemeService:   themeService,
+	}
+	uploadHandler := &handler.UploadHandler{Service: uploadService}
+
+	// Log viewer and the audit trail of changes to orders, payments, products, roles and users
+	logRepo := &repository.LogRepository{DB: db}
+	logService := &service.LogService{Repo: logRepo}
+	logHandler := &handler.LogHandler{Service: logService}
+
+	middleware.SetAuditRecorder(&service.AuditService{
+		Repo:     logRepo,
+		Orders:   orderRepo,
+		Payments: paymentRepo,
+		Products: productRepo,
+		Roles:    roleRepo,
+		Users:    userRepo,
+		Logger:   logger,
+	})
+
 	// API v1
 	v1 := app.Group("/api/v1")
 
@@ -155,71 +321,189 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	})
 	logger.LogInfo("GET /health route registered", logutil.Route("GET", "/health"))
 
+	// Files stored on local disk are served straight from the storage directory
+	if localUploader, ok := uploader.(*utils.LocalUploader); ok {
+		app.Static(localUploader.RoutePrefix(), localUploader.Dir, fiber.Static{
+			MaxAge: 86400,
+		})
+		logger.LogInfo("GET "+localUploader.RoutePrefix()+" static route registered", logutil.Route("GET", localUploader.RoutePrefix()))
+	}
+
+	// Public signing keys, so other services can verify our tokens
+	app.Get("/.well-known/jwks.json", handler.GetJWKS)
+	logger.LogInfo("GET /.well-known/jwks.json route registered", logutil.Route("GET", "/.well-known/jwks.json"))
+
 	// Auth routes (public)
 	v1.Post("/auth/login", userHandler.LoginUser)
 	logger.LogInfo("POST /api/v1/auth/login route registered", logutil.Route("POST", "/api/v1/auth/login"))
 
-	// Protected routes require valid JWT
-	protected := v1.Use(middleware.Protected())
+	// Second login step for users with two-factor authentication, authenticated by the two-factor token
+	v1.Post("/auth/2fa/verify", userHandler.VerifyTwoFactor)
+	logger.LogInfo("POST /api/v1/auth/2fa/verify route registered", logutil.Route("POST", "/api/v1/auth/2fa/verify"))
 
-	// ------------- User Management Routes -------------
-	// Admin-only routes (System, Owner, Admin roles)
-	adminRoutes := protected.Use(middleware.RequireManagement())
+	v1.Post("/auth/2fa/enroll", userHandler.BeginTwoFactorEnrollment)
+	logger.LogInfo("POST /api/v1/auth/2fa/enroll route registered", logutil.Route("POST", "/api/v1/auth/2fa/enroll"))
+
+	v1.Post("/auth/2fa/enroll/confirm", userHandler.CompleteTwoFactorEnrollment)
+	logger.LogInfo("POST /api/v1/auth/2fa/enroll/confirm route registered", logutil.Route("POST", "/api/v1/auth/2fa/enroll/confirm"))
+
+	v1.Post("/auth/refresh", sessionHandler.RefreshToken)
+	logger.LogInfo("POST /api/v1/auth/refresh route registered", logutil.Route("POST", "/api/v1/auth/refresh"))
+
+	v1.Post("/auth/logout", sessionHandler.Logout)
+	logger.LogInfo("POST /api/v1/auth/logout route registered", logutil.Route("POST", "/api/v1/auth/logout"))
+
+	v1.Post("/auth/password-reset", middleware.AuditWith(middleware.AuditUser, "password_reset", passwordHandler.ResetUserID), passwordHandler.ResetPassword)
+	logger.LogInfo("POST /api/v1/auth/password-reset route registered", logutil.Route("POST", "/api/v1/auth/password-reset"))
+
+	// PIN login on shared staff terminals, authenticated by the X-Terminal-Key header
+	v1.Post("/auth/pin-login", terminalHandler.PinLogin)
+	logger.LogInfo("POST /api/v1/auth/pin-login route registered", logutil.Route("POST", "/api/v1/auth/pin-login"))
+
+	v1.Get("/terminal/staff", terminalHandler.ListStaff)
+	logger.LogInfo("GET /api/v1/terminal/staff route registered", logutil.Route("GET", "/api/v1/terminal/staff"))
+
+	// Public menu, registered before the JWT middleware so it stays reachable anonymously
+	v1.Get("/menu", menuHandler.GetPublicMenu)
+	logger.LogInfo("GET /api/v1/menu route registered (public)", logutil.Route("GET", "/api/v1/menu"))
 
-	// Admin-only dashboard route
-	adminRoutes.Get("/admin/dashboard", func(c *fiber.Ctx) error {
+	// Public category listing, ordering from the table QR code and themes
+	v1.Get("/categories", categoryHandler.ListAllCategories)
+	logger.LogInfo("GET /api/v1/categories route registered (public)", logutil.Route("GET", "/api/v1/categories"))
+
+	v1.Post("/orders", middleware.Audit(middleware.AuditOrder, "created", ""), orderHandler.CreateOrder)
+	logger.LogInfo("POST /api/v1/orders route registered (public)", logutil.Route("POST", "/api/v1/orders"))
+
+	v1.Get("/themes", themeHandler.ListAllThemes)
+	logger.LogInfo("GET /api/v1/themes route registered (public)", logutil.Route("GET", "/api/v1/themes"))
+
+	// Protected routes require valid JWT and the permission declared for them in routePermissions
+	protected := newGuardedRouter(v1.Use(middleware.Protected()), apiPrefix)
+
+	// Current user, available to every signed-in user
+	protected.Get("/me", meHandler.GetMe)
+	logger.LogInfo("GET /api/v1/me route registered", logutil.Route("GET", "/api/v1/me"))
+
+	protected.Post("/me/can", meHandler.Can)
+	logger.LogInfo("POST /api/v1/me/can route registered", logutil.Route("POST", "/api/v1/me/can"))
+
+	// Credentials can only be changed from an interactive session, never with an API key
+	protected.Post("/me/password", middleware.RejectAPIKeys(), middleware.AuditWith(middleware.AuditUser, "password_changed", middleware.GetUserID), passwordHandler.ChangePassword)
+	logger.LogInfo("POST /api/v1/me/password route registered", logutil.Route("POST", "/api/v1/me/password"))
+
+	// Two-factor authentication of the current user
+	protected.Get("/me/2fa", middleware.RejectAPIKeys(), twoFactorHandler.GetStatus)
+	logger.LogInfo("GET /api/v1/me/2fa route registered", logutil.Route("GET", "/api/v1/me/2fa"))
+
+	protected.Post("/me/2fa/enroll", middleware.RejectAPIKeys(), twoFactorHandler.BeginEnrollment)
+	logger.LogInfo("POST /api/v1/me/2fa/enroll route registered", logutil.Route("POST", "/api/v1/me/2fa/enroll"))
+
+	protected.Post("/me/2fa/confirm", middleware.RejectAPIKeys(), twoFactorHandler.ConfirmEnrollment)
+	logger.LogInfo("POST /api/v1/me/2fa/confirm route registered", logutil.Route("POST", "/api/v1/me/2fa/confirm"))
+
+	protected.Post("/me/2fa/recovery-codes", middleware.RejectAPIKeys(), twoFactorHandler.RegenerateRecoveryCodes)
+	logger.LogInfo("POST /api/v1/me/2fa/recovery-codes route registered", logutil.Route("POST", "/api/v1/me/2fa/recovery-codes"))
+
+	protected.Post("/me/2fa/disable", middleware.RejectAPIKeys(), twoFactorHandler.Disable)
+	logger.LogInfo("POST /api/v1/me/2fa/disable route registered", logutil.Route("POST", "/api/v1/me/2fa/disable"))
+
+	// ------------- User Management Routes -------------
+	// Admin dashboard route
+	protected.Get("/admin/dashboard", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(utils.Success("Admin dashboard data", nil))
 	})
 	logger.LogInfo("GET /api/v1/admin/dashboard route registered", logutil.Route("GET", "/api/v1/admin/dashboard"))
 
-	// User management - requires either admin role or specific permissions
+	// User management
 	userManagement := protected.Group("/users")
 
-	// List users - requires admin role or read:user permission
-	userManagement.Get("/", middleware.RequireResourcePermission(middleware.PermissionRead, middleware.ResourceUser),
-		userHandler.ListAllUsers)
+	userManagement.Get("/", userHandler.ListAllUsers)
 	logger.LogInfo("GET /api/v1/users route registered", logutil.Route("GET", "/api/v1/users"))
 
-	// Create user - requires admin role or create:user permission
-	userManagement.Post("/", middleware.RequireResourcePermission(middleware.PermissionCreate, middleware.ResourceUser),
-		userHandler.CreateUser)
+	userManagement.Post("/", middleware.Audit(middleware.AuditUser, "created", ""), userHandler.CreateUser)
 	logger.LogInfo("POST /api/v1/users route registered", logutil.Route("POST", "/api/v1/users"))
 
-	// Update user - requires admin role, being the same user, or update:user permission
-	userManagement.Put("/:id", middleware.RequireAnyPermission(
-		middleware.FormatPermission(middleware.PermissionUpdate, middleware.ResourceUser)),
-		middleware.IsSameUserOrHigherRole(),
-		userHandler.UpdateUser)
+	// Updating another user additionally requires manage:users
+	userManagement.Put("/:id", middleware.IsSameUserOrHigherRole(), middleware.Audit(middleware.AuditUser, "updated", "id"), userHandler.UpdateUser)
 	logger.LogInfo("PUT /api/v1/users/:id route registered", logutil.Route("PUT", "/api/v1/users/:id"))
 
-	// Delete user - requires admin role or delete:user permission
-	userManagement.Delete("/:id", middleware.RequireResourcePermission(middleware.PermissionDelete, middleware.ResourceUser),
-		userHandler.DeleteUser)
+	userManagement.Delete("/:id", middleware.Audit(middleware.AuditUser, "deleted", "id"), userHandler.DeleteUser)
 	logger.LogInfo("DELETE /api/v1/users/:id route registered", logutil.Route("DELETE", "/api/v1/users/:id"))
 
+	// Deleted users stay restorable
+	userManagement.Get("/deleted", userHandler.ListDeletedUsers)
+	logger.LogInfo("GET /api/v1/users/deleted route registered", logutil.Route("GET", "/api/v1/users/deleted"))
+
+	userManagement.Post("/:id/restore", middleware.Audit(middleware.AuditUser, "restored", "id"), userHandler.RestoreUser)
+	logger.LogInfo("POST /api/v1/users/:id/restore route registered", logutil.Route("POST", "/api/v1/users/:id/restore"))
+
+	// Lift a login lockout before it expires
+	userManagement.Post("/:id/unlock", middleware.Audit(middleware.AuditUser, "unlocked", "id"), userHandler.UnlockUser)
+	logger.LogInfo("POST /api/v1/users/:id/unlock route registered", logutil.Route("POST", "/api/v1/users/:id/unlock"))
+
+	// Send the user a single-use link to choose a new password
+	userManagement.Post("/:id/password-reset", middleware.Audit(middleware.AuditUser, "password_reset_requested", "id"), passwordHandler.RequestPasswordReset)
+	logger.LogInfo("POST /api/v1/users/:id/password-reset route registered", logutil.Route("POST", "/api/v1/users/:id/password-reset"))
+
+	// Remove two-factor authentication from a user who lost their authenticator
+	userManagement.Delete("/:id/2fa", middleware.Audit(middleware.AuditUser, "two_factor_reset", "id"), twoFactorHandler.Reset)
+	logger.LogInfo("DELETE /api/v1/users/:id/2fa route registered", logutil.Route("DELETE", "/api/v1/users/:id/2fa"))
+
+	// Setting another user's PIN additionally requires manage:users
+	userManagement.Put("/:id/pin", middleware.IsSameUserOrHigherRole(), middleware.Audit(middleware.AuditUser, "pin_set", "id"), userHandler.SetUserPin)
+	logger.LogInfo("PUT /api/v1/users/:id/pin route registered", logutil.Route("PUT", "/api/v1/users/:id/pin"))
+
+	// Shared staff terminals
+	protected.Post("/terminals", terminalHandler.RegisterTerminal)
+	logger.LogInfo("POST /api/v1/terminals route registered", logutil.Route("POST", "/api/v1/terminals"))
+
+	protected.Get("/terminals", terminalHandler.ListTerminals)
+	logger.LogInfo("GET /api/v1/terminals route registered", logutil.Route("GET", "/api/v1/terminals"))
+
+	protected.Delete("/terminals/:id", terminalHandler.RevokeTerminal)
+	logger.LogInfo("DELETE /api/v1/terminals/:id route registered", logutil.Route("DELETE", "/api/v1/terminals/:id"))
+
+	// API keys for machine clients; a key cannot issue further keys
+	protected.Post("/api-keys", middleware.RejectAPIKeys(), apiKeyHandler.CreateAPIKey)
+	logger.LogInfo("POST /api/v1/api-keys route registered", logutil.Route("POST", "/api/v1/api-keys"))
+
+	protected.Get("/api-keys", apiKeyHandler.ListAPIKeys)
+	logger.LogInfo("GET /api/v1/api-keys route registered", logutil.Route("GET", "/api/v1/api-keys"))
+
+	protected.Delete("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+	logger.LogInfo("DELETE /api/v1/api-keys/:id route registered", logutil.Route("DELETE", "/api/v1/api-keys/:id"))
+
+	// Keys of every user, for administrators
+	protected.Get("/admin/api-keys", apiKeyHandler.ListAllAPIKeys)
+	logger.LogInfo("GET /api/v1/admin/api-keys route registered", logutil.Route("GET", "/api/v1/admin/api-keys"))
+
+	protected.Delete("/admin/api-keys/:id", apiKeyHandler.RevokeAnyAPIKey)
+	logger.LogInfo("DELETE /api/v1/admin/api-keys/:id route registered", logutil.Route("DELETE", "/api/v1/admin/api-keys/:id"))
+
 	// ------------- Role Management Routes -------------
-	// Role management - only available to System and Owner roles
+	// Role management - requires the manage:roles permission
 	roleManagement := protected.Group("/roles")
-	roleManagement.Use(middleware.RequireOneOfRoles(middleware.RoleSystem, middleware.RoleOwner))
 
 	// Role CRUD operations
 	roleManagement.Get("/", roleHandler.ListAllRoles)
+	roleManagement.Get("/deleted", roleHandler.ListDeletedRoles)
 	roleManagement.Get("/:id", roleHandler.GetRoleByID)
-	roleManagement.Post("/", roleHandler.CreateRole)
-	roleManagement.Put("/:id", roleHandler.UpdateRole)
-	roleManagement.Delete("/:id", roleHandler.DeleteRole)
+	roleManagement.Post("/", middleware.Audit(middleware.AuditRole, "created", ""), roleHandler.CreateRole)
+	roleManagement.Put("/:id", middleware.Audit(middleware.AuditRole, "updated", "id"), roleHandler.UpdateRole)
+	roleManagement.Delete("/:id", middleware.Audit(middleware.AuditRole, "deleted", "id"), roleHandler.DeleteRole)
+	roleManagement.Post("/:id/restore", middleware.Audit(middleware.AuditRole, "restored", "id"), roleHandler.RestoreRole)
 
 	// Special role endpoints
-	roleManagement.Post("/comprehensive", roleHandler.CreateComprehensiveRole)
+	roleManagement.Post("/comprehensive", middleware.Audit(middleware.AuditRole, "created", ""), roleHandler.CreateComprehensiveRole)
 	logger.LogInfo("POST /api/v1/roles/comprehensive route registered", logutil.Route("POST", "/api/v1/roles/comprehensive"))
 
-	// Permission management for roles - only System and Owner can modify permissions
-	roleManagement.Post("/:id/permissions", roleHandler.AddPermissionsToRole)
-	roleManagement.Delete("/:id/permissions", roleHandler.RemovePermissionsFromRole)
+	// Permission management for roles
+	roleManagement.Post("/:id/permissions", middleware.Audit(middleware.AuditRole, "permissions_added", "id"), roleHandler.AddPermissionsToRole)
+	roleManagement.Delete("/:id/permissions", middleware.Audit(middleware.AuditRole, "permissions_removed", "id"), roleHandler.RemovePermissionsFromRole)
 
 	// ------------- Permission Management Routes -------------
+	// Permission management - requires the manage:permissions permission
 	permissionManagement := protected.Group("/permissions")
-	permissionManagement.Use(middleware.RequireOneOfRoles(middleware.RoleSystem, middleware.RoleOwner))
 
 	// Permission CRUD operations
 	permissionManagement.Get("/", permissionHandler.ListAllPermissions)
@@ -251,11 +535,10 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	protected.Delete("/qr-codes/:id", qrHandler.DeleteQRCodeHandler)
 	logger.LogInfo("DELETE /api/v1/qr-codes/:id route registered", logutil.Route("DELETE", "/api/v1/qr-codes/:id"))
 
-	// Category routes - Public access for listing
-	v1.Get("/categories", categoryHandler.ListAllCategories)
-	logger.LogInfo("GET /api/v1/categories route registered (public)", logutil.Route("GET", "/api/v1/categories"))
+	// Category routes
+	protected.Get("/categories/deleted", categoryHandler.ListDeletedCategories)
+	logger.LogInfo("GET /api/v1/categories/deleted route registered", logutil.Route("GET", "/api/v1/categories/deleted"))
 
-	// Protected Category routes
 	protected.Get("/categories/:id", categoryHandler.GetCategoryByID)
 	logger.LogInfo("GET /api/v1/categories/:id route registered", logutil.Route("GET", "/api/v1/categories/:id"))
 
@@ -268,26 +551,45 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	protected.Delete("/categories/:id", categoryHandler.DeleteCategory)
 	logger.LogInfo("DELETE /api/v1/categories/:id route registered", logutil.Route("DELETE", "/api/v1/categories/:id"))
 
+	protected.Post("/categories/:id/restore", categoryHandler.RestoreCategory)
+	logger.LogInfo("POST /api/v1/categories/:id/restore route registered", logutil.Route("POST", "/api/v1/categories/:id/restore"))
+
 	// Product routes
 	protected.Get("/products", productHandler.ListAllProducts)
 	logger.LogInfo("GET /api/v1/products route registered", logutil.Route("GET", "/api/v1/products"))
 
+	protected.Get("/products/deleted", productHandler.ListDeletedProducts)
+	logger.LogInfo("GET /api/v1/products/deleted route registered", logutil.Route("GET", "/api/v1/products/deleted"))
+
 	protected.Get("/products/:id", productHandler.GetProductByID)
 	logger.LogInfo("GET /api/v1/products/:id route registered", logutil.Route("GET", "/api/v1/products/:id"))
 
-	protected.Post("/products", productHandler.CreateProduct)
+	protected.Post("/products", middleware.Audit(middleware.AuditProduct, "created", ""), productHandler.CreateProduct)
 	logger.LogInfo("POST /api/v1/products/with-image route registered", logutil.Route("POST", "/api/v1/products/with-image"))
 
-	protected.Put("/products/:id", productHandler.UpdateProduct)
+	protected.Put("/products/:id", middleware.Audit(middleware.AuditProduct, "updated", "id"), productHandler.UpdateProduct)
 	logger.LogInfo("PUT /api/v1/products/:id route registered", logutil.Route("PUT", "/api/v1/products/:id"))
 
-	protected.Delete("/products/:id", productHandler.DeleteProduct)
+	protected.Delete("/products/:id", middleware.Audit(middleware.AuditProduct, "deleted", "id"), productHandler.DeleteProduct)
 	logger.LogInfo("DELETE /api/v1/products/:id route registered", logutil.Route("DELETE", "/api/v1/products/:id"))
 
+	protected.Post("/products/:id/restore", middleware.Audit(middleware.AuditProduct, "restored", "id"), productHandler.RestoreProduct)
+	logger.LogInfo("POST /api/v1/products/:id/restore route registered", logutil.Route("POST", "/api/v1/products/:id/restore"))
+
+	// Presigned uploads, so image bytes go straight to storage instead of through the API
+	protected.Post("/uploads/presign", uploadHandler.PresignUpload)
+	logger.LogInfo("POST /api/v1/uploads/presign route registered", logutil.Route("POST", "/api/v1/uploads/presign"))
+
+	protected.Post("/uploads/confirm", middleware.AuditWith(middleware.AuditProduct, "image_confirmed", handler.ConfirmedProductID), uploadHandler.ConfirmUpload)
+	logger.LogInfo("POST /api/v1/uploads/confirm route registered", logutil.Route("POST", "/api/v1/uploads/confirm"))
+
 	// Variation Routes (Not tied to a specific product)
 	protected.Get("/variations", variationHandler.ListAllVariations)
 	logger.LogInfo("GET /api/v1/variations route registered", logutil.Route("GET", "/api/v1/variations"))
 
+	protected.Get("/variations/deleted", variationHandler.ListDeletedVariations)
+	logger.LogInfo("GET /api/v1/variations/deleted route registered", logutil.Route("GET", "/api/v1/variations/deleted"))
+
 	protected.Get("/variations/:id", variationHandler.GetVariationByID)
 	logger.LogInfo("GET /api/v1/variations/:id route registered", logutil.Route("GET", "/api/v1/variations/:id"))
 
@@ -300,37 +602,57 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	protected.Delete("/variations/:id", variationHandler.DeleteVariation)
 	logger.LogInfo("DELETE /api/v1/variations/:id route registered", logutil.Route("DELETE", "/api/v1/variations/:id"))
 
+	protected.Post("/variations/:id/restore", variationHandler.RestoreVariation)
+	logger.LogInfo("POST /api/v1/variations/:id/restore route registered", logutil.Route("POST", "/api/v1/variations/:id/restore"))
+
 	// Variation Routes (Tied to a specific product)
 	protected.Get("/products/:product_id/variations", variationHandler.GetProductVariations)
 	logger.LogInfo("GET /api/v1/products/:product_id/variations route registered", logutil.Route("GET", "/api/v1/products/:product_id/variations"))
 
-	protected.Post("/products/:product_id/variations", variationHandler.CreateProductVariation)
+	protected.Post("/products/:product_id/variations", middleware.Audit(middleware.AuditProduct, "variation_created", "product_id"), variationHandler.CreateProductVariation)
 	logger.LogInfo("POST /api/v1/products/:product_id/variations route registered", logutil.Route("POST", "/api/v1/products/:product_id/variations"))
 
-	protected.Put("/products/:product_id/variations/:id", variationHandler.UpdateProductVariation)
+	protected.Put("/products/:product_id/variations/:id", middleware.Audit(middleware.AuditProduct, "variation_updated", "product_id"), variationHandler.UpdateProductVariation)
 	logger.LogInfo("PUT /api/v1/products/:product_id/variations/:id route registered", logutil.Route("PUT", "/api/v1/products/:product_id/variations/:id"))
 
-	protected.Delete("/products/:product_id/variations/:id", variationHandler.DeleteProductVariation)
+	protected.Delete("/products/:product_id/variations/:id", middleware.Audit(middleware.AuditProduct, "variation_deleted", "product_id"), variationHandler.DeleteProductVariation)
 	logger.LogInfo("DELETE /api/v1/products/:product_id/variations/:id route registered", logutil.Route("DELETE", "/api/v1/products/:product_id/variations/:id"))
 
-	// Order routes
-	v1.Post("/orders", orderHandler.CreateOrder)
-	logger.LogInfo("POST /api/v1/orders route registered (public)", logutil.Route("POST", "/api/v1/orders"))
+	// Modifier group routes (Tied to a specific product)
+	protected.Get("/products/:product_id/modifier-groups", modifierHandler.GetProductModifierGroups)
+	logger.LogInfo("GET /api/v1/products/:product_id/modifier-groups route registered", logutil.Route("GET", "/api/v1/products/:product_id/modifier-groups"))
+
+	protected.Post("/products/:product_id/modifier-groups", middleware.Audit(middleware.AuditProduct, "modifier_group_created", "product_id"), modifierHandler.CreateProductModifierGroup)
+	logger.LogInfo("POST /api/v1/products/:product_id/modifier-groups route registered", logutil.Route("POST", "/api/v1/products/:product_id/modifier-groups"))
+
+	protected.Put("/products/:product_id/modifier-groups/:id", middleware.Audit(middleware.AuditProduct, "modifier_group_updated", "product_id"), modifierHandler.UpdateProductModifierGroup)
+	logger.LogInfo("PUT /api/v1/products/:product_id/modifier-groups/:id route registered", logutil.Route("PUT", "/api/v1/products/:product_id/modifier-groups/:id"))
 
+	protected.Delete("/products/:product_id/modifier-groups/:id", middleware.Audit(middleware.AuditProduct, "modifier_group_deleted", "product_id"), modifierHandler.DeleteProductModifierGroup)
+	logger.LogInfo("DELETE /api/v1/products/:product_id/modifier-groups/:id route registered", logutil.Route("DELETE", "/api/v1/products/:product_id/modifier-groups/:id"))
+
+	// Menu import/export routes
+	protected.Post("/menu/import", menuHandler.ImportMenu)
+	logger.LogInfo("POST /api/v1/menu/import route registered", logutil.Route("POST", "/api/v1/menu/import"))
+
+	protected.Get("/menu/export", menuHandler.ExportMenu)
+	logger.LogInfo("GET /api/v1/menu/export route registered", logutil.Route("GET", "/api/v1/menu/export"))
+
+	// Order routes
 	protected.Get("/orders", orderHandler.ListAllOrders)
 	logger.LogInfo("GET /api/v1/orders route registered", logutil.Route("GET", "/api/v1/orders"))
 
 	protected.Get("/orders/:id", orderHandler.GetOrderByID)
 	logger.LogInfo("GET /api/v1/orders/:id route registered", logutil.Route("GET", "/api/v1/orders/:id"))
 
-	protected.Put("/orders/:id", orderHandler.UpdateOrder)
+	protected.Put("/orders/:id", middleware.Audit(middleware.AuditOrder, "updated", "id"), orderHandler.UpdateOrder)
 	logger.LogInfo("PUT /api/v1/orders/:id route registered", logutil.Route("PUT", "/api/v1/orders/:id"))
 
 	// Order Status
-	protected.Post("/orders/:orderID/complete", orderHandler.MarkOrderAsCompleted)
+	protected.Post("/orders/:orderID/complete", middleware.Audit(middleware.AuditOrder, "completed", "orderID"), orderHandler.MarkOrderAsCompleted)
 	logger.LogInfo("POST /api/v1/orders/:orderID/complete route registered", logutil.Route("POST", "/api/v1/orders/:orderID/complete"))
 
-	protected.Post("/orders/:orderID/cancel", orderHandler.MarkOrderAsCanceled)
+	protected.Post("/orders/:orderID/cancel", middleware.Audit(middleware.AuditOrder, "canceled", "orderID"), orderHandler.MarkOrderAsCanceled)
 	logger.LogInfo("POST /api/v1/orders/:orderID/cancel route registered", logutil.Route("POST", "/api/v1/orders/:orderID/cancel"))
 
 	// Order Payment
@@ -340,12 +662,12 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	protected.Get("/orders/:orderID/payments", paymentHandler.GetOrderPayments)
 	logger.LogInfo("GET /api/v1/orders/:orderID/payments route registered", logutil.Route("GET", "/api/v1/orders/:orderID/payments"))
 
-	protected.Post("/orders/:orderID/payments", paymentHandler.ProcessOrderPayment)
+	protected.Post("/orders/:orderID/payments", middleware.Audit(middleware.AuditPayment, "created", ""), paymentHandler.ProcessOrderPayment)
 	logger.LogInfo("POST /api/v1/orders/:orderID/payments route registered", logutil.Route("POST", "/api/v1/orders/:orderID/payments"))
 
 	// Utility routes
-	v1.Get("/themes", themeHandler.ListAllThemes)
-	logger.LogInfo("GET /api/v1/themes route registered (public)", logutil.Route("GET", "/api/v1/themes"))
+	protected.Get("/themes/deleted", themeHandler.ListDeletedThemes)
+	logger.LogInfo("GET /api/v1/themes/deleted route registered", logutil.Route("GET", "/api/v1/themes/deleted"))
 
 	protected.Get("/themes/:id", themeHandler.GetThemeByID)
 	logger.LogInfo("GET /api/v1/themes/:id route registered", logutil.Route("GET", "/api/v1/themes/:id"))
@@ -359,15 +681,18 @@ func SetupRoutes(app *fiber.App, db *gorm.DB, logger logging.Logger) {
 	protected.Delete("/themes/:id", themeHandler.DeleteTheme)
 	logger.LogInfo("DELETE /api/v1/themes/:id route registered", logutil.Route("DELETE", "/api/v1/themes/:id"))
 
-	// Log routes
-	protected.Get("/logs", func(c *fiber.Ctx) error {
-		var logs []domain.Log
-		if err := db.Find(&logs).Error; err != nil {
-			logger.LogError("Failed to fetch logs", logutil.Route("GET", "/api/v1/logs"))
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.Error("Failed to retrieve Logs", fiber.StatusInternalServerError))
-		}
+	protected.Post("/themes/:id/restore", themeHandler.RestoreTheme)
+	logger.LogInfo("POST /api/v1/themes/:id/restore route registered", logutil.Route("POST", "/api/v1/themes/:id/restore"))
 
-		return c.Status(fiber.StatusOK).JSON(utils.Success("Logs fetched successfully", logs))
-	})
+	// Log routes
+	protected.Get("/logs", logHandler.ListLogs)
 	logger.LogInfo("GET /api/v1/logs route registered", logutil.Route("GET", "/api/v1/logs"))
+
+	// Refuse to start with a protected route that any authenticated user could call
+	if err := checkRoutePermissions(app, protected.guarded); err != nil {
+		logger.LogError("Route permission check failed", logutil.MainCall("init", "rbac", map[string]interface{}{
+			"error": err.Error(),
+		}))
+		panic("Route permission check failed: " + err.Error())
+	}
 }
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrInvalidAPIKey       = errors.New("invalid, expired or revoked API key")
+	ErrInvalidAPIKeyScope  = errors.New("invalid API key scope")
+	ErrInvalidAPIKeyExpiry = errors.New("API key expiry must be in the future")
+)
+
+// APIKeyService issues API keys for machine clients. A key acts for the user who created
+// it, with that user's current permissions narrowed to the key's scopes.
+type APIKeyService struct {
+	Repo  *repository.APIKeyRepository
+	Users *repository.UserRepository
+}
+
+// CreateAPIKey issues a key for the owner and returns it; only its hash is stored.
+// Every scope must be covered by granted, the owner's own permissions.
+func (s *APIKeyService) CreateAPIKey(ownerID uuid.UUID, granted []string, req *dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error) {
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if scope != middleware.PermissionFullAccess && !strings.Contains(scope, ":") {
+			return nil, fmt.Errorf("%w: %q is not a permission", ErrInvalidAPIKeyScope, scope)
+		}
+		if !middleware.HasPermission(granted, scope) {
+			return nil, fmt.Errorf("%w: you do not have %q", ErrInvalidAPIKeyScope, scope)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	prefix, key, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &domain.APIKey{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   crypto.HashToken(key),
+		UserID:    ownerID,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := s.Repo.CreateAPIKey(apiKey); err != nil {
+		return nil, err
+	}
+
+	return &dto.APIKeyCreatedResponse{
+		APIKey: mapAPIKeyToResponse(apiKey),
+		Key:    key,
+	}, nil
+}
+
+// ListAPIKeys returns the keys that act for the owner
+func (s *APIKeyService) ListAPIKeys(ownerID uuid.UUID) ([]dto.APIKeyResponse, error) {
+	keys, err := s.Repo.ListUserAPIKeys(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return mapAPIKeysToResponse(keys), nil
+}
+
+// ListAllAPIKeys returns the keys of every user, for administrators
+func (s *APIKeyService) ListAllAPIKeys() ([]dto.APIKeyResponse, error) {
+	keys, err := s.Repo.ListAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+	return mapAPIKeysToResponse(keys), nil
+}
+
+// RevokeAPIKey revokes one of the owner's keys; keys of other users are not found
+func (s *APIKeyService) RevokeAPIKey(ownerID, id uuid.UUID) error {
+	key, err := s.getAPIKey(id)
+	if err != nil {
+		return err
+	}
+	if key.UserID != ownerID {
+		return ErrAPIKeyNotFound
+	}
+	return s.Repo.RevokeAPIKey(id, time.Now())
+}
+
+// RevokeAnyAPIKey revokes a key regardless of its owner, for administrators
+func (s *APIKeyService) RevokeAnyAPIKey(id uuid.UUID) error {
+	if _, err := s.getAPIKey(id); err != nil {
+		return err
+	}
+	return s.Repo.RevokeAPIKey(id, time.Now())
+}
+
+func (s *APIKeyService) getAPIKey(id uuid.UUID) (*domain.APIKey, error) {
+	key, err := s.Repo.GetAPIKeyByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// AuthenticateAPIKey implements middleware.APIKeyAuthenticator. The owner's role is
+// looked up on every request, so role changes and deleted owners take effect at once.
+func (s *APIKeyService) AuthenticateAPIKey(key string) (*middleware.APIKeyPrincipal, error) {
+	now := time.Now()
+	apiKey, err := s.Repo.FindActiveAPIKeyByHash(crypto.HashToken(key), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	user, err := s.Users.GetUserByID(apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if user.Role == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Busy integrations use their key on every request, so a recent last use is kept
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > repository.APIKeyTouchInterval {
+		if err := s.Repo.TouchAPIKey(apiKey.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &middleware.APIKeyPrincipal{
+		KeyID:    apiKey.ID,
+		UserID:   user.ID,
+		RoleID:   user.Role.ID,
+		RoleName: user.Role.Name,
+		IsStaff:  user.IsStaff,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
+
+// generateAPIKey returns a new key and the prefix it is listed by: "srk_<id>_<secret>",
+// where the short id makes up the prefix
+func generateAPIKey() (string, string, error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	prefix := middleware.APIKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func mapAPIKeysToResponse(keys []domain.APIKey) []dto.APIKeyResponse {
+	responses := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, mapAPIKeyToResponse(&keys[i]))
+	}
+	return responses
+}
+
+func mapAPIKeyToResponse(key *domain.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		UserID:     key.UserID,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
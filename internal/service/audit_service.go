@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/core/logging"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/logutil"
+)
+
+// Level and type of audit entries in the logs table
+const LogTypeAudit = "audit"
+
+// AuditService keeps the audit trail of changes made through the API. Entries are
+// written straight to the logs table rather than through the application logger, so
+// an audit entry is stored before the response is sent.
+type AuditService struct {
+	Repo     *repository.LogRepository
+	Orders   *repository.OrderRepository
+	Payments *repository.PaymentRepository
+	Products *repository.ProductRepository
+	Roles    *repository.RoleRepository
+	Users    *repository.UserRepository
+	Logger   logging.Logger
+}
+
+// AuditSnapshot returns the JSON form of an entity as the API exposes it, so secrets
+// such as password hashes never reach the audit trail
+func (s *AuditService) AuditSnapshot(entity string, id uuid.UUID) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch entity {
+	case middleware.AuditOrder:
+		value, err = s.Orders.GetOrderWithAssociations(id)
+	case middleware.AuditPayment:
+		value, err = s.Payments.GetByID(id)
+	case middleware.AuditProduct:
+		value, err = s.Products.GetProductByID(id)
+	case middleware.AuditRole:
+		var role *domain.Role
+		if role, err = s.Roles.GetRoleByID(id); err == nil {
+			value = mapRoleToResponse(role)
+		}
+	case middleware.AuditUser:
+		var user *domain.User
+		if user, err = s.Users.GetUserByID(id); err == nil {
+			value = mapToUserResponse(user)
+		}
+	default:
+		return nil, fmt.Errorf("unknown audit entity %q", entity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toJSONMap(value)
+}
+
+// RecordAudit stores an audit entry. A failure is logged but does not fail the
+// request, whose change has already been made.
+func (s *AuditService) RecordAudit(entry middleware.AuditEntry) {
+	metadata := db.JSONB{
+		"method": entry.Method,
+		"route":  entry.Route,
+		"path":   entry.Path,
+		"status": entry.Status,
+		"before": entry.Before,
+		"after":  entry.After,
+		"diff":   auditDiff(entry.Before, entry.After),
+	}
+	if entry.APIKeyID != nil {
+		metadata["api_key_id"] = entry.APIKeyID.String()
+	}
+
+	log := &domain.Log{
+		ID:          uuid.New(),
+		Timestamp:   time.Now(),
+		Level:       LogTypeAudit,
+		Source:      "audit",
+		UserID:      entry.UserID,
+		Action:      entry.Action,
+		Entity:      entry.Entity,
+		EntityID:    entry.EntityID,
+		Description: entry.Method + " " + entry.Path,
+		Metadata:    metadata,
+		Environment: os.Getenv("APP_ENV"),
+		Application: os.Getenv("APP_NAME"),
+		Type:        LogTypeAudit,
+	}
+	if entry.IPAddress != "" {
+		log.IPAddress = &entry.IPAddress
+	}
+	if entry.RequestID != "" {
+		log.RequestID = &entry.RequestID
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		log.Hostname = &hostname
+	}
+
+	if err := s.Repo.CreateLog(log); err != nil && s.Logger != nil {
+		s.Logger.LogError("Failed to store audit entry", logutil.ServiceCall(entry.Action, entry.Entity, map[string]interface{}{
+			"path":  entry.Path,
+			"error": err.Error(),
+		}))
+	}
+}
+
+// auditDiff lists the top-level fields that differ between two snapshots, each with
+// its value before and after
+func auditDiff(before, after interface{}) map[string]interface{} {
+	beforeFields, _ := before.(map[string]interface{})
+	afterFields, _ := after.(map[string]interface{})
+
+	diff := make(map[string]interface{})
+	for field, old := range beforeFields {
+		if value, ok := afterFields[field]; !ok || !reflect.DeepEqual(old, value) {
+			diff[field] = map[string]interface{}{"before": old, "after": afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			diff[field] = map[string]interface{}{"before": nil, "after": value}
+		}
+	}
+	return diff
+}
+
+func toJSONMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
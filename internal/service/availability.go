@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+var ErrProductNotOrderable = errors.New("product is not available at this time")
+
+// restaurantNow returns the current time in the restaurant's timezone, UTC if unset
+func restaurantNow(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Now().In(loc)
+}
+
+// checkProductSchedule verifies that a product and its category are scheduled to be available at t.
+// The product's Category must be loaded for the category schedule to be considered.
+func checkProductSchedule(product *domain.Product, t time.Time) error {
+	if !product.Schedule.IsAvailableAt(t) {
+		return fmt.Errorf("%w: %s", ErrProductNotOrderable, product.Name)
+	}
+	if product.Category != nil && !product.Category.Schedule.IsAvailableAt(t) {
+		return fmt.Errorf("%w: %s (%s is not being served)", ErrProductNotOrderable, product.Name, product.Category.Name)
+	}
+	return nil
+}
+
+// filterPublicMenu returns a copy of the menu without categories and products scheduled out at t
+func filterPublicMenu(menu *dto.PublicMenuResponse, t time.Time) *dto.PublicMenuResponse {
+	filtered := &dto.PublicMenuResponse{
+		Categories: make([]dto.PublicMenuCategory, 0, len(menu.Categories)),
+	}
+
+	for _, category := range menu.Categories {
+		if !category.Schedule.IsAvailableAt(t) {
+			continue
+		}
+
+		products := make([]dto.PublicMenuProduct, 0, len(category.Products))
+		for _, product := range category.Products {
+			if product.Schedule.IsAvailableAt(t) {
+				products = append(products, product)
+			}
+		}
+
+		category.Products = products
+		filtered.Categories = append(filtered.Categories, category)
+	}
+
+	return filtered
+}
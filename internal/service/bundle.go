@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidBundle          = errors.New("invalid bundle")
+	ErrInvalidBundleSelection = errors.New("invalid bundle selection")
+)
+
+// validateBundle checks the bundle slots of p. Components must be existing,
+// non-bundle products; each slot is either a fixed product or a choice group.
+func validateBundle(db *gorm.DB, p *domain.Product) error {
+	if !p.IsBundle {
+		if len(p.BundleItems) > 0 {
+			return fmt.Errorf("%w: only bundle products can have bundle items", ErrInvalidBundle)
+		}
+		return nil
+	}
+	if len(p.BundleItems) == 0 {
+		return fmt.Errorf("%w: a bundle must contain at least one item", ErrInvalidBundle)
+	}
+
+	productIDs := make(map[uuid.UUID]struct{})
+	var slotIDs []uuid.UUID
+	for i, item := range p.BundleItems {
+		if strings.TrimSpace(item.Name) == "" {
+			return fmt.Errorf("%w: bundle_items[%d] name cannot be empty", ErrInvalidBundle, i)
+		}
+		if item.Quantity < 1 {
+			return fmt.Errorf("%w: bundle_items[%d] quantity must be at least 1", ErrInvalidBundle, i)
+		}
+		if item.ID != uuid.Nil {
+			slotIDs = append(slotIDs, item.ID)
+		}
+
+		switch {
+		case item.ProductID != nil && len(item.Choices) > 0:
+			return fmt.Errorf("%w: bundle_items[%d] must have either a product or choices, not both", ErrInvalidBundle, i)
+		case item.ProductID != nil:
+			productIDs[*item.ProductID] = struct{}{}
+		case len(item.Choices) > 0:
+			seen := make(map[uuid.UUID]bool, len(item.Choices))
+			for _, choice := range item.Choices {
+				if seen[choice.ProductID] {
+					return fmt.Errorf("%w: bundle_items[%d] lists the same choice twice", ErrInvalidBundle, i)
+				}
+				seen[choice.ProductID] = true
+				productIDs[choice.ProductID] = struct{}{}
+			}
+		default:
+			return fmt.Errorf("%w: bundle_items[%d] needs a product or at least one choice", ErrInvalidBundle, i)
+		}
+	}
+
+	if p.ID != uuid.Nil {
+		if _, ok := productIDs[p.ID]; ok {
+			return fmt.Errorf("%w: a bundle cannot contain itself", ErrInvalidBundle)
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(productIDs))
+	for id := range productIDs {
+		ids = append(ids, id)
+	}
+
+	var components []domain.Product
+	if err := db.Select("id", "name", "is_bundle").Where("id IN ?", ids).Find(&components).Error; err != nil {
+		return fmt.Errorf("error checking bundle products: %w", err)
+	}
+	if len(components) != len(ids) {
+		return fmt.Errorf("%w: one or more bundle products do not exist", ErrInvalidBundle)
+	}
+	for _, component := range components {
+		if component.IsBundle {
+			return fmt.Errorf("%w: %s is itself a bundle", ErrInvalidBundle, component.Name)
+		}
+	}
+
+	// Slots can only be kept by ID when they already belong to this bundle
+	if len(slotIDs) > 0 {
+		var count int64
+		if err := db.Model(&domain.BundleItem{}).
+			Where("id IN ? AND bundle_id = ?", slotIDs, p.ID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("error checking bundle items: %w", err)
+		}
+		if int(count) != len(slotIDs) {
+			return fmt.Errorf("%w: unknown bundle item ID", ErrInvalidBundle)
+		}
+	}
+
+	return nil
+}
+
+// syncBundleItems makes the stored slots of a bundle match items. Slots with an ID
+// are kept and updated, new ones are created and the rest are removed.
+// All slots are removed when the product is not a bundle.
+func syncBundleItems(tx *gorm.DB, productID uuid.UUID, isBundle bool, items []domain.BundleItem) error {
+	var keep []uuid.UUID
+	if isBundle {
+		for _, item := range items {
+			if item.ID != uuid.Nil {
+				keep = append(keep, item.ID)
+			}
+		}
+	}
+
+	stale := tx.Model(&domain.BundleItem{}).Select("id").Where("bundle_id = ?", productID)
+	if len(keep) > 0 {
+		stale = stale.Where("id NOT IN ?", keep)
+	}
+	if err := tx.Where("bundle_item_id IN (?)", stale).Delete(&domain.BundleChoice{}).Error; err != nil {
+		return fmt.Errorf("failed to remove bundle choices: %w", err)
+	}
+
+	remove := tx.Where("bundle_id = ?", productID)
+	if len(keep) > 0 {
+		remove = remove.Where("id NOT IN ?", keep)
+	}
+	if err := remove.Delete(&domain.BundleItem{}).Error; err != nil {
+		return fmt.Errorf("failed to remove bundle items: %w", err)
+	}
+
+	if !isBundle {
+		return nil
+	}
+
+	for i := range items {
+		item := &items[i]
+		item.BundleID = productID
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+
+		if err := tx.Omit("Product", "Choices").Save(item).Error; err != nil {
+			return fmt.Errorf("failed to save bundle item: %w", err)
+		}
+
+		// Choices carry no identity of their own, so they are simply replaced
+		if err := tx.Where("bundle_item_id = ?", item.ID).Delete(&domain.BundleChoice{}).Error; err != nil {
+			return fmt.Errorf("failed to replace bundle choices: %w", err)
+		}
+		for j := range item.Choices {
+			choice := &item.Choices[j]
+			choice.ID = uuid.New()
+			choice.BundleItemID = item.ID
+			if err := tx.Omit("Product").Create(choice).Error; err != nil {
+				return fmt.Errorf("failed to save bundle choice: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandBundle turns a bundle order line into its component products.
+// On input, detail.Components only carries the customer's picks (BundleItemID and
+// ProductID); on output it holds every component with names and quantities.
+func expandBundle(detail *domain.OrderDetail) error {
+	product := detail.Product
+
+	selections := make(map[uuid.UUID]uuid.UUID, len(detail.Components))
+	for _, component := range detail.Components {
+		if component.BundleItemID == nil || component.ProductID == nil {
+			return fmt.Errorf("%w: bundle choices need both bundle_item_id and product_id", ErrInvalidBundleSelection)
+		}
+		selections[*component.BundleItemID] = *component.ProductID
+	}
+
+	if !product.IsBundle {
+		if len(selections) > 0 {
+			return fmt.Errorf("%w: %s is not a bundle", ErrInvalidBundleSelection, product.Name)
+		}
+		detail.Components = nil
+		return nil
+	}
+
+	components := make([]domain.OrderDetailComponent, 0, len(product.BundleItems))
+	for _, item := range product.BundleItems {
+		itemID := item.ID
+		component := domain.OrderDetailComponent{
+			BundleItemID: &itemID,
+			Quantity:     item.Quantity * detail.Quantity,
+		}
+
+		if !item.IsChoiceGroup() {
+			component.ProductID = item.ProductID
+			if item.Product != nil {
+				component.ProductName = item.Product.Name
+			}
+		} else {
+			chosen, ok := selections[item.ID]
+			if !ok {
+				return fmt.Errorf("%w: a choice is required for %q in %s", ErrInvalidBundleSelection, item.Name, product.Name)
+			}
+
+			choice := findBundleChoice(item.Choices, chosen)
+			if choice == nil {
+				return fmt.Errorf("%w: selected product is not an option for %q in %s", ErrInvalidBundleSelection, item.Name, product.Name)
+			}
+			if choice.Product != nil && !choice.Product.IsAvailable {
+				return fmt.Errorf("%w: %s is currently unavailable", ErrInvalidBundleSelection, choice.Product.Name)
+			}
+
+			choiceProductID := choice.ProductID
+			component.ProductID = &choiceProductID
+			component.PriceModifier = choice.PriceModifier
+			if choice.Product != nil {
+				component.ProductName = choice.Product.Name
+			}
+		}
+
+		delete(selections, item.ID)
+		components = append(components, component)
+	}
+
+	if len(selections) > 0 {
+		return fmt.Errorf("%w: unknown bundle item for %s", ErrInvalidBundleSelection, product.Name)
+	}
+
+	detail.Components = components
+	return nil
+}
+
+func findBundleChoice(choices []domain.BundleChoice, productID uuid.UUID) *domain.BundleChoice {
+	for i := range choices {
+		if choices[i].ProductID == productID {
+			return &choices[i]
+		}
+	}
+	return nil
+}
@@ -9,10 +9,12 @@ import (
 	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/repository"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
 )
 
 type CategoryService struct {
-	Repo *repository.CategoryRepository
+	Repo      *repository.CategoryRepository
+	MenuCache *MenuCache
 }
 
 func (s *CategoryService) ListAllCategories(includeProducts bool) ([]domain.Category, error) {
@@ -39,6 +41,12 @@ func (s *CategoryService) CreateCategory(category *domain.Category) (*domain.Cat
 	if category.Position < 0 {
 		return nil, errors.New("category position cannot be negative")
 	}
+	if err := category.Schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	if category.Schedule.IsEmpty() {
+		category.Schedule = nil
+	}
 	exists, err := s.Repo.ExistsByName(category.Name)
 	if err != nil {
 		return nil, err
@@ -52,6 +60,8 @@ func (s *CategoryService) CreateCategory(category *domain.Category) (*domain.Cat
 		return nil, err
 	}
 
+	s.MenuCache.Invalidate()
+
 	category.Products = []domain.Product{}
 	return category, nil
 }
@@ -88,10 +98,21 @@ func (s *CategoryService) UpdateCategory(id uuid.UUID, update *dto.UpdateCategor
 		existing.IsActive = *update.IsActive
 	}
 
+	if update.Schedule != nil {
+		if err := update.Schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		existing.Schedule = update.Schedule
+		if existing.Schedule.IsEmpty() {
+			existing.Schedule = nil
+		}
+	}
+
 	if err := s.Repo.UpdateCategory(existing); err != nil {
 		return nil, err
 	}
 
+	s.MenuCache.Invalidate()
 	return existing, nil
 }
 
@@ -105,5 +126,48 @@ func (s *CategoryService) DeleteCategory(id uuid.UUID) error {
 		return fmt.Errorf("cannot delete category: it has associated products")
 	}
 
-	return s.Repo.DeleteCategory(id)
+	if err := s.Repo.DeleteCategory(id); err != nil {
+		return err
+	}
+
+	s.MenuCache.Invalidate()
+	return nil
+}
+
+// ListDeletedCategories lists deleted categories that can be restored
+func (s *CategoryService) ListDeletedCategories() ([]dto.DeletedItemResponse, error) {
+	categories, err := s.Repo.ListDeletedCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(categories))
+	for _, category := range categories {
+		items = append(items, mapDeletedItem(category.ID, category.Name, "", category.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreCategory restores a deleted category unless another one took its name
+func (s *CategoryService) RestoreCategory(id uuid.UUID) (*domain.Category, error) {
+	category, err := s.Repo.GetDeletedCategory(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+
+	taken, err := s.Repo.ExistsByName(category.Name)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, fmt.Errorf("%w: another category is named %q", ErrRestoreConflict, category.Name)
+	}
+
+	if err := s.Repo.RestoreCategory(id); err != nil {
+		return nil, notDeleted(err)
+	}
+
+	s.MenuCache.Invalidate()
+	category.DeletedAt = gorm.DeletedAt{}
+	return category, nil
 }
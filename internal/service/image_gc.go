@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// ProductImagePrefix is the storage prefix all product images are uploaded under
+const ProductImagePrefix = "products/"
+
+// ImageGCService removes stored product images that nothing references anymore
+type ImageGCService struct {
+	DB       *gorm.DB
+	Uploader utils.Uploader
+	// MinAge protects recent uploads whose product is still being created
+	MinAge time.Duration
+}
+
+// ImageGCResult summarises one garbage collection run
+type ImageGCResult struct {
+	Scanned  int
+	Orphaned []utils.StoredObject
+	Deleted  int
+	Failed   map[string]error
+}
+
+// Run lists the objects under ProductImagePrefix and deletes those not referenced
+// by any product or theme. With dryRun set nothing is deleted.
+func (s *ImageGCService) Run(dryRun bool) (*ImageGCResult, error) {
+	if s.Uploader == nil {
+		return nil, fmt.Errorf("file storage is not configured")
+	}
+
+	referenced, err := s.referencedKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.Uploader.List(ProductImagePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImageGCResult{
+		Scanned: len(objects),
+		Failed:  make(map[string]error),
+	}
+	cutoff := time.Now().Add(-s.MinAge)
+	for _, object := range objects {
+		if referenced[object.Key] || object.LastModified.After(cutoff) {
+			continue
+		}
+		result.Orphaned = append(result.Orphaned, object)
+		if dryRun {
+			continue
+		}
+
+		if err := s.Uploader.Delete(object.Key); err != nil {
+			result.Failed[object.Key] = err
+			continue
+		}
+		result.Deleted++
+	}
+
+	return result, nil
+}
+
+// referencedKeys collects the storage keys of every image still in use. Images of
+// deleted products and themes count as in use, since those can be restored.
+func (s *ImageGCService) referencedKeys() (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	add := func(url string) {
+		if key, ok := s.Uploader.KeyFromURL(url); ok {
+			referenced[key] = true
+		}
+	}
+
+	var products []domain.Product
+	if err := s.DB.Unscoped().Select("id", "image_url", "images").Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to load product images: %w", err)
+	}
+	for _, product := range products {
+		add(product.ImageURL)
+		for _, url := range product.Images {
+			add(url)
+		}
+	}
+
+	var themes []domain.Theme
+	if err := s.DB.Unscoped().Select("id", "logo_url", "favicon_url").Find(&themes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load theme images: %w", err)
+	}
+	for _, theme := range themes {
+		add(theme.LogoURL)
+		add(theme.FaviconURL)
+	}
+
+	return referenced, nil
+}
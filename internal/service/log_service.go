@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+const (
+	DefaultLogPageSize = 50
+	MaxLogPageSize     = 200
+)
+
+var ErrInvalidLogFilter = errors.New("invalid log filter")
+
+// Metadata keys end up in the SQL text, so they are limited to plain identifiers
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// LogService searches the logs table for the log viewer
+type LogService struct {
+	Repo *repository.LogRepository
+}
+
+// ListLogs returns a page of logs matching filter, newest first. Pages are chained
+// with NextCursor, which stays stable while new logs are written.
+func (s *LogService) ListLogs(filter dto.LogFilter) (*dto.LogPageResponse, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLogPageSize
+	}
+	if limit > MaxLogPageSize {
+		limit = MaxLogPageSize
+	}
+
+	for key := range filter.Metadata {
+		if !metadataKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w: metadata key %q", ErrInvalidLogFilter, key)
+		}
+	}
+
+	query := repository.LogQuery{
+		Level:     filter.Level,
+		Source:    filter.Source,
+		Action:    filter.Action,
+		Entity:    filter.Entity,
+		EntityID:  filter.EntityID,
+		UserID:    filter.UserID,
+		RequestID: filter.RequestID,
+		From:      filter.From,
+		To:        filter.To,
+		Metadata:  filter.Metadata,
+		Limit:     limit + 1, // One more tells whether another page follows
+	}
+	if filter.Cursor != "" {
+		timestamp, id, err := decodeLogCursor(filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cursor", ErrInvalidLogFilter)
+		}
+		query.AfterTimestamp = &timestamp
+		query.AfterID = id
+	}
+
+	logs, err := s.Repo.ListLogs(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &dto.LogPageResponse{Logs: logs}
+	if len(logs) > limit {
+		page.Logs = logs[:limit]
+		last := page.Logs[limit-1]
+		page.NextCursor = encodeLogCursor(last.Timestamp, last.ID)
+	}
+	return page, nil
+}
+
+// A cursor is the timestamp and ID of the last log of a page
+func encodeLogCursor(timestamp time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(timestamp.UnixNano(), 10) + "_" + id.String()))
+}
+
+func decodeLogCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	nanos, idText, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return time.Time{}, uuid.Nil, errors.New("malformed cursor")
+	}
+	unixNanos, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idText)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	// Local time, like the timestamps the logs were written with
+	return time.Unix(0, unixNanos), id, nil
+}
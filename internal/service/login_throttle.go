@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/core/logging"
+	"github.com/latoulicious/siresto-backend/pkg/logutil"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// LoginLockedError is returned while an account or client IP is locked out
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, try again after %s", ErrLoginLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginPolicy decides when repeated failures lock logins out
+type LoginPolicy struct {
+	MaxFailures   int           // Failures allowed before the first lockout
+	BaseLockout   time.Duration // First lockout, doubled by every further failure
+	MaxLockout    time.Duration
+	FailureWindow time.Duration // Failures are forgotten once this long has passed without one
+}
+
+var (
+	// DefaultAccountLoginPolicy protects a single account against password guessing
+	DefaultAccountLoginPolicy = LoginPolicy{
+		MaxFailures:   5,
+		BaseLockout:   time.Minute,
+		MaxLockout:    time.Hour,
+		FailureWindow: 15 * time.Minute,
+	}
+
+	// DefaultIPLoginPolicy is looser, since a restaurant's staff often share one address
+	DefaultIPLoginPolicy = LoginPolicy{
+		MaxFailures:   20,
+		BaseLockout:   time.Minute,
+		MaxLockout:    time.Hour,
+		FailureWindow: 15 * time.Minute,
+	}
+)
+
+// lockout returns how long to lock after the given number of consecutive failures
+func (p LoginPolicy) lockout(failures int) time.Duration {
+	if failures < p.MaxFailures {
+		return 0
+	}
+	exponent := failures - p.MaxFailures
+	if exponent > 30 {
+		return p.MaxLockout
+	}
+	lockout := p.BaseLockout * time.Duration(math.Pow(2, float64(exponent)))
+	if lockout > p.MaxLockout || lockout <= 0 {
+		return p.MaxLockout
+	}
+	return lockout
+}
+
+// LoginThrottle tracks failed logins per account and per client IP and locks either
+// out with exponential backoff. Every failed, locked and blocked attempt is logged.
+// Callers count each attempt with Begin before checking credentials.
+type LoginThrottle struct {
+	Repo          *repository.LoginFailureRepository
+	Logger        logging.Logger
+	AccountPolicy LoginPolicy // Zero value means DefaultAccountLoginPolicy
+	IPPolicy      LoginPolicy // Zero value means DefaultIPLoginPolicy
+}
+
+func accountKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (t *LoginThrottle) accountPolicy() LoginPolicy {
+	if t.AccountPolicy.MaxFailures == 0 {
+		return DefaultAccountLoginPolicy
+	}
+	return t.AccountPolicy
+}
+
+func (t *LoginThrottle) ipPolicy() LoginPolicy {
+	if t.IPPolicy.MaxFailures == 0 {
+		return DefaultIPLoginPolicy
+	}
+	return t.IPPolicy
+}
+
+// lockSteps lists the failure counts at which the lockout grows, largest first, with the
+// time each would lock until if reached now
+func (p LoginPolicy) lockSteps(now time.Time) []repository.LoginLockStep {
+	var steps []repository.LoginLockStep
+	for failures := p.MaxFailures; ; failures++ {
+		lockout := p.lockout(failures)
+		steps = append(steps, repository.LoginLockStep{Failures: failures, Until: now.Add(lockout)})
+		if lockout >= p.MaxLockout {
+			break
+		}
+	}
+	slices.Reverse(steps)
+	return steps
+}
+
+// LoginAttempt is a login attempt counted as a failure before its credentials are checked,
+// so a burst of concurrent guesses cannot all get in below the limit. Report the outcome
+// with Failed or Succeeded, or take the attempt back with Cancel.
+type LoginAttempt struct {
+	throttle *LoginThrottle
+	account  string
+	ip       string
+	counts   []attemptCount
+}
+
+type attemptCount struct {
+	key         string
+	failures    int
+	lockedUntil *time.Time // Set when this attempt's failure locks the key
+}
+
+// Begin counts an attempt against the account and the client IP, and refuses it with a
+// *LoginLockedError while either is locked out. Refused attempts are not counted.
+func (t *LoginThrottle) Begin(account, ip string) (*LoginAttempt, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	attempt := &LoginAttempt{throttle: t, account: account, ip: ip}
+	for _, limit := range []struct {
+		key    string
+		policy LoginPolicy
+	}{
+		{accountKey(account), t.accountPolicy()},
+		{ipKey(ip), t.ipPolicy()},
+	} {
+		// Truncated to what Postgres stores, so a release can match the lock it set
+		now := time.Now().Truncate(time.Microsecond)
+		failures, counted, err := t.Repo.CountLoginAttempt(limit.key, now, now.Add(-limit.policy.FailureWindow), limit.policy.lockSteps(now))
+		if err != nil {
+			attempt.Cancel()
+			return nil, err
+		}
+		if !counted {
+			attempt.Cancel()
+			return nil, t.locked(limit.key, account, ip)
+		}
+
+		count := attemptCount{key: limit.key, failures: failures}
+		if lockout := limit.policy.lockout(failures); lockout > 0 {
+			lockedUntil := now.Add(lockout)
+			count.lockedUntil = &lockedUntil
+		}
+		attempt.counts = append(attempt.counts, count)
+	}
+	return attempt, nil
+}
+
+// locked reports an attempt refused because key is locked out
+func (t *LoginThrottle) locked(key, account, ip string) error {
+	lockedUntil := time.Now()
+	failure, err := t.Repo.FindLoginFailure(key)
+	if err != nil {
+		return err
+	}
+	if failure != nil && failure.LockedUntil != nil {
+		lockedUntil = *failure.LockedUntil
+	}
+
+	t.log("Login attempt blocked by lockout", "auth.login_blocked", map[string]interface{}{
+		"account":      account,
+		"ip":           ip,
+		"locked_until": lockedUntil.Format(time.RFC3339),
+	})
+	return &LoginLockedError{Until: lockedUntil}
+}
+
+// Failed keeps the attempt counted against the account and the client IP
+func (a *LoginAttempt) Failed() {
+	if a == nil || len(a.counts) == 0 {
+		return
+	}
+
+	for _, count := range a.counts {
+		if count.lockedUntil != nil {
+			a.throttle.log("Login locked after repeated failures", "auth.login_locked", map[string]interface{}{
+				"key":          count.key,
+				"account":      a.account,
+				"ip":           a.ip,
+				"failures":     count.failures,
+				"locked_until": count.lockedUntil.Format(time.RFC3339),
+			})
+		}
+	}
+
+	a.throttle.log("Failed login attempt", "auth.login_failed", map[string]interface{}{
+		"account":  a.account,
+		"ip":       a.ip,
+		"failures": a.counts[0].failures,
+	})
+}
+
+// Succeeded forgets the account's failures. The client IP only loses this attempt, so
+// one valid login cannot be used to reset guessing against other accounts.
+func (a *LoginAttempt) Succeeded() {
+	if a == nil {
+		return
+	}
+
+	for _, count := range a.counts {
+		if count.key == accountKey(a.account) {
+			if err := a.throttle.Repo.DeleteLoginFailure(count.key); err != nil {
+				a.throttle.logError("Failed to reset login failures", count.key, err)
+			}
+			continue
+		}
+		if err := a.throttle.Repo.ReleaseLoginAttempt(count.key, count.lockedUntil); err != nil {
+			a.throttle.logError("Failed to release login attempt", count.key, err)
+		}
+	}
+}
+
+// Cancel takes the attempt back when it could not be decided, e.g. on a database error
+func (a *LoginAttempt) Cancel() {
+	if a == nil {
+		return
+	}
+
+	for _, count := range a.counts {
+		if err := a.throttle.Repo.ReleaseLoginAttempt(count.key, count.lockedUntil); err != nil {
+			a.throttle.logError("Failed to release login attempt", count.key, err)
+		}
+	}
+	a.counts = nil
+}
+
+// Unlock lifts the lockouts of the given accounts before they expire
+func (t *LoginThrottle) Unlock(accounts ...string) error {
+	if t == nil {
+		return nil
+	}
+	for _, account := range accounts {
+		if err := t.Repo.DeleteLoginFailure(accountKey(account)); err != nil {
+			return err
+		}
+	}
+	t.log("Account login lockout lifted", "auth.login_unlocked", map[string]interface{}{
+		"accounts": accounts,
+	})
+	return nil
+}
+
+func (t *LoginThrottle) log(msg, action string, extra map[string]interface{}) {
+	if t.Logger == nil {
+		return
+	}
+	t.Logger.LogInfo(msg, logutil.ServiceCall(action, "user", extra))
+}
+
+func (t *LoginThrottle) logError(msg, key string, err error) {
+	if t.Logger == nil {
+		return
+	}
+	t.Logger.LogError(msg, logutil.ServiceCall("auth.login_throttle", "user", map[string]interface{}{
+		"key":   key,
+		"error": err.Error(),
+	}))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+// MeService tells frontends who is signed in and what they may do, using the same
+// permission evaluation as the RBAC middleware
+type MeService struct {
+	Users          *UserService
+	PermissionRepo *repository.PermissionRepository
+}
+
+// GetMe returns the user with their granted and effective permissions
+func (s *MeService) GetMe(userID uuid.UUID, granted []string) (*dto.MeResponse, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := s.PermissionRepo.ListPermissionNames()
+	if err != nil {
+		return nil, err
+	}
+
+	if granted == nil {
+		granted = []string{}
+	}
+	return &dto.MeResponse{
+		User:               *user,
+		Role:               user.Role,
+		Permissions:        middleware.ExpandPermissions(granted, known),
+		GrantedPermissions: granted,
+	}, nil
+}
+
+// CheckPermissions reports, for each requested permission, whether granted allows it
+func (s *MeService) CheckPermissions(granted, requested []string) *dto.PermissionCheckResponse {
+	results := make(map[string]bool, len(requested))
+	for _, permission := range requested {
+		results[permission] = middleware.HasPermission(granted, permission)
+	}
+	return &dto.PermissionCheckResponse{Results: results}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+// MenuCache holds the public menu in memory until a menu mutation invalidates it.
+// The cached menu still contains scheduled items; callers filter it per request.
+// The zero value is ready to use and a nil *MenuCache never caches.
+type MenuCache struct {
+	mu      sync.RWMutex
+	menu    *dto.PublicMenuResponse
+	version uint64
+}
+
+// GetOrLoad returns the cached menu, calling load on a cache miss
+func (c *MenuCache) GetOrLoad(load func() (*dto.PublicMenuResponse, error)) (*dto.PublicMenuResponse, error) {
+	if c == nil {
+		return load()
+	}
+
+	c.mu.RLock()
+	menu, version := c.menu, c.version
+	c.mu.RUnlock()
+	if menu != nil {
+		return menu, nil
+	}
+
+	menu, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip storing if the menu was invalidated while loading, so stale data is never cached
+	c.mu.Lock()
+	if c.version == version {
+		c.menu = menu
+	}
+	c.mu.Unlock()
+
+	return menu, nil
+}
+
+// Invalidate drops the cached menu so the next read reloads it from the database
+func (c *MenuCache) Invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.menu = nil
+	c.version++
+	c.mu.Unlock()
+}
+
+// menuETag derives a weak validator from the serialized menu.
+// It is weak because the response envelope carries a per-request timestamp.
+func menuETag(menu *dto.PublicMenuResponse) (string, error) {
+	payload, err := json.Marshal(menu)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize menu: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	return fmt.Sprintf(`W/"%s"`, hex.EncodeToString(sum[:16])), nil
+}
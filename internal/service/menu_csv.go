@@ -0,0 +1,308 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+// MenuCSVHeader lists the columns used for CSV menu import and export.
+// Every row describes one variation option; category, product and variation
+// attributes are read from the first row that mentions them.
+var MenuCSVHeader = []string{
+	"category",
+	"category_position",
+	"category_active",
+	"product",
+	"description",
+	"image_url",
+	"base_price",
+	"product_available",
+	"product_position",
+	"variation_type",
+	"variation_required",
+	"variation_default",
+	"variation_available",
+	"option_label",
+	"option_price_modifier",
+	"option_price_absolute",
+	"option_default",
+}
+
+var ErrInvalidMenuCSV = errors.New("invalid menu CSV")
+
+// ParseMenuCSV converts a flat CSV menu into the nested import structure.
+// Cell-level problems are returned as row errors so they can be reported
+// alongside validation errors.
+func ParseMenuCSV(r io.Reader) (*dto.MenuImportRequest, []dto.MenuImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read header: %v", ErrInvalidMenuCSV, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["category"]; !ok {
+		return nil, nil, fmt.Errorf("%w: missing required column \"category\"", ErrInvalidMenuCSV)
+	}
+
+	req := &dto.MenuImportRequest{}
+	var rowErrors []dto.MenuImportRowError
+
+	categoryIndex := make(map[string]int)
+	productIndex := make(map[string]int)
+	variationIndex := make(map[string]int)
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			rowErrors = append(rowErrors, dto.MenuImportRowError{Row: row, Message: err.Error()})
+			continue
+		}
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		path := fmt.Sprintf("row %d", row)
+		cellErrors := 0
+		parseInt := func(name string) int {
+			value := cell(name)
+			if value == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				rowErrors = append(rowErrors, dto.MenuImportRowError{Row: row, Path: path, Field: name, Message: "must be an integer"})
+				cellErrors++
+			}
+			return n
+		}
+		parseFloat := func(name string) *float64 {
+			value := cell(name)
+			if value == "" {
+				return nil
+			}
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				rowErrors = append(rowErrors, dto.MenuImportRowError{Row: row, Path: path, Field: name, Message: "must be a number"})
+				cellErrors++
+				return nil
+			}
+			return &f
+		}
+		parseBool := func(name string) *bool {
+			value := cell(name)
+			if value == "" {
+				return nil
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				rowErrors = append(rowErrors, dto.MenuImportRowError{Row: row, Path: path, Field: name, Message: "must be true or false"})
+				cellErrors++
+				return nil
+			}
+			return &b
+		}
+
+		categoryName := cell("category")
+		if categoryName == "" {
+			rowErrors = append(rowErrors, dto.MenuImportRowError{Row: row, Path: path, Field: "category", Message: "category name cannot be empty"})
+			continue
+		}
+
+		categoryPosition := parseInt("category_position")
+		categoryActive := parseBool("category_active")
+		basePrice := parseFloat("base_price")
+		productAvailable := parseBool("product_available")
+		productPosition := parseInt("product_position")
+		variationRequired := parseBool("variation_required")
+		variationDefault := parseBool("variation_default")
+		variationAvailable := parseBool("variation_available")
+		priceModifier := parseFloat("option_price_modifier")
+		priceAbsolute := parseFloat("option_price_absolute")
+		optionDefault := parseBool("option_default")
+		if cellErrors > 0 {
+			continue
+		}
+
+		categoryKey := strings.ToLower(categoryName)
+		ci, ok := categoryIndex[categoryKey]
+		if !ok {
+			req.Categories = append(req.Categories, dto.MenuCategoryItem{
+				Name:     categoryName,
+				IsActive: categoryActive,
+				Position: categoryPosition,
+				Row:      row,
+			})
+			ci = len(req.Categories) - 1
+			categoryIndex[categoryKey] = ci
+		}
+		category := &req.Categories[ci]
+
+		productName := cell("product")
+		if productName == "" {
+			continue
+		}
+
+		productKey := categoryKey + "\x00" + strings.ToLower(productName)
+		pi, ok := productIndex[productKey]
+		if !ok {
+			product := dto.MenuProductItem{
+				Name:        productName,
+				Description: cell("description"),
+				ImageURL:    cell("image_url"),
+				IsAvailable: productAvailable,
+				Position:    productPosition,
+				Row:         row,
+			}
+			if basePrice != nil {
+				product.BasePrice = *basePrice
+			}
+			category.Products = append(category.Products, product)
+			pi = len(category.Products) - 1
+			productIndex[productKey] = pi
+		}
+		product := &category.Products[pi]
+
+		variationType := cell("variation_type")
+		if variationType == "" {
+			continue
+		}
+
+		variationKey := productKey + "\x00" + strings.ToLower(variationType)
+		vi, ok := variationIndex[variationKey]
+		if !ok {
+			variation := dto.MenuVariationItem{
+				VariationType: variationType,
+				IsAvailable:   variationAvailable,
+				Row:           row,
+			}
+			if variationRequired != nil {
+				variation.IsRequired = *variationRequired
+			}
+			if variationDefault != nil {
+				variation.IsDefault = *variationDefault
+			}
+			product.Variations = append(product.Variations, variation)
+			vi = len(product.Variations) - 1
+			variationIndex[variationKey] = vi
+		}
+		variation := &product.Variations[vi]
+
+		option := dto.VariationOption{
+			Label:         cell("option_label"),
+			PriceModifier: priceModifier,
+			PriceAbsolute: priceAbsolute,
+		}
+		if optionDefault != nil {
+			option.IsDefault = *optionDefault
+		}
+		variation.Options = append(variation.Options, option)
+	}
+
+	return req, rowErrors, nil
+}
+
+// WriteMenuCSV flattens an exported menu into CSV rows using MenuCSVHeader
+func WriteMenuCSV(w io.Writer, menu *dto.MenuExportResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(MenuCSVHeader); err != nil {
+		return err
+	}
+
+	for _, category := range menu.Categories {
+		categoryCols := []string{
+			category.Name,
+			strconv.Itoa(category.Position),
+			formatOptionalBool(category.IsActive),
+		}
+
+		if len(category.Products) == 0 {
+			if err := writer.Write(padMenuCSVRow(categoryCols)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, product := range category.Products {
+			productCols := append(append([]string{}, categoryCols...),
+				product.Name,
+				product.Description,
+				product.ImageURL,
+				strconv.FormatFloat(product.BasePrice, 'f', -1, 64),
+				formatOptionalBool(product.IsAvailable),
+				strconv.Itoa(product.Position),
+			)
+
+			if len(product.Variations) == 0 {
+				if err := writer.Write(padMenuCSVRow(productCols)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, variation := range product.Variations {
+				variationCols := append(append([]string{}, productCols...),
+					variation.VariationType,
+					strconv.FormatBool(variation.IsRequired),
+					strconv.FormatBool(variation.IsDefault),
+					formatOptionalBool(variation.IsAvailable),
+				)
+
+				for _, option := range variation.Options {
+					row := append(append([]string{}, variationCols...),
+						option.Label,
+						formatOptionalFloat(option.PriceModifier),
+						formatOptionalFloat(option.PriceAbsolute),
+						strconv.FormatBool(option.IsDefault),
+					)
+					if err := writer.Write(row); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func padMenuCSVRow(cols []string) []string {
+	row := make([]string, len(MenuCSVHeader))
+	copy(row, cols)
+	return row
+}
+
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
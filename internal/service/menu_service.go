@@ -0,0 +1,400 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyMenuImport = errors.New("menu import contains no categories")
+
+	// errMenuImportRollback aborts the import transaction without reporting a failure
+	errMenuImportRollback = errors.New("menu import rolled back")
+)
+
+type MenuService struct {
+	DB           *gorm.DB
+	CategoryRepo *repository.CategoryRepository
+	Cache        *MenuCache
+	Location     *time.Location // Restaurant timezone for availability schedules
+}
+
+// GetPublicMenu returns the customer-facing menu as it stands right now, along with its ETag.
+// The menu is served from cache when possible and filtered by availability schedules.
+func (s *MenuService) GetPublicMenu() (*dto.PublicMenuResponse, string, error) {
+	menu, err := s.Cache.GetOrLoad(func() (*dto.PublicMenuResponse, error) {
+		categories, err := s.CategoryRepo.ListActiveMenu()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load menu: %w", err)
+		}
+		return dto.ToPublicMenuResponse(categories), nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	menu = filterPublicMenu(menu, restaurantNow(s.Location))
+
+	etag, err := menuETag(menu)
+	if err != nil {
+		return nil, "", err
+	}
+	return menu, etag, nil
+}
+
+// ImportMenu validates and applies a bulk menu import in a single transaction.
+// Categories are matched by name, products by name within their category and
+// variations by type within their product; matches are updated, the rest created.
+// When dryRun is set, or any row fails validation, the transaction is rolled back
+// and only the report is returned.
+func (s *MenuService) ImportMenu(req *dto.MenuImportRequest, dryRun bool) (*dto.MenuImportResult, error) {
+	if req == nil || len(req.Categories) == 0 {
+		return nil, ErrEmptyMenuImport
+	}
+
+	result := &dto.MenuImportResult{
+		DryRun: dryRun,
+		Errors: []dto.MenuImportRowError{},
+	}
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range req.Categories {
+			if err := importMenuCategory(tx, &req.Categories[i], fmt.Sprintf("categories[%d]", i), result); err != nil {
+				return err
+			}
+		}
+
+		if dryRun || len(result.Errors) > 0 {
+			return errMenuImportRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errMenuImportRollback) {
+		return nil, fmt.Errorf("failed to import menu: %w", err)
+	}
+
+	result.Applied = err == nil
+	if result.Applied {
+		s.Cache.Invalidate()
+	}
+	return result, nil
+}
+
+// ImportMenuCSV parses a CSV menu and imports it. Rows that cannot be parsed
+// force a dry run so the remaining rows are still validated and reported.
+func (s *MenuService) ImportMenuCSV(r io.Reader, dryRun bool) (*dto.MenuImportResult, error) {
+	req, parseErrors, err := ParseMenuCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Categories) == 0 {
+		if len(parseErrors) == 0 {
+			return nil, ErrEmptyMenuImport
+		}
+		return &dto.MenuImportResult{DryRun: dryRun, Errors: parseErrors}, nil
+	}
+
+	result, err := s.ImportMenu(req, dryRun || len(parseErrors) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result.DryRun = dryRun
+	result.Errors = append(parseErrors, result.Errors...)
+	return result, nil
+}
+
+// ExportMenu returns every category with its products and variations ordered by position
+func (s *MenuService) ExportMenu() (*dto.MenuExportResponse, error) {
+	categories, err := s.CategoryRepo.ListAllCategoriesWithProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Position < categories[j].Position
+	})
+
+	export := &dto.MenuExportResponse{
+		Categories: make([]dto.MenuCategoryItem, 0, len(categories)),
+	}
+
+	for _, category := range categories {
+		isActive := category.IsActive
+		item := dto.MenuCategoryItem{
+			Name:     category.Name,
+			IsActive: &isActive,
+			Position: category.Position,
+		}
+
+		products := category.Products
+		sort.SliceStable(products, func(i, j int) bool {
+			return products[i].Position < products[j].Position
+		})
+
+		for _, product := range products {
+			isAvailable := product.IsAvailable
+			productItem := dto.MenuProductItem{
+				Name:        product.Name,
+				Description: product.Description,
+				ImageURL:    product.ImageURL,
+				BasePrice:   product.BasePrice,
+				IsAvailable: &isAvailable,
+				Position:    product.Position,
+			}
+
+			for _, variation := range product.Variations {
+				variationAvailable := variation.IsAvailable
+				productItem.Variations = append(productItem.Variations, dto.MenuVariationItem{
+					VariationType: variation.VariationType,
+					IsDefault:     variation.IsDefault,
+					IsAvailable:   &variationAvailable,
+					IsRequired:    variation.IsRequired,
+					Options:       dto.ToVariationSummary(&variation).Options,
+				})
+			}
+
+			item.Products = append(item.Products, productItem)
+		}
+
+		export.Categories = append(export.Categories, item)
+	}
+
+	return export, nil
+}
+
+// Helper Function
+
+// importMenuCategory upserts a category and its products, recording validation
+// failures on the result. Only database errors are returned.
+func importMenuCategory(tx *gorm.DB, item *dto.MenuCategoryItem, path string, result *dto.MenuImportResult) error {
+	name := strings.TrimSpace(item.Name)
+	if name == "" {
+		addMenuImportError(result, item.Row, path, "name", "category name cannot be empty")
+		return nil
+	}
+	if item.Position < 0 {
+		addMenuImportError(result, item.Row, path, "position", "category position cannot be negative")
+		return nil
+	}
+
+	var category domain.Category
+	err := tx.Where("LOWER(name) = LOWER(?)", name).First(&category).Error
+	switch {
+	case err == nil:
+		category.Position = item.Position
+		if item.IsActive != nil {
+			category.IsActive = *item.IsActive
+		}
+		if err := tx.Omit("Products").Save(&category).Error; err != nil {
+			return fmt.Errorf("failed to update category %q: %w", name, err)
+		}
+		result.CategoriesUpdated++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		isActive := true
+		if item.IsActive != nil {
+			isActive = *item.IsActive
+		}
+		category = domain.Category{
+			ID:       uuid.New(),
+			Name:     name,
+			IsActive: isActive,
+			Position: item.Position,
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("failed to create category %q: %w", name, err)
+		}
+		// GORM skips zero values on create and reads the column default back,
+		// so persist an explicit inactive flag separately
+		if !isActive {
+			category.IsActive = false
+			if err := tx.Model(&category).Update("is_active", false).Error; err != nil {
+				return fmt.Errorf("failed to create category %q: %w", name, err)
+			}
+		}
+		result.CategoriesCreated++
+	default:
+		return fmt.Errorf("failed to look up category %q: %w", name, err)
+	}
+
+	for i := range item.Products {
+		productPath := fmt.Sprintf("%s.products[%d]", path, i)
+		if err := importMenuProduct(tx, category.ID, &item.Products[i], productPath, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importMenuProduct(tx *gorm.DB, categoryID uuid.UUID, item *dto.MenuProductItem, path string, result *dto.MenuImportResult) error {
+	isAvailable := true
+	if item.IsAvailable != nil {
+		isAvailable = *item.IsAvailable
+	}
+
+	product := &domain.Product{
+		CategoryID:  &categoryID,
+		Name:        strings.TrimSpace(item.Name),
+		Description: item.Description,
+		ImageURL:    item.ImageURL,
+		BasePrice:   item.BasePrice,
+		IsAvailable: isAvailable,
+		Position:    item.Position,
+	}
+
+	// Apply the same rules as the product endpoints
+	if err := ValidateProduct(tx, product); err != nil {
+		addMenuImportError(result, item.Row, path, productErrorField(err), err.Error())
+		return nil
+	}
+
+	var existing domain.Product
+	err := tx.Where("category_id = ? AND LOWER(name) = LOWER(?)", categoryID, product.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		existing.Description = product.Description
+		if product.ImageURL != "" && product.ImageURL != existing.ImageURL {
+			existing.ImageURL = product.ImageURL
+			existing.Images = nil
+		}
+		existing.BasePrice = product.BasePrice
+		existing.IsAvailable = product.IsAvailable
+		existing.Position = product.Position
+		if err := tx.Omit("Category", "Variations").Save(&existing).Error; err != nil {
+			return fmt.Errorf("failed to update product %q: %w", product.Name, err)
+		}
+		product = &existing
+		result.ProductsUpdated++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		product.ID = uuid.New()
+		if err := tx.Create(product).Error; err != nil {
+			return fmt.Errorf("failed to create product %q: %w", product.Name, err)
+		}
+		if !isAvailable {
+			product.IsAvailable = false
+			if err := tx.Model(product).Update("is_available", false).Error; err != nil {
+				return fmt.Errorf("failed to create product %q: %w", product.Name, err)
+			}
+		}
+		result.ProductsCreated++
+	default:
+		return fmt.Errorf("failed to look up product %q: %w", product.Name, err)
+	}
+
+	for i := range item.Variations {
+		variationPath := fmt.Sprintf("%s.variations[%d]", path, i)
+		if err := importMenuVariation(tx, product.ID, &item.Variations[i], variationPath, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importMenuVariation(tx *gorm.DB, productID uuid.UUID, item *dto.MenuVariationItem, path string, result *dto.MenuImportResult) error {
+	variationType := strings.TrimSpace(item.VariationType)
+	if variationType == "" {
+		addMenuImportError(result, item.Row, path, "variation_type", "variation_type is required")
+		return nil
+	}
+	if len(item.Options) == 0 {
+		addMenuImportError(result, item.Row, path, "options", "options must contain at least one item")
+		return nil
+	}
+
+	options := make(db.VariationOptions, 0, len(item.Options))
+	for i, opt := range item.Options {
+		if strings.TrimSpace(opt.Label) == "" {
+			addMenuImportError(result, item.Row, fmt.Sprintf("%s.options[%d]", path, i), "label", "option label cannot be empty")
+			return nil
+		}
+		options = append(options, db.VariationOption{
+			Label:         opt.Label,
+			PriceModifier: opt.PriceModifier,
+			PriceAbsolute: opt.PriceAbsolute,
+			IsDefault:     opt.IsDefault,
+		})
+	}
+
+	isAvailable := true
+	if item.IsAvailable != nil {
+		isAvailable = *item.IsAvailable
+	}
+
+	var variation domain.Variation
+	err := tx.Where("product_id = ? AND variation_type = ?", productID, variationType).First(&variation).Error
+	switch {
+	case err == nil:
+		variation.IsDefault = item.IsDefault
+		variation.IsAvailable = isAvailable
+		variation.IsRequired = item.IsRequired
+		variation.Options = options
+		if err := tx.Omit("Product").Save(&variation).Error; err != nil {
+			return fmt.Errorf("failed to update variation %q: %w", variationType, err)
+		}
+		result.VariationsUpdated++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		variation = domain.Variation{
+			ID:            uuid.New(),
+			ProductID:     productID,
+			IsDefault:     item.IsDefault,
+			IsAvailable:   isAvailable,
+			IsRequired:    item.IsRequired,
+			VariationType: variationType,
+			Options:       options,
+		}
+		if err := tx.Create(&variation).Error; err != nil {
+			return fmt.Errorf("failed to create variation %q: %w", variationType, err)
+		}
+		if !isAvailable {
+			variation.IsAvailable = false
+			if err := tx.Model(&variation).Update("is_available", false).Error; err != nil {
+				return fmt.Errorf("failed to create variation %q: %w", variationType, err)
+			}
+		}
+		result.VariationsCreated++
+	default:
+		return fmt.Errorf("failed to look up variation %q: %w", variationType, err)
+	}
+
+	return nil
+}
+
+func addMenuImportError(result *dto.MenuImportResult, row int, path, field, message string) {
+	result.Errors = append(result.Errors, dto.MenuImportRowError{
+		Row:     row,
+		Path:    path,
+		Field:   field,
+		Message: message,
+	})
+}
+
+// productErrorField maps ValidateProduct errors to the offending import field
+func productErrorField(err error) string {
+	switch {
+	case errors.Is(err, ErrEmptyName):
+		return "name"
+	case errors.Is(err, ErrInvalidBasePrice):
+		return "base_price"
+	case errors.Is(err, ErrMissingCategoryID), errors.Is(err, ErrCategoryNotFound):
+		return "category"
+	case errors.Is(err, ErrInvalidSchedule):
+		return "schedule"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrModifierGroupNotFound     = errors.New("modifier group not found")
+	ErrInvalidModifierGroup      = errors.New("invalid modifier group")
+	ErrInvalidModifierSelection  = errors.New("invalid modifier selection")
+	ErrModifierProductNotFound   = errors.New("product does not exist")
+	ErrModifierGroupWrongProduct = errors.New("modifier group does not belong to the specified product")
+)
+
+type ModifierService struct {
+	Repo      *repository.ModifierRepository
+	MenuCache *MenuCache
+}
+
+// GetModifierGroupsByProductID lists the modifier groups of a product
+func (s *ModifierService) GetModifierGroupsByProductID(productID uuid.UUID) ([]domain.ModifierGroup, error) {
+	return s.Repo.ListModifierGroupsByProductID(productID)
+}
+
+// CreateProductModifierGroup adds a modifier group to a product
+func (s *ModifierService) CreateProductModifierGroup(productID uuid.UUID, group *domain.ModifierGroup) (*domain.ModifierGroup, error) {
+	exists, err := s.Repo.ProductExists(productID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking product: %w", err)
+	}
+	if !exists {
+		return nil, ErrModifierProductNotFound
+	}
+
+	group.ID = uuid.New()
+	group.ProductID = productID
+	if err := validateModifierGroup(group); err != nil {
+		return nil, err
+	}
+
+	// GORM skips zero values on create and reads the column default back into the
+	// group, so remember the flag and persist an explicit unavailable one separately
+	isAvailable := group.IsAvailable
+	if err := s.Repo.CreateModifierGroup(group); err != nil {
+		return nil, err
+	}
+	if !isAvailable {
+		group.IsAvailable = false
+		if err := s.Repo.DB.Model(group).Update("is_available", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	s.MenuCache.Invalidate()
+	return group, nil
+}
+
+// UpdateProductModifierGroup applies a partial update to a modifier group of a product
+func (s *ModifierService) UpdateProductModifierGroup(productID, id uuid.UUID, update *dto.UpdateModifierGroupRequest) (*domain.ModifierGroup, error) {
+	group, err := s.getProductModifierGroup(productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		group.Name = *update.Name
+	}
+	if update.MinSelect != nil {
+		group.MinSelect = *update.MinSelect
+	}
+	if update.MaxSelect != nil {
+		group.MaxSelect = *update.MaxSelect
+	}
+	if update.IsAvailable != nil {
+		group.IsAvailable = *update.IsAvailable
+	}
+	if update.Position != nil {
+		group.Position = *update.Position
+	}
+	if update.Options != nil {
+		group.Options = dto.ToModifierOptionsDomain(update.Options)
+	}
+
+	if err := validateModifierGroup(group); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.UpdateModifierGroup(group); err != nil {
+		return nil, err
+	}
+
+	s.MenuCache.Invalidate()
+	return group, nil
+}
+
+// DeleteProductModifierGroup removes a modifier group from a product
+func (s *ModifierService) DeleteProductModifierGroup(productID, id uuid.UUID) error {
+	if _, err := s.getProductModifierGroup(productID, id); err != nil {
+		return err
+	}
+
+	if err := s.Repo.DeleteModifierGroup(id); err != nil {
+		return err
+	}
+
+	s.MenuCache.Invalidate()
+	return nil
+}
+
+// Helper Function
+
+func (s *ModifierService) getProductModifierGroup(productID, id uuid.UUID) (*domain.ModifierGroup, error) {
+	group, err := s.Repo.GetModifierGroupByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrModifierGroupNotFound
+		}
+		return nil, err
+	}
+	if group.ProductID != productID {
+		return nil, ErrModifierGroupWrongProduct
+	}
+	return group, nil
+}
+
+// validateModifierGroup checks the name, options and selection limits of a group
+func validateModifierGroup(group *domain.ModifierGroup) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidModifierGroup)
+	}
+	if len(group.Options) == 0 {
+		return fmt.Errorf("%w: options must contain at least one item", ErrInvalidModifierGroup)
+	}
+
+	labels := make(map[string]bool, len(group.Options))
+	for i, opt := range group.Options {
+		label := strings.ToLower(strings.TrimSpace(opt.Label))
+		if label == "" {
+			return fmt.Errorf("%w: options[%d] label cannot be empty", ErrInvalidModifierGroup, i)
+		}
+		if labels[label] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidModifierGroup, opt.Label)
+		}
+		labels[label] = true
+		if opt.Price < 0 {
+			return fmt.Errorf("%w: options[%d] price cannot be negative", ErrInvalidModifierGroup, i)
+		}
+	}
+
+	if group.MinSelect < 0 || group.MaxSelect < 0 {
+		return fmt.Errorf("%w: min_select and max_select cannot be negative", ErrInvalidModifierGroup)
+	}
+	if group.MaxSelect > 0 && group.MaxSelect < group.MinSelect {
+		return fmt.Errorf("%w: max_select cannot be less than min_select", ErrInvalidModifierGroup)
+	}
+	if group.MinSelect > len(group.Options) {
+		return fmt.Errorf("%w: min_select cannot exceed the number of options", ErrInvalidModifierGroup)
+	}
+
+	return nil
+}
+
+// applyModifiers validates the modifiers picked for an order line against the
+// product's modifier groups and fills in group names and prices.
+// On input, detail.Modifiers only carries GroupID and Label for each pick.
+func applyModifiers(detail *domain.OrderDetail) error {
+	product := detail.Product
+
+	groups := make(map[string]*domain.ModifierGroup, len(product.ModifierGroups))
+	for i := range product.ModifierGroups {
+		groups[product.ModifierGroups[i].ID.String()] = &product.ModifierGroups[i]
+	}
+
+	counts := make(map[string]int, len(groups))
+	seen := make(map[string]bool, len(detail.Modifiers))
+	selected := make(db.SelectedModifiers, 0, len(detail.Modifiers))
+
+	for _, pick := range detail.Modifiers {
+		group, ok := groups[pick.GroupID]
+		if !ok {
+			return fmt.Errorf("%w: unknown modifier group for %s", ErrInvalidModifierSelection, product.Name)
+		}
+		if !group.IsAvailable {
+			return fmt.Errorf("%w: %s is currently unavailable", ErrInvalidModifierSelection, group.Name)
+		}
+
+		option := findModifierOption(group.Options, pick.Label)
+		if option == nil {
+			return fmt.Errorf("%w: %q is not an option of %s", ErrInvalidModifierSelection, pick.Label, group.Name)
+		}
+
+		key := pick.GroupID + "\x00" + strings.ToLower(option.Label)
+		if seen[key] {
+			return fmt.Errorf("%w: %q selected more than once", ErrInvalidModifierSelection, option.Label)
+		}
+		seen[key] = true
+		counts[pick.GroupID]++
+
+		selected = append(selected, db.SelectedModifier{
+			GroupID:   pick.GroupID,
+			GroupName: group.Name,
+			Label:     option.Label,
+			Price:     option.Price,
+		})
+	}
+
+	for id, group := range groups {
+		if !group.IsAvailable {
+			continue
+		}
+		count := counts[id]
+		if count < group.MinSelect {
+			return fmt.Errorf("%w: select at least %d from %s", ErrInvalidModifierSelection, group.MinSelect, group.Name)
+		}
+		if group.MaxSelect > 0 && count > group.MaxSelect {
+			return fmt.Errorf("%w: select at most %d from %s", ErrInvalidModifierSelection, group.MaxSelect, group.Name)
+		}
+	}
+
+	if len(selected) == 0 {
+		detail.Modifiers = nil
+		return nil
+	}
+	detail.Modifiers = selected
+	return nil
+}
+
+func findModifierOption(options db.ModifierOptions, label string) *db.ModifierOption {
+	for i := range options {
+		if strings.EqualFold(strings.TrimSpace(options[i].Label), strings.TrimSpace(label)) {
+			return &options[i]
+		}
+	}
+	return nil
+}
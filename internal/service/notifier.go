@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/pkg/core/logging"
+	"github.com/latoulicious/siresto-backend/pkg/logutil"
+)
+
+// Notifier delivers account messages to users, for example by email
+type Notifier interface {
+	SendPasswordReset(user *domain.User, resetLink string, expiresAt time.Time) error
+}
+
+// ConsoleNotifier prints messages on the server console instead of delivering them.
+// It is meant for development and for deployments without a mail provider, where an
+// administrator passes the link on by hand. Reset links carry a live token, so they
+// are never written to the application log, which is stored and searchable; the log
+// only records that a link was issued.
+type ConsoleNotifier struct {
+	Logger logging.Logger
+	Out    io.Writer // Defaults to os.Stdout
+}
+
+func (n *ConsoleNotifier) SendPasswordReset(user *domain.User, resetLink string, expiresAt time.Time) error {
+	out := n.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if _, err := fmt.Fprintf(out, "Password reset link for %s, valid until %s: %s\n",
+		user.Email, expiresAt.Format(time.RFC3339), resetLink); err != nil {
+		return err
+	}
+
+	n.Logger.LogInfo("Password reset link issued", logutil.ServiceCall("notify.password_reset", "user", map[string]interface{}{
+		"user_id":    user.ID.String(),
+		"expires_at": expiresAt.Format(time.RFC3339),
+	}))
+	return nil
+}
@@ -14,6 +14,7 @@ type OrderService struct {
 	Repo          *repository.OrderRepository
 	ProductRepo   *repository.ProductRepository
 	VariationRepo *repository.VariationRepository
+	Location      *time.Location // Restaurant timezone for availability schedules
 }
 
 func (s *OrderService) ListAllOrders() ([]domain.Order, error) {
@@ -31,6 +32,11 @@ func (s *OrderService) CreateOrder(order *domain.Order, details []domain.OrderDe
 		return nil, err
 	}
 
+	// Reject items that are scheduled out right now
+	if err := s.checkDetailsOrderable(details); err != nil {
+		return nil, err
+	}
+
 	// Calculate prices for each order detail
 	totalAmount := 0.0
 	for i := range details {
@@ -91,6 +97,30 @@ func (s *OrderService) loadProductData(details *[]domain.OrderDetail) error {
 				}
 			}
 		}
+
+		// Expand bundles into their components
+		if err := expandBundle(detail); err != nil {
+			return err
+		}
+
+		// Validate and price the chosen add-ons
+		if err := applyModifiers(detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDetailsOrderable ensures every loaded product is within its availability schedule
+func (s *OrderService) checkDetailsOrderable(details []domain.OrderDetail) error {
+	now := restaurantNow(s.Location)
+	for _, detail := range details {
+		if detail.Product == nil {
+			continue
+		}
+		if err := checkProductSchedule(detail.Product, now); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -117,6 +147,16 @@ func calculateUnitPrice(detail *domain.OrderDetail) float64 {
 		}
 	}
 
+	// Add surcharges for premium bundle choices
+	for _, component := range detail.Components {
+		price += component.PriceModifier
+	}
+
+	// Add modifier prices
+	for _, modifier := range detail.Modifiers {
+		price += modifier.Price
+	}
+
 	return price
 }
 
@@ -277,6 +317,11 @@ func (s *OrderService) UpdateOrder(orderID uuid.UUID, orderUpdate *domain.Order,
 			return nil, fmt.Errorf("failed to load product data: %w", err)
 		}
 
+		if err := s.checkDetailsOrderable(newDetails); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Calculate prices for new details
 		for i := range newDetails {
 			newDetails[i].OrderID = orderID
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/validator"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrPasswordUnchanged = errors.New("new password must differ from the current password")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	ErrWeakPassword      = validator.ErrWeakPassword // Wrapped with the rule the password broke
+)
+
+// PasswordService lets users change their password and administrators send reset links.
+// Setting a new password either way logs the user out everywhere.
+type PasswordService struct {
+	Users    *repository.UserRepository
+	Resets   *repository.PasswordResetRepository
+	Sessions *SessionService
+	Throttle *LoginThrottle
+	Notifier Notifier
+	ResetURL string        // Page the reset token is appended to as ?token=
+	ResetTTL time.Duration // How long a reset link stays valid
+}
+
+// ChangePassword sets a new password for a user who knows the current one
+func (s *PasswordService) ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest) error {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if !crypto.CheckPassword(req.CurrentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return ErrPasswordUnchanged
+	}
+
+	return s.setPassword(user, req.NewPassword)
+}
+
+// RequestPasswordReset issues a single-use reset token for the user and sends them
+// the link. Links issued earlier stop working. Only users whose role ranks below
+// callerPosition can be sent a link.
+func (s *PasswordService) RequestPasswordReset(callerPosition int, userID uuid.UUID) (*dto.PasswordResetResponse, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := checkRank(callerPosition, user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.Resets.InvalidateUserPasswordResetTokens(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	expiresAt := now.Add(s.ResetTTL)
+	if err := s.Resets.CreatePasswordResetToken(&domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: crypto.HashToken(token),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	if err := s.Notifier.SendPasswordReset(user, s.resetLink(token), expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to send password reset link: %w", err)
+	}
+
+	return &dto.PasswordResetResponse{ExpiresAt: expiresAt}, nil
+}
+
+// GetCallerPosition returns the position of the caller's role, to pass to RequestPasswordReset
+func (s *PasswordService) GetCallerPosition(callerID uuid.UUID) (int, error) {
+	return callerPosition(s.Users, callerID)
+}
+
+// ResetTokenUserID returns the user a reset token was issued to, whether or not it
+// can still be used
+func (s *PasswordService) ResetTokenUserID(token string) (uuid.UUID, error) {
+	stored, err := s.Resets.FindPasswordResetTokenByHash(crypto.HashToken(token))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return stored.UserID, nil
+}
+
+// ResetPassword sets a new password with a reset token and uses the token up
+func (s *PasswordService) ResetPassword(req *dto.ResetPasswordRequest) error {
+	stored, err := s.Resets.FindPasswordResetTokenByHash(crypto.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil || now.After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.Users.GetUserByID(stored.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	// Check the policy first, so a rejected password does not use up the link
+	if err := validator.ValidatePassword(req.NewPassword, user.Email); err != nil {
+		return err
+	}
+
+	consumed, err := s.Resets.ConsumePasswordResetToken(stored.ID, now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// The user proved control of the account, so lift its password and PIN lockouts
+	return s.Throttle.Unlock(user.Email, pinAccount(user.ID))
+}
+
+// setPassword stores a new password that passes the policy and ends every session
+func (s *PasswordService) setPassword(user *domain.User, password string) error {
+	if err := validator.ValidatePassword(password, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.Users.SetUserPassword(user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.Resets.InvalidateUserPasswordResetTokens(user.ID, time.Now()); err != nil {
+		return err
+	}
+	if s.Sessions != nil {
+		return s.Sessions.RevokeUserSessions(user.ID)
+	}
+	return nil
+}
+
+func (s *PasswordService) resetLink(token string) string {
+	if s.ResetURL == "" {
+		return token
+	}
+	link, err := url.Parse(s.ResetURL)
+	if err != nil {
+		return s.ResetURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PermissionResolver looks up the current permissions of a role for the RBAC
+// middleware. Results are cached per role and dropped whenever roles or their
+// permissions change; TTL bounds how stale an entry can get when the change
+// happened in another server instance.
+type PermissionResolver struct {
+	Repo *repository.RoleRepository
+	TTL  time.Duration
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedPermissions
+}
+
+type cachedPermissions struct {
+	permissions []string
+	loadedAt    time.Time
+}
+
+// PermissionsForRole returns the permissions stored for a role. Built-in roles are
+// treated like any other; their defaults are seeded by migrations.
+func (r *PermissionResolver) PermissionsForRole(roleID uuid.UUID) ([]string, error) {
+	if roleID == uuid.Nil {
+		return []string{}, nil
+	}
+
+	r.mu.RLock()
+	entry, ok := r.cache[roleID]
+	r.mu.RUnlock()
+	if ok && (r.TTL <= 0 || time.Since(entry.loadedAt) < r.TTL) {
+		return entry.permissions, nil
+	}
+
+	role, err := r.Repo.GetRoleByID(roleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// A deleted role grants nothing
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, perm := range role.Permissions {
+		permissions = append(permissions, perm.Name)
+	}
+
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[uuid.UUID]cachedPermissions)
+	}
+	r.cache[roleID] = cachedPermissions{permissions: permissions, loadedAt: time.Now()}
+	r.mu.Unlock()
+
+	return permissions, nil
+}
+
+// Invalidate drops the cached permissions of a role
+func (r *PermissionResolver) Invalidate(roleID uuid.UUID) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.cache, roleID)
+	r.mu.Unlock()
+}
+
+// InvalidateAll drops every cached role, e.g. after a permission is renamed or deleted
+func (r *PermissionResolver) InvalidateAll() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.cache = nil
+	r.mu.Unlock()
+}
@@ -10,8 +10,9 @@ import (
 )
 
 type PermissionService struct {
-	Repo *repository.PermissionRepository
-	DB   *gorm.DB
+	Repo        *repository.PermissionRepository
+	DB          *gorm.DB
+	Permissions *PermissionResolver
 }
 
 // ListAllPermissions now supports pagination
@@ -33,11 +34,20 @@ func (s *PermissionService) CreatePermission(permission *domain.Permission) erro
 }
 
 func (s *PermissionService) UpdatePermission(permission *domain.Permission) error {
-	return s.Repo.UpdatePermission(permission)
+	if err := s.Repo.UpdatePermission(permission); err != nil {
+		return err
+	}
+	// Any role may grant the permission under its old name
+	s.Permissions.InvalidateAll()
+	return nil
 }
 
 func (s *PermissionService) DeletePermission(id uuid.UUID) error {
-	return s.Repo.DeletePermission(id)
+	if err := s.Repo.DeletePermission(id); err != nil {
+		return err
+	}
+	s.Permissions.InvalidateAll()
+	return nil
 }
 
 func (s *PermissionService) GetPermissionsByIDs(ids []uuid.UUID) ([]domain.Permission, error) {
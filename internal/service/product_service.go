@@ -1,20 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/repository"
 	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
 	"gorm.io/gorm"
 )
 
 type ProductService struct {
-	Repo     *repository.ProductRepository
-	Uploader utils.Uploader
+	Repo      *repository.ProductRepository
+	Uploader  utils.Uploader
+	MenuCache *MenuCache
 }
 
 // ListAllProducts retrieves all products from the repository
@@ -28,6 +32,28 @@ func (s *ProductService) GetProductByID(id uuid.UUID) (*domain.Product, error) {
 	return s.Repo.GetProductByID(id)
 }
 
+// UploadProductImage validates an uploaded image, re-encodes it into every product
+// rendition and stores them under products/<product id>/<rendition>.webp.
+// It returns the public URL of each rendition keyed by name.
+func (s *ProductService) UploadProductImage(productID uuid.UUID, file io.Reader) (db.ImageRenditions, error) {
+	processed, err := utils.ProcessImage(file, utils.DefaultProductImageLimits, utils.ProductImageRenditions)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(db.ImageRenditions, len(processed))
+	for _, rendition := range processed {
+		key := productImageKey(productID, rendition.Name, rendition.Extension)
+		url, err := s.Uploader.Upload(bytes.NewReader(rendition.Data), key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %s image: %w", rendition.Name, err)
+		}
+		images[rendition.Name] = url
+	}
+
+	return images, nil
+}
+
 // CreateProduct creates a new product in the repository
 func (s ProductService) CreateProduct(product *domain.Product) (*domain.Product, error) {
 	// Set the product ID if it's not set
@@ -51,6 +77,7 @@ func (s ProductService) CreateProduct(product *domain.Product) (*domain.Product,
 		return nil, fmt.Errorf("failed to reload product after creation: %w", err)
 	}
 
+	s.MenuCache.Invalidate()
 	return created, nil
 }
 
@@ -58,12 +85,21 @@ func (s *ProductService) CreateProductWithVariations(product *domain.Product, va
 	// Start a database transaction to ensure atomicity
 	tx := s.Repo.DB.Begin()
 
-	// Create the product
-	if err := tx.Create(&product).Error; err != nil {
+	if product.ID == uuid.Nil {
+		product.ID = uuid.New()
+	}
+
+	// Create the product, bundle contents are stored separately below
+	if err := tx.Omit("BundleItems").Create(&product).Error; err != nil {
 		tx.Rollback()
 		return nil, nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
+	if err := syncBundleItems(tx, product.ID, product.IsBundle, product.BundleItems); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
 	// Prepare a slice to hold the created variations (pointer slice)
 	var createdVariations []*domain.Variation
 
@@ -88,10 +124,34 @@ func (s *ProductService) CreateProductWithVariations(product *domain.Product, va
 		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.MenuCache.Invalidate()
+	s.reloadBundleItems(product)
+
 	// Return the created product and its variations
 	return product, createdVariations, nil
 }
 
+// AttachProductImage points a product at renditions that are already in storage and
+// removes the images they replace
+func (s *ProductService) AttachProductImage(id uuid.UUID, images db.ImageRenditions) (*domain.Product, error) {
+	product, err := s.Repo.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousImages := productImageURLs(product)
+	product.ImageURL = images["full"]
+	product.Images = images
+
+	if err := s.Repo.UpdateProduct(product); err != nil {
+		return nil, err
+	}
+
+	s.deleteReplacedImages(previousImages, productImageURLs(product))
+	s.MenuCache.Invalidate()
+	return product, nil
+}
+
 // UpdateProduct updates an existing product in the repository
 func (s *ProductService) UpdateProduct(id uuid.UUID, update *domain.Product) (*domain.Product, error) {
 	// Fetch the existing product
@@ -108,16 +168,24 @@ func (s *ProductService) UpdateProduct(id uuid.UUID, update *domain.Product) (*d
 	// Update the product fields
 	existing.Name = update.Name
 	existing.Description = update.Description
+	previousImages := productImageURLs(existing)
+	if existing.ImageURL != update.ImageURL {
+		existing.Images = nil // Renditions belong to the previous image
+	}
 	existing.ImageURL = update.ImageURL
 	existing.BasePrice = update.BasePrice
 	existing.IsAvailable = update.IsAvailable
 	existing.Position = update.Position
+	existing.Schedule = update.Schedule
 
 	// Save the updated product
 	if err := s.Repo.UpdateProduct(existing); err != nil {
 		return nil, err
 	}
 
+	s.deleteReplacedImages(previousImages, productImageURLs(existing))
+
+	s.MenuCache.Invalidate()
 	return existing, nil
 }
 
@@ -138,10 +206,16 @@ func (s *ProductService) UpdateProductWithVariations(id uuid.UUID, product *doma
 	// Update product fields
 	existingProduct.Name = product.Name
 	existingProduct.Description = product.Description
+	previousImages := productImageURLs(existingProduct)
+	if existingProduct.ImageURL != product.ImageURL {
+		existingProduct.Images = nil // Renditions belong to the previous image
+	}
 	existingProduct.ImageURL = product.ImageURL
 	existingProduct.BasePrice = product.BasePrice
 	existingProduct.IsAvailable = product.IsAvailable
 	existingProduct.Position = product.Position
+	existingProduct.Schedule = product.Schedule
+	existingProduct.IsBundle = product.IsBundle
 	if product.CategoryID != nil {
 		existingProduct.CategoryID = product.CategoryID
 	}
@@ -152,6 +226,11 @@ func (s *ProductService) UpdateProductWithVariations(id uuid.UUID, product *doma
 		return nil, nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
+	if err := syncBundleItems(tx, existingProduct.ID, product.IsBundle, product.BundleItems); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
 	// Handle variations only if they're provided
 	var updatedVariations []*domain.Variation
 
@@ -249,6 +328,11 @@ func (s *ProductService) UpdateProductWithVariations(id uuid.UUID, product *doma
 		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.deleteReplacedImages(previousImages, productImageURLs(existingProduct))
+
+	s.MenuCache.Invalidate()
+	s.reloadBundleItems(existingProduct)
+
 	// Return updated entities
 	return existingProduct, updatedVariations, nil
 }
@@ -260,17 +344,125 @@ func (s *ProductService) DeleteProduct(id uuid.UUID) error {
 		return fmt.Errorf("product cannot be deleted: %w", err)
 	}
 
-	// Proceed with deletion
-	return s.Repo.DeleteProduct(id)
+	if _, err := s.Repo.GetProductByID(id); err != nil {
+		return err
+	}
+
+	// Proceed with deletion; the images are kept so the product can be restored
+	if err := s.Repo.DeleteProduct(id); err != nil {
+		return err
+	}
+
+	s.MenuCache.Invalidate()
+	return nil
+}
+
+// ListDeletedProducts lists deleted products that can be restored
+func (s *ProductService) ListDeletedProducts() ([]dto.DeletedItemResponse, error) {
+	products, err := s.Repo.ListDeletedProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(products))
+	for _, product := range products {
+		items = append(items, mapDeletedItem(product.ID, product.Name, "", product.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreProduct restores a deleted product, provided its category still exists
+func (s *ProductService) RestoreProduct(id uuid.UUID) (*domain.Product, error) {
+	product, err := s.Repo.GetDeletedProduct(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+
+	if product.CategoryID != nil {
+		exists, err := repository.CategoryExists(s.Repo.DB, *product.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: the product's category was deleted, restore it first", ErrRestoreConflict)
+		}
+	}
+
+	if err := s.Repo.RestoreProduct(id); err != nil {
+		return nil, notDeleted(err)
+	}
+
+	s.MenuCache.Invalidate()
+	return s.Repo.GetProductByID(id)
 }
 
 // Helper Function
 
+// productImageKey is the storage key of one rendition of a product image
+func productImageKey(productID uuid.UUID, rendition, ext string) string {
+	return fmt.Sprintf("%s%s/%s%s", ProductImagePrefix, productID, rendition, ext)
+}
+
+// productImageURLs lists every image URL stored on a product, including renditions
+func productImageURLs(p *domain.Product) []string {
+	var urls []string
+	if p.ImageURL != "" {
+		urls = append(urls, p.ImageURL)
+	}
+	for _, url := range p.Images {
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// deleteReplacedImages removes the stored objects behind previous that are not
+// part of current. It runs after the database change is committed, so storage
+// errors are ignored rather than failing a change that already happened; anything
+// left behind is picked up by the image garbage collector.
+func (s *ProductService) deleteReplacedImages(previous, current []string) {
+	if s.Uploader == nil {
+		return
+	}
+
+	inUse := make(map[string]bool, len(current))
+	for _, url := range current {
+		if key, ok := s.Uploader.KeyFromURL(url); ok {
+			inUse[key] = true
+		}
+	}
+
+	for _, url := range previous {
+		key, ok := s.Uploader.KeyFromURL(url)
+		if !ok || inUse[key] {
+			continue
+		}
+		inUse[key] = true // Delete each object once
+
+		// The same URL can be shared by other products, e.g. after a menu import
+		if shared, err := s.Repo.ImageURLInUse(url); err != nil || shared {
+			continue
+		}
+		_ = s.Uploader.Delete(key)
+	}
+}
+
+// reloadBundleItems refreshes the bundle contents of p, including product names, for the response
+func (s *ProductService) reloadBundleItems(p *domain.Product) {
+	if !p.IsBundle {
+		p.BundleItems = nil
+		return
+	}
+	if reloaded, err := s.Repo.GetProductByID(p.ID); err == nil {
+		p.BundleItems = reloaded.BundleItems
+	}
+}
+
 var (
 	ErrMissingCategoryID = errors.New("categoryID is required")
 	ErrInvalidBasePrice  = errors.New("basePrice must be greater than 0")
 	ErrEmptyName         = errors.New("product name cannot be empty")
 	ErrCategoryNotFound  = errors.New("category does not exist")
+	ErrInvalidSchedule   = errors.New("invalid availability schedule")
 )
 
 // ValidateProduct performs all validation on a product for creation or update
@@ -284,6 +476,9 @@ func ValidateProduct(db *gorm.DB, p *domain.Product) error {
 	if p.BasePrice <= 0 {
 		return ErrInvalidBasePrice
 	}
+	if err := p.Schedule.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
 	exists, err := repository.CategoryExists(db, *p.CategoryID)
 	if err != nil {
 		return fmt.Errorf("error checking category: %w", err)
@@ -291,7 +486,7 @@ func ValidateProduct(db *gorm.DB, p *domain.Product) error {
 	if !exists {
 		return ErrCategoryNotFound
 	}
-	return nil
+	return validateBundle(db, p)
 }
 
 // ValidateProductForUpdate performs validation on a product for update
@@ -302,6 +497,9 @@ func ValidateProductForUpdate(db *gorm.DB, p *domain.Product) error {
 	if p.BasePrice <= 0 {
 		return ErrInvalidBasePrice
 	}
+	if err := p.Schedule.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
 	// only check category if it's explicitly allowed to change
 	if p.CategoryID != nil && *p.CategoryID != uuid.Nil {
 		exists, err := repository.CategoryExists(db, *p.CategoryID)
@@ -312,7 +510,7 @@ func ValidateProductForUpdate(db *gorm.DB, p *domain.Product) error {
 			return ErrCategoryNotFound
 		}
 	}
-	return nil
+	return validateBundle(db, p)
 }
 
 // ValidateProductDeletable ensures the product can be safely deleted
@@ -328,5 +526,17 @@ func ValidateProductDeletable(db *gorm.DB, productID uuid.UUID) error {
 		return fmt.Errorf("cannot delete product: %d associated variations found", count)
 	}
 
+	var bundleCount int64
+	if err := db.Model(&domain.BundleItem{}).
+		Where("product_id = ? OR id IN (?)", productID,
+			db.Model(&domain.BundleChoice{}).Select("bundle_item_id").Where("product_id = ?", productID)).
+		Count(&bundleCount).Error; err != nil {
+		return fmt.Errorf("error checking related bundles: %w", err)
+	}
+
+	if bundleCount > 0 {
+		return fmt.Errorf("cannot delete product: it is part of %d bundle items", bundleCount)
+	}
+
 	return nil
 }
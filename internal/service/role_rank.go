@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+)
+
+// ErrInsufficientRank is returned when a caller manages a user whose role is as
+// privileged as their own, or more
+var ErrInsufficientRank = errors.New("insufficient privileges to manage a user whose role is at or above your own")
+
+// callerPosition returns the position of the caller's current role. As with roles,
+// lower positions are more privileged; a caller without a role ranks below everyone.
+func callerPosition(users *repository.UserRepository, callerID uuid.UUID) (int, error) {
+	caller, err := users.GetUserByID(callerID)
+	if err != nil {
+		return 0, ErrUserNotFound
+	}
+	if caller.Role == nil {
+		return math.MaxInt, nil
+	}
+	return caller.Role.Position, nil
+}
+
+// checkRank refuses targets whose role position is at or above callerPosition, the
+// same rule RoleService applies to roles
+func checkRank(callerPosition int, target *domain.User) error {
+	if target.Role != nil && target.Role.Position <= callerPosition {
+		return ErrInsufficientRank
+	}
+	return nil
+}
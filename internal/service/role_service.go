@@ -14,6 +14,7 @@ type RoleService struct {
 	Repo              *repository.RoleRepository
 	PermissionService *PermissionService
 	DB                *gorm.DB
+	Permissions       *PermissionResolver
 }
 
 func (s *RoleService) ListAllRoles() ([]dto.RoleResponse, error) {
@@ -176,6 +177,7 @@ func (s *RoleService) UpdateRole(id uuid.UUID, req *dto.UpdateRoleRequest) (*dto
 	if err != nil {
 		return nil, err
 	}
+	s.Permissions.Invalidate(id)
 
 	// Get the updated role with all associations loaded
 	updatedRole, err := s.Repo.GetRoleByID(id)
@@ -239,6 +241,7 @@ func (s *RoleService) AddPermissionsToRole(roleID uuid.UUID, permissionIDs []uui
 	if err != nil {
 		return nil, err
 	}
+	s.Permissions.Invalidate(roleID)
 
 	// Get the updated role with preloaded permissions
 	updatedRole, err := s.Repo.GetRoleByID(roleID)
@@ -283,6 +286,7 @@ func (s *RoleService) RemovePermissionsFromRole(roleID uuid.UUID, permissionIDs
 	if err := s.Repo.UpdateRole(existingRole); err != nil {
 		return nil, err
 	}
+	s.Permissions.Invalidate(roleID)
 
 	// Get updated role
 	updatedRole, err := s.Repo.GetRoleByID(roleID)
@@ -317,7 +321,62 @@ func (s *RoleService) DeleteRole(id uuid.UUID) error {
 		return fmt.Errorf("system roles cannot be deleted")
 	}
 
-	return s.Repo.DeleteRole(id)
+	// Users would be left without permissions
+	users, err := s.Repo.CountUsersWithRole(id)
+	if err != nil {
+		return fmt.Errorf("failed to count users with role: %w", err)
+	}
+	if users > 0 {
+		return fmt.Errorf("role is assigned to %d users, reassign them first", users)
+	}
+
+	if err := s.Repo.DeleteRole(id); err != nil {
+		return err
+	}
+	s.Permissions.Invalidate(id)
+	return nil
+}
+
+// ListDeletedRoles lists deleted roles that can be restored
+func (s *RoleService) ListDeletedRoles() ([]dto.DeletedItemResponse, error) {
+	roles, err := s.Repo.ListDeletedRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(roles))
+	for _, role := range roles {
+		items = append(items, mapDeletedItem(role.ID, role.Name, role.Description, role.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreRole restores a deleted role with the permissions it had
+func (s *RoleService) RestoreRole(id uuid.UUID) (*dto.RoleResponse, error) {
+	role, err := s.Repo.GetDeletedRole(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+
+	// Restoring is held to the same privilege rule as deleting
+	callerPosition, err := s.GetCallerPosition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate permissions: %w", err)
+	}
+	if role.Position <= callerPosition {
+		return nil, fmt.Errorf("insufficient privileges to restore this role")
+	}
+
+	if _, err := s.Repo.GetRoleByName(role.Name); err == nil {
+		return nil, fmt.Errorf("%w: another role is named %q", ErrRestoreConflict, role.Name)
+	}
+
+	if err := s.Repo.RestoreRole(id); err != nil {
+		return nil, notDeleted(err)
+	}
+	s.Permissions.Invalidate(id)
+
+	return s.GetRoleByID(id)
 }
 
 // Helper function to map domain role to response DTO
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// SessionService manages refresh tokens and access token revocation.
+// Refresh tokens rotate on every use; presenting one that was already used revokes
+// the whole login, since it means the token was copied.
+type SessionService struct {
+	Repo      *repository.SessionRepository
+	UserRepo  *repository.UserRepository
+	Terminals *repository.TerminalRepository
+}
+
+// CreateRefreshToken issues a refresh token for the user in the given login family
+func (s *SessionService) CreateRefreshToken(userID, familyID uuid.UUID) (string, error) {
+	return createRefreshToken(s.Repo, userID, familyID)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token
+func (s *SessionService) Refresh(refreshToken string) (*dto.UserLoginResponse, error) {
+	stored, err := s.Repo.FindRefreshTokenByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		// A used token came back: assume it leaked and end the whole login
+		if err := s.Repo.RevokeRefreshTokenFamily(stored.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.UserRepo.GetUserByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var nextToken string
+	err = s.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		repo := &repository.SessionRepository{DB: tx}
+		consumed, err := repo.ConsumeRefreshToken(stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return ErrInvalidRefreshToken
+		}
+
+		nextToken, err = createRefreshToken(repo, user.ID, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Role and permissions are read again, so changes apply from the next refresh
+	accessToken, err := generateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UserLoginResponse{
+		User:         mapToUserResponse(user),
+		Token:        accessToken,
+		RefreshToken: nextToken,
+		ExpiresIn:    int64(jwt.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// Logout ends the login a refresh token belongs to and revokes the access token.
+// Either token may be empty; unknown or expired tokens are ignored.
+func (s *SessionService) Logout(accessToken, refreshToken string) error {
+	now := time.Now()
+
+	if refreshToken != "" {
+		stored, err := s.Repo.FindRefreshTokenByHash(hashRefreshToken(refreshToken))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if stored != nil {
+			if err := s.Repo.RevokeRefreshTokenFamily(stored.FamilyID, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if accessToken != "" {
+		if claims, err := jwt.ValidateToken(accessToken); err == nil && claims.ID != "" && claims.ExpiresAt != nil {
+			if err := s.Repo.RevokeAccessToken(&domain.RevokedToken{
+				JTI:       claims.ID,
+				UserID:    claims.UserID,
+				ExpiresAt: claims.ExpiresAt.Time,
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Entries for expired tokens are no longer needed
+	return s.Repo.DeleteExpiredRevokedTokens(now)
+}
+
+// RevokeUserSessions logs a user out everywhere: refresh tokens are revoked and
+// access tokens issued so far are rejected
+func (s *SessionService) RevokeUserSessions(userID uuid.UUID) error {
+	now := time.Now()
+	if err := s.Repo.RevokeUserRefreshTokens(userID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// Issue times have microsecond precision, like the stored cutoff
+	cutoff := now.Truncate(time.Microsecond)
+	if err := s.Repo.SetUserSessionsRevokedAt(userID, cutoff); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a valid access token was revoked, either on its own
+// at logout or together with all of its user's sessions
+func (s *SessionService) IsRevoked(claims *jwt.Claims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := s.Repo.IsAccessTokenRevoked(claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	// Tokens from a PIN login die with their terminal
+	if claims.TerminalID != uuid.Nil && s.Terminals != nil {
+		revoked, err := s.Terminals.IsTerminalRevoked(claims.TerminalID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	cutoff, err := s.Repo.GetUserSessionsRevokedAt(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil // The user was deleted
+		}
+		return false, err
+	}
+	if cutoff != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*cutoff)) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// Helper Function
+
+func createRefreshToken(repo *repository.SessionRepository, userID, familyID uuid.UUID) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := repo.CreateRefreshToken(&domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(token),
+		ExpiresAt: time.Now().Add(jwt.RefreshTokenTTL()),
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// hashRefreshToken returns the value stored for a refresh token. The tokens are
+// random, so a plain SHA-256 is enough and keeps lookups indexable.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
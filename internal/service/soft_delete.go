@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotDeleted      = errors.New("no deleted record with this ID")
+	ErrRestoreConflict = errors.New("cannot restore")
+)
+
+// notDeleted maps a failed lookup of a deleted record to ErrNotDeleted
+func notDeleted(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotDeleted
+	}
+	return err
+}
+
+func mapDeletedItem(id uuid.UUID, name, detail string, deletedAt gorm.DeletedAt) dto.DeletedItemResponse {
+	return dto.DeletedItemResponse{
+		ID:        id,
+		Name:      name,
+		Detail:    detail,
+		DeletedAt: deletedAt.Time,
+	}
+}
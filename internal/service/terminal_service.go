@@ -0,0 +1,184 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTerminalNotFound   = errors.New("terminal not found")
+	ErrInvalidTerminal    = errors.New("unknown or revoked terminal")
+	ErrPinLoginNotAllowed = errors.New("PIN login is only available to restaurant staff")
+)
+
+// PinLoginRoles are the restaurant-staff roles that may sign in on terminals with a PIN
+var PinLoginRoles = []string{middleware.RoleCashier, middleware.RoleKitchen, middleware.RoleWaiter}
+
+// TerminalService registers shared staff devices and signs staff in on them with a PIN.
+// A terminal proves itself with a device key sent in the X-Terminal-Key header; the
+// tokens it obtains are bound to that key.
+type TerminalService struct {
+	Repo     *repository.TerminalRepository
+	UserRepo *repository.UserRepository
+	Throttle *LoginThrottle
+}
+
+// RegisterTerminal creates a terminal and returns its device key, which is not stored
+func (s *TerminalService) RegisterTerminal(req *dto.RegisterTerminalRequest) (*dto.TerminalRegistrationResponse, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate terminal key: %w", err)
+	}
+	key := base64.RawURLEncoding.EncodeToString(raw)
+
+	terminal := &domain.Terminal{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		KeyHash:   crypto.HashToken(key),
+		CreatedAt: time.Now(),
+	}
+	if err := s.Repo.CreateTerminal(terminal); err != nil {
+		return nil, err
+	}
+
+	return &dto.TerminalRegistrationResponse{
+		Terminal: mapTerminalToResponse(terminal),
+		Key:      key,
+	}, nil
+}
+
+func (s *TerminalService) ListTerminals() ([]dto.TerminalResponse, error) {
+	terminals, err := s.Repo.ListTerminals()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TerminalResponse, 0, len(terminals))
+	for i := range terminals {
+		responses = append(responses, mapTerminalToResponse(&terminals[i]))
+	}
+	return responses, nil
+}
+
+// RevokeTerminal stops the terminal from signing staff in and invalidates its tokens
+func (s *TerminalService) RevokeTerminal(id uuid.UUID) error {
+	if _, err := s.Repo.GetTerminalByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTerminalNotFound
+		}
+		return err
+	}
+	return s.Repo.RevokeTerminal(id, time.Now())
+}
+
+// ListStaff returns who can sign in with a PIN on the terminal with the given key
+func (s *TerminalService) ListStaff(terminalKey string) ([]dto.TerminalStaffResponse, error) {
+	if _, err := s.authenticate(terminalKey); err != nil {
+		return nil, err
+	}
+
+	users, err := s.UserRepo.ListPinUsers(PinLoginRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	staff := make([]dto.TerminalStaffResponse, 0, len(users))
+	for _, user := range users {
+		staff = append(staff, dto.TerminalStaffResponse{
+			ID:   user.ID,
+			Name: user.Name,
+			Role: user.Role.Name,
+		})
+	}
+	return staff, nil
+}
+
+// PinLogin signs a staff member in on a registered terminal. Since short PINs are easy
+// to guess, failed PINs are locked out under the same policy as password logins, on a
+// counter of their own.
+func (s *TerminalService) PinLogin(terminalKey string, req *dto.PinLoginRequest, ip string) (*dto.UserLoginResponse, error) {
+	terminal, err := s.authenticate(terminalKey)
+	if err != nil {
+		return nil, err
+	}
+
+	account := pinAccount(req.UserID)
+	attempt, err := s.Throttle.Begin(account, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepo.GetUserByID(req.UserID)
+	if err != nil || user.PinHash == nil || !crypto.CheckPassword(req.Pin, *user.PinHash) {
+		attempt.Failed()
+		return nil, ErrInvalidCredentials
+	}
+	attempt.Succeeded()
+
+	if user.Role == nil || !slices.Contains(PinLoginRoles, user.Role.Name) {
+		return nil, ErrPinLoginNotAllowed
+	}
+
+	token, err := jwt.GenerateTerminalToken(user.ID, user.Role.ID, user.Role.Name, terminal.ID, terminal.KeyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.UserRepo.UpdateLastLogin(user.ID, now); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.TouchTerminal(terminal.ID, now); err != nil {
+		return nil, err
+	}
+	user.LastLoginAt = &now
+
+	return &dto.UserLoginResponse{
+		User:      mapToUserResponse(user),
+		Token:     token,
+		ExpiresIn: int64(jwt.TerminalTokenTTL().Seconds()),
+	}, nil
+}
+
+func (s *TerminalService) authenticate(terminalKey string) (*domain.Terminal, error) {
+	if terminalKey == "" {
+		return nil, ErrInvalidTerminal
+	}
+	terminal, err := s.Repo.FindActiveTerminalByKeyHash(crypto.HashToken(terminalKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidTerminal
+		}
+		return nil, err
+	}
+	return terminal, nil
+}
+
+func mapTerminalToResponse(terminal *domain.Terminal) dto.TerminalResponse {
+	return dto.TerminalResponse{
+		ID:         terminal.ID,
+		Name:       terminal.Name,
+		CreatedAt:  terminal.CreatedAt,
+		LastUsedAt: terminal.LastUsedAt,
+		RevokedAt:  terminal.RevokedAt,
+	}
+}
+
+// pinAccount is the throttle account for a user's PIN logins, kept apart from their
+// password logins
+func pinAccount(userID uuid.UUID) string {
+	return "pin:" + userID.String()
+}
@@ -1,9 +1,14 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"gorm.io/gorm"
 )
 
 type ThemeService struct {
@@ -50,3 +55,36 @@ func (s *ThemeService) DeleteTheme(id uuid.UUID) error {
 	}
 	return nil
 }
+
+// ListDeletedThemes lists deleted themes that can be restored
+func (s *ThemeService) ListDeletedThemes() ([]dto.DeletedItemResponse, error) {
+	themes, err := s.Repo.ListDeletedThemes()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(themes))
+	for _, theme := range themes {
+		items = append(items, mapDeletedItem(theme.ID, theme.Name, "", theme.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreTheme restores a deleted theme unless another one took its name
+func (s *ThemeService) RestoreTheme(id uuid.UUID) (*domain.Theme, error) {
+	theme, err := s.Repo.GetDeletedTheme(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+
+	if _, err := s.Repo.GetThemeByName(theme.Name); err == nil {
+		return nil, fmt.Errorf("%w: another theme is named %q", ErrRestoreConflict, theme.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.Repo.RestoreTheme(id); err != nil {
+		return nil, notDeleted(err)
+	}
+	return s.Repo.GetThemeByID(id)
+}
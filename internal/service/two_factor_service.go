@@ -0,0 +1,348 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/latoulicious/siresto-backend/pkg/totp"
+)
+
+var (
+	ErrTwoFactorNotAvailable   = errors.New("two-factor authentication is only available to management roles")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolling   = errors.New("two-factor enrollment has not been started")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorToken   = errors.New("invalid or expired two-factor token")
+)
+
+// Purposes of the challenge tokens handed out between the password and the second factor
+const (
+	TwoFactorPurposeLogin  = "2fa_login"
+	TwoFactorPurposeEnroll = "2fa_enroll"
+)
+
+const recoveryCodeCount = 10
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService manages TOTP two-factor authentication for users whose role is
+// Admin or above. With Enforced set, those users cannot finish a login without it.
+type TwoFactorService struct {
+	Repo     *repository.TwoFactorRepository
+	Users    *repository.UserRepository
+	Roles    *repository.RoleRepository
+	Throttle *LoginThrottle
+	Issuer   string
+	Enforced bool
+}
+
+// Available reports whether the user's role is at or above Admin
+func (s *TwoFactorService) Available(user *domain.User) (bool, error) {
+	if user.Role == nil {
+		return false, nil
+	}
+	admin, err := s.Roles.GetRoleByName(middleware.RoleAdmin)
+	if err != nil {
+		return false, fmt.Errorf("failed to load the %s role: %w", middleware.RoleAdmin, err)
+	}
+	// Lower positions are more privileged
+	return user.Role.Position <= admin.Position, nil
+}
+
+// Required reports whether the user must enroll before logging in
+func (s *TwoFactorService) Required(user *domain.User) (bool, error) {
+	if !s.Enforced {
+		return false, nil
+	}
+	return s.Available(user)
+}
+
+func (s *TwoFactorService) Status(userID uuid.UUID) (*dto.TwoFactorStatusResponse, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	available, err := s.Available(user)
+	if err != nil {
+		return nil, err
+	}
+	status := &dto.TwoFactorStatusResponse{
+		Enabled:   user.TOTPEnabledAt != nil,
+		Available: available,
+		Required:  available && s.Enforced,
+	}
+	if status.Enabled {
+		if status.RecoveryCodesCount, err = s.Repo.CountUnusedRecoveryCodes(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// LoginChallenge returns the response for a login that passed the password check but
+// still needs a second factor, or nil when the login can complete
+func (s *TwoFactorService) LoginChallenge(user *domain.User) (*dto.UserLoginResponse, error) {
+	purpose := TwoFactorPurposeLogin
+	if user.TOTPEnabledAt == nil {
+		required, err := s.Required(user)
+		if err != nil || !required {
+			return nil, err
+		}
+		purpose = TwoFactorPurposeEnroll
+	}
+
+	token, err := jwt.GenerateChallengeToken(user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UserLoginResponse{
+		User:                        mapToUserResponse(user),
+		TwoFactorRequired:           purpose == TwoFactorPurposeLogin,
+		TwoFactorEnrollmentRequired: purpose == TwoFactorPurposeEnroll,
+		TwoFactorToken:              token,
+	}, nil
+}
+
+// ResolveChallenge returns the user a challenge token was issued to
+func (s *TwoFactorService) ResolveChallenge(token, purpose string) (*domain.User, error) {
+	claims, err := jwt.ValidateChallengeToken(token, purpose)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorToken
+	}
+	user, err := s.Users.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorToken
+	}
+	return user, nil
+}
+
+// BeginEnrollment generates a new secret for the user. It is only required at login
+// once ConfirmEnrollment has checked a code from it.
+func (s *TwoFactorService) BeginEnrollment(userID uuid.UUID) (*dto.TwoFactorEnrollmentResponse, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	available, err := s.Available(user)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrTwoFactorNotAvailable
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.SetPendingTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication once the user proves their
+// authenticator produces valid codes, and returns their recovery codes
+func (s *TwoFactorService) ConfirmEnrollment(userID uuid.UUID, code, ip string) ([]string, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorNotEnrolling
+	}
+
+	account := twoFactorAccount(user.ID)
+	attempt, err := s.Throttle.Begin(account, ip)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		attempt.Failed()
+		return nil, ErrInvalidTwoFactorCode
+	}
+	attempt.Succeeded()
+
+	codes, records, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.EnableTOTP(user.ID, time.Now(), step, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyCode checks a TOTP code or an unused recovery code of a user with two-factor
+// authentication enabled. Both are single-use, and failures count towards a lockout.
+func (s *TwoFactorService) VerifyCode(user *domain.User, code, ip string) error {
+	if user.TOTPEnabledAt == nil || user.TOTPSecret == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	account := twoFactorAccount(user.ID)
+	attempt, err := s.Throttle.Begin(account, ip)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.checkCode(user, code)
+	if err != nil {
+		attempt.Cancel()
+		return err
+	}
+	if !ok {
+		attempt.Failed()
+		return ErrInvalidTwoFactorCode
+	}
+	attempt.Succeeded()
+	return nil
+}
+
+func (s *TwoFactorService) checkCode(user *domain.User, code string) (bool, error) {
+	if isTOTPCode(code) {
+		step, ok := totp.Validate(*user.TOTPSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.Repo.AdvanceTOTPStep(user.ID, step)
+	}
+	return s.Repo.ConsumeRecoveryCode(user.ID, hashRecoveryCode(code), time.Now())
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, code, ip string) ([]string, error) {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.VerifyCode(user, code, ip); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.ReplaceRecoveryCodes(user.ID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off for a user who proves both factors.
+// Roles that must use it cannot turn it off.
+func (s *TwoFactorService) Disable(userID uuid.UUID, req *dto.DisableTwoFactorRequest, ip string) error {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	required, err := s.Required(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	if !crypto.CheckPassword(req.Password, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if err := s.VerifyCode(user, req.Code, ip); err != nil {
+		return err
+	}
+	return s.Repo.DisableTOTP(user.ID)
+}
+
+// Reset removes a user's two-factor authentication after they lost their authenticator
+// and recovery codes. With enforcement on they enroll again at their next login. Only
+// users whose role ranks below callerPosition can be reset.
+func (s *TwoFactorService) Reset(callerPosition int, userID uuid.UUID) error {
+	user, err := s.Users.GetUserByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := checkRank(callerPosition, user); err != nil {
+		return err
+	}
+	return s.Repo.DisableTOTP(userID)
+}
+
+// GetCallerPosition returns the position of the caller's role, to pass to Reset
+func (s *TwoFactorService) GetCallerPosition(callerID uuid.UUID) (int, error) {
+	return callerPosition(s.Users, callerID)
+}
+
+func twoFactorAccount(userID uuid.UUID) string {
+	return "2fa:" + userID.String()
+}
+
+// isTOTPCode tells codes from the authenticator app apart from recovery codes
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCodes returns new recovery codes formatted for the user, along
+// with the records that store their hashes
+func generateRecoveryCodes(userID uuid.UUID) ([]string, []domain.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]domain.RecoveryCode, 0, recoveryCodeCount)
+	now := time.Now()
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))[:10]
+		code := encoded[:5] + "-" + encoded[5:]
+
+		codes = append(codes, code)
+		records = append(records, domain.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedAt: now,
+		})
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which users often get wrong when typing
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return crypto.HashToken(normalized)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+)
+
+var (
+	ErrDirectUploadUnsupported = errors.New("file storage does not support direct uploads")
+	ErrInvalidUploadRequest    = errors.New("invalid upload request")
+	ErrUploadTargetNotFound    = errors.New("upload target not found")
+	ErrUploadNotFound          = errors.New("uploaded file not found")
+)
+
+const (
+	UploadTargetProduct = "product"
+	UploadTargetTheme   = "theme"
+
+	presignedUploadExpiry = 15 * time.Minute
+)
+
+// directUploadTypes lists the accepted content types and the extension used for their keys
+var directUploadTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// UploadService lets clients upload images straight to storage through presigned
+// URLs and then attach them to a product or theme
+type UploadService struct {
+	Uploader       utils.Uploader
+	ProductService *ProductService
+	ThemeService   *ThemeService
+}
+
+// PresignUpload reserves a storage key for the target and returns a URL the client
+// can upload the file to directly. The URL only accepts the declared type and size.
+func (s *UploadService) PresignUpload(request *dto.PresignUploadRequest) (*dto.PresignUploadResponse, error) {
+	presigner, ok := s.Uploader.(utils.PresignedUploader)
+	if !ok {
+		return nil, ErrDirectUploadUnsupported
+	}
+
+	prefix, err := s.uploadKeyPrefix(request.Target, request.TargetID, request.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	ext, ok := directUploadTypes[strings.ToLower(request.ContentType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: content_type must be image/jpeg, image/png or image/webp", ErrInvalidUploadRequest)
+	}
+	maxBytes := utils.DefaultProductImageLimits.MaxBytes
+	if request.Size <= 0 || request.Size > maxBytes {
+		return nil, fmt.Errorf("%w: size must be between 1 and %d bytes", ErrInvalidUploadRequest, maxBytes)
+	}
+
+	key := prefix + uuid.New().String() + ext
+	upload, err := presigner.PresignUpload(key, strings.ToLower(request.ContentType), request.Size, presignedUploadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PresignUploadResponse{
+		Key:       key,
+		UploadURL: upload.URL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// ConfirmUpload checks that a presigned upload reached storage and attaches it to its
+// target. Product images are processed into renditions like images posted to the API.
+func (s *UploadService) ConfirmUpload(request *dto.ConfirmUploadRequest) (*dto.ConfirmUploadResponse, error) {
+	if s.Uploader == nil {
+		return nil, ErrDirectUploadUnsupported
+	}
+
+	prefix, err := s.uploadKeyPrefix(request.Target, request.TargetID, request.Field)
+	if err != nil {
+		return nil, err
+	}
+	// Only keys issued for this target can be attached to it
+	name, ok := strings.CutPrefix(request.Key, prefix)
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: key was not issued for this target", ErrInvalidUploadRequest)
+	}
+
+	exists, err := s.Uploader.Exists(request.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check uploaded file: %w", err)
+	}
+	if !exists {
+		return nil, ErrUploadNotFound
+	}
+
+	var url string
+	switch request.Target {
+	case UploadTargetProduct:
+		images, err := s.attachProductUpload(request.TargetID, request.Key)
+		if err != nil {
+			return nil, err
+		}
+		url = images["full"]
+	case UploadTargetTheme:
+		url = s.Uploader.URL(request.Key)
+		if err := s.attachThemeImage(request.TargetID, request.Field, url); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.ConfirmUploadResponse{
+		Target:   request.Target,
+		TargetID: request.TargetID,
+		Field:    request.Field,
+		Key:      request.Key,
+		URL:      url,
+	}, nil
+}
+
+// Helper Function
+
+// uploadKeyPrefix validates the upload target and returns the storage prefix its files live under
+func (s *UploadService) uploadKeyPrefix(target string, targetID uuid.UUID, field string) (string, error) {
+	if targetID == uuid.Nil {
+		return "", fmt.Errorf("%w: target_id is required", ErrInvalidUploadRequest)
+	}
+
+	switch target {
+	case UploadTargetProduct:
+		if field != "" && field != "image" {
+			return "", fmt.Errorf("%w: products only accept the image field", ErrInvalidUploadRequest)
+		}
+		if _, err := s.ProductService.GetProductByID(targetID); err != nil {
+			return "", ErrUploadTargetNotFound
+		}
+		return fmt.Sprintf("%s%s/original-", ProductImagePrefix, targetID), nil
+	case UploadTargetTheme:
+		if field != "logo" && field != "favicon" {
+			return "", fmt.Errorf("%w: field must be logo or favicon for themes", ErrInvalidUploadRequest)
+		}
+		if _, err := s.ThemeService.GetThemeByID(targetID); err != nil {
+			return "", ErrUploadTargetNotFound
+		}
+		return fmt.Sprintf("themes/%s/%s-", targetID, field), nil
+	default:
+		return "", fmt.Errorf("%w: target must be product or theme", ErrInvalidUploadRequest)
+	}
+}
+
+// attachProductUpload validates a directly uploaded product image, stores its renditions
+// and attaches them to the product. The original is removed once it is replaced by the
+// renditions or rejected; after other errors it is kept, so the confirm can be retried.
+func (s *UploadService) attachProductUpload(productID uuid.UUID, key string) (db.ImageRenditions, error) {
+	file, err := s.Uploader.Open(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	images, err := s.ProductService.UploadProductImage(productID, file)
+	file.Close()
+	if err != nil {
+		if isRejectedImage(err) {
+			s.deleteUpload(key)
+		}
+		return nil, err
+	}
+
+	if _, err := s.ProductService.AttachProductImage(productID, images); err != nil {
+		return nil, fmt.Errorf("failed to attach image to product: %w", err)
+	}
+	s.deleteUpload(key)
+	return images, nil
+}
+
+// deleteUpload removes an uploaded original. A failure is ignored, as the image
+// garbage collector removes originals nothing refers to.
+func (s *UploadService) deleteUpload(key string) {
+	_ = s.Uploader.Delete(key)
+}
+
+// isRejectedImage reports whether an image failed validation, as opposed to storage errors
+func isRejectedImage(err error) bool {
+	return errors.Is(err, utils.ErrImageTooLarge) ||
+		errors.Is(err, utils.ErrUnsupportedImage) ||
+		errors.Is(err, utils.ErrInvalidImageSize) ||
+		errors.Is(err, utils.ErrImageDecodingFailed)
+}
+
+// attachThemeImage stores url as the theme's logo or favicon and removes the stored file it replaces
+func (s *UploadService) attachThemeImage(themeID uuid.UUID, field, url string) error {
+	theme, err := s.ThemeService.GetThemeByID(themeID)
+	if err != nil {
+		return ErrUploadTargetNotFound
+	}
+
+	previous := theme.LogoURL
+	if field == "favicon" {
+		previous = theme.FaviconURL
+		theme.FaviconURL = url
+	} else {
+		theme.LogoURL = url
+	}
+
+	if _, err := s.ThemeService.UpdateTheme(theme); err != nil {
+		return fmt.Errorf("failed to attach image to theme: %w", err)
+	}
+
+	// Logos used to be stored inline as Base64, which never maps to a storage key
+	if key, ok := s.Uploader.KeyFromURL(previous); ok && previous != url {
+		_ = s.Uploader.Delete(key)
+	}
+	return nil
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
-	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/validator"
 	"github.com/latoulicious/siresto-backend/pkg/crypto"
 	"github.com/latoulicious/siresto-backend/pkg/dto"
 	"github.com/latoulicious/siresto-backend/pkg/jwt"
@@ -21,7 +22,13 @@ var (
 )
 
 type UserService struct {
-	Repo *repository.UserRepository
+	Repo     *repository.UserRepository
+	Sessions *SessionService
+	Throttle *LoginThrottle
+	// Management roles may need a second factor before a login completes
+	TwoFactor *TwoFactorService
+	// Passwords set through UpdateUser are changed like any other password
+	Passwords *PasswordService
 }
 
 // ListAllUsers returns all users as DTOs
@@ -59,6 +66,10 @@ func (s *UserService) CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse,
 		return nil, ErrEmailAlreadyExists
 	}
 
+	if err := validator.ValidatePassword(req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
 	// Hash password using bcrypt
 	hashedPassword, err := crypto.HashPassword(req.Password)
 	if err != nil {
@@ -111,18 +122,18 @@ func (s *UserService) UpdateUser(id uuid.UUID, req *dto.UpdateUserRequest) (*dto
 		existingUser.Email = *req.Email
 	}
 
+	// Checked before anything is saved; the password itself is set below
 	if req.Password != nil {
-		hashedPassword, err := crypto.HashPassword(*req.Password)
-		if err != nil {
+		if err := validator.ValidatePassword(*req.Password, existingUser.Email); err != nil {
 			return nil, err
 		}
-		existingUser.Password = hashedPassword
 	}
 
 	if req.IsStaff != nil {
 		existingUser.IsStaff = *req.IsStaff
 	}
 
+	roleChanged := req.RoleID != nil && *req.RoleID != existingUser.RoleID
 	if req.RoleID != nil {
 		existingUser.RoleID = *req.RoleID
 	}
@@ -133,6 +144,20 @@ func (s *UserService) UpdateUser(id uuid.UUID, req *dto.UpdateUserRequest) (*dto
 		return nil, err
 	}
 
+	// Like any password change, this logs the user out and voids their reset links
+	if req.Password != nil {
+		if err := s.Passwords.setPassword(updatedUser, *req.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tokens carry the old role's permissions, so force the user to log in again
+	if roleChanged && s.Sessions != nil {
+		if err := s.Sessions.RevokeUserSessions(id); err != nil {
+			return nil, err
+		}
+	}
+
 	// Map to DTO
 	userDTO := mapToUserResponse(updatedUser)
 	return &userDTO, nil
@@ -146,21 +171,173 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 		return ErrUserNotFound
 	}
 
-	return s.Repo.DeleteUser(id)
+	if err := s.Repo.DeleteUser(id); err != nil {
+		return err
+	}
+
+	if s.Sessions != nil {
+		return s.Sessions.RevokeUserSessions(id)
+	}
+	return nil
+}
+
+// ListDeletedUsers lists deleted users that can be restored
+func (s *UserService) ListDeletedUsers() ([]dto.DeletedItemResponse, error) {
+	users, err := s.Repo.ListDeletedUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(users))
+	for _, user := range users {
+		items = append(items, mapDeletedItem(user.ID, user.Name, user.Email, user.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreUser restores a deleted user. Sessions revoked at deletion stay revoked.
+func (s *UserService) RestoreUser(id uuid.UUID) (*dto.UserResponse, error) {
+	user, err := s.Repo.GetDeletedUser(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+
+	if _, err := s.Repo.FindByEmail(user.Email); err == nil {
+		return nil, fmt.Errorf("%w: another user has the email %s", ErrRestoreConflict, user.Email)
+	}
+	roleExists, err := s.Repo.RoleExists(user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if !roleExists {
+		return nil, fmt.Errorf("%w: the user's role was deleted, restore it first", ErrRestoreConflict)
+	}
+
+	if err := s.Repo.RestoreUser(id); err != nil {
+		return nil, notDeleted(err)
+	}
+
+	restored, err := s.Repo.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	userDTO := mapToUserResponse(restored)
+	return &userDTO, nil
+}
+
+// SetUserPin sets the PIN the user signs in with on terminals. Only users whose role
+// ranks below callerPosition can be given a PIN.
+func (s *UserService) SetUserPin(callerPosition int, id uuid.UUID, pin string) error {
+	user, err := s.Repo.GetUserByID(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := checkRank(callerPosition, user); err != nil {
+		return err
+	}
+
+	pinHash, err := crypto.HashPassword(pin)
+	if err != nil {
+		return err
+	}
+	return s.Repo.SetUserPin(id, &pinHash)
 }
 
-// LoginUser authenticates a user and returns user info with token
-func (s *UserService) LoginUser(req *dto.LoginRequest) (*dto.UserLoginResponse, error) {
+// UnlockUser lifts the user's password, PIN and two-factor login lockouts. Only users
+// whose role ranks below callerPosition can be unlocked.
+func (s *UserService) UnlockUser(callerPosition int, id uuid.UUID) error {
+	user, err := s.Repo.GetUserByID(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := checkRank(callerPosition, user); err != nil {
+		return err
+	}
+	return s.Throttle.Unlock(user.Email, pinAccount(user.ID), twoFactorAccount(user.ID))
+}
+
+// GetCallerPosition returns the position of the caller's role, to pass to the methods
+// that manage other users
+func (s *UserService) GetCallerPosition(callerID uuid.UUID) (int, error) {
+	return callerPosition(s.Repo, callerID)
+}
+
+// LoginUser authenticates a user and returns user info with token. Failed attempts
+// are counted per account and per client IP, and locked out ones are refused with
+// a *LoginLockedError before the password is checked. Users who need a second factor
+// get a two-factor challenge instead of tokens.
+func (s *UserService) LoginUser(req *dto.LoginRequest, ip string) (*dto.UserLoginResponse, error) {
+	attempt, err := s.Throttle.Begin(req.Email, ip)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.Repo.FindByEmail(req.Email)
 	if err != nil || user == nil {
+		attempt.Failed()
 		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password using bcrypt
 	if !crypto.CheckPassword(req.Password, user.Password) {
+		attempt.Failed()
 		return nil, ErrInvalidCredentials
 	}
+	attempt.Succeeded()
+
+	if s.TwoFactor != nil {
+		challenge, err := s.TwoFactor.LoginChallenge(user)
+		if err != nil || challenge != nil {
+			return challenge, err
+		}
+	}
+
+	return s.completeLogin(user)
+}
+
+// CompleteTwoFactorLogin finishes a login challenged for a second factor
+func (s *UserService) CompleteTwoFactorLogin(req *dto.TwoFactorLoginRequest, ip string) (*dto.UserLoginResponse, error) {
+	user, err := s.TwoFactor.ResolveChallenge(req.TwoFactorToken, TwoFactorPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TwoFactor.VerifyCode(user, req.Code, ip); err != nil {
+		return nil, err
+	}
+	return s.completeLogin(user)
+}
+
+// BeginTwoFactorEnrollment starts the enrollment a login was challenged for
+func (s *UserService) BeginTwoFactorEnrollment(req *dto.TwoFactorEnrollmentRequest) (*dto.TwoFactorEnrollmentResponse, error) {
+	user, err := s.TwoFactor.ResolveChallenge(req.TwoFactorToken, TwoFactorPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return s.TwoFactor.BeginEnrollment(user.ID)
+}
 
+// CompleteTwoFactorEnrollment confirms the enrollment a login was challenged for and
+// finishes the login, returning the new recovery codes with the tokens
+func (s *UserService) CompleteTwoFactorEnrollment(req *dto.TwoFactorLoginRequest, ip string) (*dto.UserLoginResponse, error) {
+	user, err := s.TwoFactor.ResolveChallenge(req.TwoFactorToken, TwoFactorPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.TwoFactor.ConfirmEnrollment(user.ID, req.Code, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	loginResponse, err := s.completeLogin(user)
+	if err != nil {
+		return nil, err
+	}
+	loginResponse.RecoveryCodes = codes
+	return loginResponse, nil
+}
+
+// completeLogin records the login and starts the session
+func (s *UserService) completeLogin(user *domain.User) (*dto.UserLoginResponse, error) {
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
@@ -169,38 +346,29 @@ func (s *UserService) LoginUser(req *dto.LoginRequest) (*dto.UserLoginResponse,
 		return nil, err
 	}
 
-	// Create user response
-	userResponse := mapToUserResponse(user)
-
-	// Extract role information for JWT
-	roleID := uuid.Nil
-	roleName := ""
-	var permissions []string
-
-	if user.Role != nil {
-		roleID = user.Role.ID
-		roleName = user.Role.Name
-
-		// Get default permissions for this role
-		permissions = middleware.GetDefaultPermissionsForRole(roleName)
-
-		// For custom roles, extract permissions from the database
-		if len(permissions) == 0 && user.Role.Permissions != nil {
-			for _, perm := range user.Role.Permissions {
-				permissions = append(permissions, perm.Name)
-			}
-		}
-	}
+	return s.startSession(user)
+}
 
-	// Generate JWT token with role information and permissions
-	token, err := jwt.GenerateToken(user.ID, roleID, roleName, user.IsStaff, permissions)
+// startSession issues the access token and, when sessions are enabled, the first
+// refresh token of a new login
+func (s *UserService) startSession(user *domain.User) (*dto.UserLoginResponse, error) {
+	token, err := generateAccessToken(user)
 	if err != nil {
 		return nil, err
 	}
 
 	loginResponse := &dto.UserLoginResponse{
-		User:  userResponse,
-		Token: token,
+		User:      mapToUserResponse(user),
+		Token:     token,
+		ExpiresIn: int64(jwt.AccessTokenTTL().Seconds()),
+	}
+
+	if s.Sessions != nil {
+		refreshToken, err := s.Sessions.CreateRefreshToken(user.ID, uuid.New())
+		if err != nil {
+			return nil, err
+		}
+		loginResponse.RefreshToken = refreshToken
 	}
 
 	return loginResponse, nil
@@ -208,6 +376,19 @@ func (s *UserService) LoginUser(req *dto.LoginRequest) (*dto.UserLoginResponse,
 
 // Helper functions
 
+// generateAccessToken creates a JWT carrying the user's role. Permissions are not
+// included; the RBAC middleware resolves them from the role on every request.
+func generateAccessToken(user *domain.User) (string, error) {
+	roleID := uuid.Nil
+	roleName := ""
+	if user.Role != nil {
+		roleID = user.Role.ID
+		roleName = user.Role.Name
+	}
+
+	return jwt.GenerateToken(user.ID, roleID, roleName, user.IsStaff, nil)
+}
+
 // mapToUserResponse maps a domain user to a DTO
 func mapToUserResponse(user *domain.User) dto.UserResponse {
 	role := dto.RoleInfo{}
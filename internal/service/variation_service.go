@@ -2,14 +2,17 @@ package service
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
 )
 
 type VariationService struct {
-	Repo *repository.VariationRepository
+	Repo      *repository.VariationRepository
+	MenuCache *MenuCache
 }
 
 //! Global Variation Service
@@ -30,6 +33,7 @@ func (s *VariationService) CreateVariation(variation *domain.Variation) (*domain
 	if err != nil {
 		return nil, err
 	}
+	s.MenuCache.Invalidate()
 	return variation, nil
 }
 
@@ -39,12 +43,53 @@ func (s *VariationService) UpdateVariation(variation *domain.Variation) (*domain
 	if err != nil {
 		return nil, err
 	}
+	s.MenuCache.Invalidate()
 	return variation, nil
 }
 
 // DeleteVariation deletes a variation by its ID
 func (s *VariationService) DeleteVariation(id uuid.UUID) error {
-	return s.Repo.DeleteVariation(id)
+	if err := s.Repo.DeleteVariation(id); err != nil {
+		return err
+	}
+	s.MenuCache.Invalidate()
+	return nil
+}
+
+// ListDeletedVariations lists deleted variations that can be restored
+func (s *VariationService) ListDeletedVariations() ([]dto.DeletedItemResponse, error) {
+	variations, err := s.Repo.ListDeletedVariations()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.DeletedItemResponse, 0, len(variations))
+	for _, variation := range variations {
+		items = append(items, mapDeletedItem(variation.ID, variation.VariationType, "product "+variation.ProductID.String(), variation.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreVariation restores a deleted variation, provided its product still exists
+func (s *VariationService) RestoreVariation(id uuid.UUID) (*domain.Variation, error) {
+	variation, err := s.Repo.GetDeletedVariation(id)
+	if err != nil {
+		return nil, notDeleted(err)
+	}
+	exists, err := s.Repo.ProductExists(variation.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: the variation's product was deleted, restore it first", ErrRestoreConflict)
+	}
+
+	if err := s.Repo.RestoreVariation(id); err != nil {
+		return nil, notDeleted(err)
+	}
+
+	s.MenuCache.Invalidate()
+	return s.Repo.GetVariationByID(id)
 }
 
 // TODO Implement Function for Product Tied Variations
@@ -69,5 +114,6 @@ func (s *VariationService) CreateProductVariation(productID uuid.UUID, variation
 	if err != nil {
 		return nil, err
 	}
+	s.MenuCache.Invalidate()
 	return variation, nil
 }
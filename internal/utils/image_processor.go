@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Register decoders for image.Decode
+	_ "image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrImageTooLarge       = errors.New("image exceeds the maximum upload size")
+	ErrUnsupportedImage    = errors.New("unsupported image format, use JPEG, PNG or WebP")
+	ErrInvalidImageSize    = errors.New("image dimensions are out of the allowed range")
+	ErrImageDecodingFailed = errors.New("image could not be decoded")
+)
+
+// ImageLimits bounds what an upload may be before it is processed
+type ImageLimits struct {
+	MaxBytes  int64
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+}
+
+// ImageRendition is a resized copy of an upload that fits within MaxWidth x MaxHeight
+type ImageRendition struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// ProcessedImage is an encoded rendition ready to be stored
+type ProcessedImage struct {
+	Name        string
+	Data        []byte
+	Width       int
+	Height      int
+	ContentType string
+	Extension   string
+}
+
+// ProductImageExtension is the file extension of every processed rendition
+const ProductImageExtension = ".webp"
+
+// DefaultProductImageLimits accepts photos from typical phone cameras
+var DefaultProductImageLimits = ImageLimits{
+	MaxBytes:  8 << 20,
+	MinWidth:  200,
+	MinHeight: 200,
+	MaxWidth:  8000,
+	MaxHeight: 8000,
+}
+
+// ProductImageRenditions are generated for every product image
+var ProductImageRenditions = []ImageRendition{
+	{Name: "thumbnail", MaxWidth: 200, MaxHeight: 200},
+	{Name: "card", MaxWidth: 480, MaxHeight: 480},
+	{Name: "full", MaxWidth: 1200, MaxHeight: 1200},
+}
+
+// DetectImageFormat identifies an image by its magic bytes rather than the
+// client-supplied filename or content type
+func DetectImageFormat(header []byte) (string, error) {
+	switch {
+	case len(header) >= 3 && bytes.Equal(header[:3], []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg", nil
+	case len(header) >= 8 && bytes.Equal(header[:8], []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}):
+		return "png", nil
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return "webp", nil
+	default:
+		return "", ErrUnsupportedImage
+	}
+}
+
+// ProcessImage validates an uploaded image and re-encodes it as WebP in every rendition.
+// Dimensions are checked from the header before the full image is decoded, and
+// renditions are only ever scaled down.
+func ProcessImage(r io.Reader, limits ImageLimits, renditions []ImageRendition) ([]ProcessedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	if _, err := DetectImageFormat(data); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageDecodingFailed, err)
+	}
+	if config.Width < limits.MinWidth || config.Height < limits.MinHeight ||
+		config.Width > limits.MaxWidth || config.Height > limits.MaxHeight {
+		return nil, fmt.Errorf("%w: got %dx%d, expected between %dx%d and %dx%d", ErrInvalidImageSize,
+			config.Width, config.Height, limits.MinWidth, limits.MinHeight, limits.MaxWidth, limits.MaxHeight)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageDecodingFailed, err)
+	}
+
+	processed := make([]ProcessedImage, 0, len(renditions))
+	for _, rendition := range renditions {
+		resized := resizeToFit(src, rendition.MaxWidth, rendition.MaxHeight)
+
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, resized, nil); err != nil {
+			return nil, fmt.Errorf("encode %s rendition: %w", rendition.Name, err)
+		}
+
+		bounds := resized.Bounds()
+		processed = append(processed, ProcessedImage{
+			Name:        rendition.Name,
+			Data:        buf.Bytes(),
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+			ContentType: "image/webp",
+			Extension:   ProductImageExtension,
+		})
+	}
+
+	return processed, nil
+}
+
+// resizeToFit scales src down, keeping its aspect ratio, so it fits within maxWidth x maxHeight
+func resizeToFit(src image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return src
+	}
+
+	scale := min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	targetWidth := max(1, int(float64(width)*scale+0.5))
+	targetHeight := max(1, int(float64(height)*scale+0.5))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
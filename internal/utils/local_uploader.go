@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalUploader stores files on the local disk under Dir. The files are expected
+// to be served by a static route mounted at the path of BaseURL.
+type LocalUploader struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalUploader(dir, baseURL string) (*LocalUploader, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage directory: %w", err)
+	}
+	return &LocalUploader{
+		Dir:     dir,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// RoutePrefix returns the URL path the static file route should be mounted on
+func (u *LocalUploader) RoutePrefix() string {
+	prefix := u.BaseURL
+	if parsed, err := url.Parse(u.BaseURL); err == nil && parsed.Path != "" {
+		prefix = parsed.Path
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+func (u *LocalUploader) Upload(file io.Reader, filename string) (string, error) {
+	path, err := u.path(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial upload
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("write file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("write file: %w", err)
+	}
+
+	return u.URL(filename), nil
+}
+
+func (u *LocalUploader) Delete(filename string) error {
+	path, err := u.path(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete file: %w", err)
+	}
+	return nil
+}
+
+func (u *LocalUploader) Exists(filename string) (bool, error) {
+	path, err := u.path(filename)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat file: %w", err)
+	}
+	return !info.IsDir(), nil
+}
+
+func (u *LocalUploader) Open(filename string) (io.ReadCloser, error) {
+	path, err := u.path(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	return file, nil
+}
+
+func (u *LocalUploader) List(prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	err := filepath.WalkDir(u.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(u.Dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, StoredObject{
+			Key:          key,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+	return objects, nil
+}
+
+func (u *LocalUploader) URL(filename string) string {
+	return fmt.Sprintf("%s/%s", u.BaseURL, filename)
+}
+
+func (u *LocalUploader) KeyFromURL(url string) (string, bool) {
+	return keyFromURL(u.BaseURL, url)
+}
+
+// path resolves filename inside Dir and rejects keys that would escape it
+func (u *LocalUploader) path(filename string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(filename)) {
+		return "", fmt.Errorf("invalid file name %q", filename)
+	}
+	return filepath.Join(u.Dir, filepath.FromSlash(filename)), nil
+}
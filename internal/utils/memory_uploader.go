@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryUploader keeps files in memory. It is meant for tests and local runs
+// where nothing needs to survive a restart.
+type MemoryUploader struct {
+	BaseURL string
+
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data     []byte
+	modified time.Time
+}
+
+func NewMemoryUploader(baseURL string) *MemoryUploader {
+	return &MemoryUploader{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		objects: make(map[string]memoryObject),
+	}
+}
+
+func (u *MemoryUploader) Upload(file io.Reader, filename string) (string, error) {
+	buffer := new(bytes.Buffer)
+	if _, err := io.Copy(buffer, file); err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+
+	u.mu.Lock()
+	u.objects[filename] = memoryObject{data: buffer.Bytes(), modified: time.Now()}
+	u.mu.Unlock()
+
+	return u.URL(filename), nil
+}
+
+func (u *MemoryUploader) Delete(filename string) error {
+	u.mu.Lock()
+	delete(u.objects, filename)
+	u.mu.Unlock()
+	return nil
+}
+
+func (u *MemoryUploader) Exists(filename string) (bool, error) {
+	u.mu.RLock()
+	_, ok := u.objects[filename]
+	u.mu.RUnlock()
+	return ok, nil
+}
+
+func (u *MemoryUploader) Open(filename string) (io.ReadCloser, error) {
+	data, ok := u.Get(filename)
+	if !ok {
+		return nil, fmt.Errorf("open file: %q not found", filename)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (u *MemoryUploader) List(prefix string) ([]StoredObject, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	var objects []StoredObject
+	for key, object := range u.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, StoredObject{
+				Key:          key,
+				Size:         int64(len(object.data)),
+				LastModified: object.modified,
+			})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (u *MemoryUploader) URL(filename string) string {
+	return fmt.Sprintf("%s/%s", u.BaseURL, filename)
+}
+
+func (u *MemoryUploader) KeyFromURL(url string) (string, bool) {
+	return keyFromURL(u.BaseURL, url)
+}
+
+// PresignUpload returns a placeholder URL; tests store the file with Upload
+// under the returned key to simulate the client's direct upload
+func (u *MemoryUploader) PresignUpload(filename, contentType string, size int64, expires time.Duration) (*PresignedUpload, error) {
+	return &PresignedUpload{
+		URL:    u.URL(filename),
+		Method: "PUT",
+		Headers: map[string]string{
+			"Content-Type": contentType,
+		},
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// SetModTime overrides the modification time of a stored file, so tests can age objects
+func (u *MemoryUploader) SetModTime(filename string, modified time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if object, ok := u.objects[filename]; ok {
+		object.modified = modified
+		u.objects[filename] = object
+	}
+}
+
+// Get returns a copy of the stored file
+func (u *MemoryUploader) Get(filename string) ([]byte, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	object, ok := u.objects[filename]
+	if !ok {
+		return nil, false
+	}
+	return bytes.Clone(object.data), true
+}
+
+// Keys lists the stored file names in sorted order
+func (u *MemoryUploader) Keys() []string {
+	u.mu.RLock()
+	keys := make([]string, 0, len(u.objects))
+	for key := range u.objects {
+		keys = append(keys, key)
+	}
+	u.mu.RUnlock()
+
+	sort.Strings(keys)
+	return keys
+}
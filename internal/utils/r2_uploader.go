@@ -3,6 +3,7 @@ package utils
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,8 +11,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -132,5 +135,104 @@ func (u *R2Uploader) Upload(file io.Reader, filename string) (string, error) {
 		return "", fmt.Errorf("upload to R2: %w", err)
 	}
 
-	return fmt.Sprintf("%s/%s", u.BaseURL, filename), nil
+	return u.URL(filename), nil
+}
+
+func (u *R2Uploader) Delete(filename string) error {
+	_, err := u.Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(u.BucketName),
+		Key:    aws.String(filename),
+	})
+	if err != nil {
+		return fmt.Errorf("delete from R2: %w", err)
+	}
+	return nil
+}
+
+func (u *R2Uploader) Exists(filename string) (bool, error) {
+	_, err := u.Client.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(u.BucketName),
+		Key:    aws.String(filename),
+	})
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound *types.NotFound
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &notFound) || (errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound) {
+		return false, nil
+	}
+	return false, fmt.Errorf("check object in R2: %w", err)
+}
+
+func (u *R2Uploader) Open(filename string) (io.ReadCloser, error) {
+	output, err := u.Client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(u.BucketName),
+		Key:    aws.String(filename),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get object from R2: %w", err)
+	}
+	return output.Body, nil
+}
+
+func (u *R2Uploader) List(prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	paginator := s3.NewListObjectsV2Paginator(u.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(u.BucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("list R2 objects: %w", err)
+		}
+		for _, object := range page.Contents {
+			objects = append(objects, StoredObject{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (u *R2Uploader) URL(filename string) string {
+	return fmt.Sprintf("%s/%s", u.BaseURL, filename)
+}
+
+func (u *R2Uploader) KeyFromURL(url string) (string, bool) {
+	return keyFromURL(u.BaseURL, url)
+}
+
+func (u *R2Uploader) PresignUpload(filename, contentType string, size int64, expires time.Duration) (*PresignedUpload, error) {
+	presigner := s3.NewPresignClient(u.Client)
+	// Content type, length and ACL are part of the signature, so the client cannot change them
+	request, err := presigner.PresignPutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:        aws.String(u.BucketName),
+		Key:           aws.String(filename),
+		ACL:           "public-read",
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("presign R2 upload: %w", err)
+	}
+
+	headers := make(map[string]string, len(request.SignedHeader))
+	for name, values := range request.SignedHeader {
+		if strings.EqualFold(name, "Host") || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+
+	return &PresignedUpload{
+		URL:       request.URL,
+		Method:    request.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
 }
@@ -2,8 +2,54 @@ package utils
 
 import (
 	"io"
+	"strings"
+	"time"
 )
 
+// Uploader stores files under a key and serves them from a public URL
 type Uploader interface {
 	Upload(file io.Reader, filename string) (string, error)
+	// Delete removes the object stored under filename; deleting a missing object is not an error
+	Delete(filename string) error
+	Exists(filename string) (bool, error)
+	// Open returns the contents of the object stored under filename
+	Open(filename string) (io.ReadCloser, error)
+	// List returns every stored object whose key starts with prefix
+	List(prefix string) ([]StoredObject, error)
+	// URL returns the public URL the object stored under filename is served from
+	URL(filename string) string
+	// KeyFromURL returns the key of an object served at url, or false when
+	// url does not point into this storage
+	KeyFromURL(url string) (string, bool)
+}
+
+// PresignedUploader is implemented by backends that let clients upload directly,
+// without the file passing through the API server
+type PresignedUploader interface {
+	// PresignUpload returns a URL that accepts exactly one file of contentType and size under filename
+	PresignUpload(filename, contentType string, size int64, expires time.Duration) (*PresignedUpload, error)
+}
+
+// PresignedUpload tells a client how to upload a file directly to storage
+type PresignedUpload struct {
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// StoredObject describes an object kept by an Uploader
+type StoredObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// keyFromURL strips baseURL from url, returning false when url lives elsewhere
+func keyFromURL(baseURL, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, baseURL+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
 }
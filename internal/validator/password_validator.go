@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// PasswordPolicy describes the passwords users may choose
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int // bcrypt ignores everything past 72 bytes
+	RequireLetter bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy applies to every password set through the API
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:     8,
+	MaxLength:     72,
+	RequireLetter: true,
+	RequireDigit:  true,
+}
+
+// commonPasswords are rejected even when they satisfy the character rules
+var commonPasswords = map[string]bool{
+	"password1":   true,
+	"password12":  true,
+	"password123": true,
+	"passw0rd":    true,
+	"12345678a":   true,
+	"qwerty123":   true,
+	"abc12345":    true,
+	"letmein1":    true,
+	"welcome1":    true,
+	"admin123":    true,
+	"iloveyou1":   true,
+}
+
+// Validate checks a password against the policy. The email is used to reject
+// passwords that merely repeat the account name.
+func (p PasswordPolicy) Validate(password, email string) error {
+	if len(password) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrWeakPassword, p.MaxLength)
+	}
+
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireLetter && !hasLetter {
+		return fmt.Errorf("%w: must contain a letter", ErrWeakPassword)
+	}
+	if p.RequireDigit && !hasDigit {
+		return fmt.Errorf("%w: must contain a digit", ErrWeakPassword)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("%w: must contain a symbol", ErrWeakPassword)
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("%w: is too common", ErrWeakPassword)
+	}
+	if local, _, ok := strings.Cut(strings.ToLower(email), "@"); ok && len(local) >= 3 && strings.Contains(lower, local) {
+		return fmt.Errorf("%w: must not contain the email address", ErrWeakPassword)
+	}
+	return nil
+}
+
+// ValidatePassword checks a password against DefaultPasswordPolicy
+func ValidatePassword(password, email string) error {
+	return DefaultPasswordPolicy.Validate(password, email)
+}
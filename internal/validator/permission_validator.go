@@ -26,6 +26,7 @@ var (
 		"list",    // For listing operations
 		"access",  // For general access
 		"full",    // For full access
+		"*",       // Any action on the resource
 	}
 
 	// Special prefixes for more complex permissions
@@ -35,8 +36,9 @@ var (
 		"full:",
 	}
 
-	// Valid permission format: action:resource or special:permission
-	permissionFormatRegex = regexp.MustCompile(`^([a-z]+):([a-z_]+)$`)
+	// Valid permission format: action:resource or special:permission, where either
+	// part may be the "*" wildcard
+	permissionFormatRegex = regexp.MustCompile(`^([a-z]+|\*):([a-z_]+|\*)$`)
 )
 
 // ValidatePermission ensures that a permission follows the established patterns
@@ -46,6 +48,11 @@ func ValidatePermission(permission *domain.Permission) error {
 		return fmt.Errorf("permission name cannot be empty")
 	}
 
+	// Validate against permission format regex
+	if !permissionFormatRegex.MatchString(permission.Name) {
+		return fmt.Errorf("permission must follow format 'action:resource' (e.g., 'read:user', 'read:*' or '*:order')")
+	}
+
 	// Check for special permissions
 	for _, prefix := range specialPermissionPrefixes {
 		if strings.HasPrefix(permission.Name, prefix) {
@@ -53,11 +60,6 @@ func ValidatePermission(permission *domain.Permission) error {
 		}
 	}
 
-	// Validate against permission format regex
-	if !permissionFormatRegex.MatchString(permission.Name) {
-		return fmt.Errorf("permission must follow format 'action:resource' (e.g., 'read:user')")
-	}
-
 	// Extract action and resource parts
 	parts := strings.Split(permission.Name, ":")
 	if len(parts) != 2 {
@@ -101,6 +103,16 @@ func GetPermissionDescription(permissionName string) string {
 	action := parts[0]
 	resource := parts[1]
 
+	// Describe wildcard permissions
+	switch {
+	case action == middleware.PermissionWildcard && resource == middleware.PermissionWildcard:
+		return "Permission to perform any action on any resource"
+	case action == middleware.PermissionWildcard:
+		return fmt.Sprintf("Permission to perform any action on %s", strings.ReplaceAll(resource, "_", " "))
+	case resource == middleware.PermissionWildcard:
+		return fmt.Sprintf("Permission to %s any resource", action)
+	}
+
 	// Create a user-friendly description
 	return fmt.Sprintf("Permission to %s %s", action, strings.ReplaceAll(resource, "_", " "))
 }
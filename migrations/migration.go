@@ -3,7 +3,10 @@ package migrations
 import (
 	"log"
 
+	"github.com/google/uuid"
 	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/validator"
 	"gorm.io/gorm"
 )
 
@@ -21,21 +24,41 @@ func RunMigrations(db *gorm.DB) error {
 	// 	return err
 	// }
 
+	// Emails and role and theme names only need to be unique among rows that are not
+	// soft deleted. Drop the old table-wide constraints before AutoMigrate creates the
+	// partial unique indexes declared on the models.
+	log.Println("Replacing unique constraints of soft-deletable tables...")
+	if err := dropSoftDeleteUniqueConstraints(db); err != nil {
+		return err
+	}
+
 	log.Println("Running database migrations...")
 	// Auto-migrate all domain models
 	if err := db.AutoMigrate(
 		// User & authorization models
+		&domain.Permission{},
 		&domain.Role{},
 		&domain.User{},
+		&domain.RefreshToken{},
+		&domain.RevokedToken{},
+		&domain.LoginFailure{},
+		&domain.Terminal{},
+		&domain.PasswordResetToken{},
+		&domain.RecoveryCode{},
+		&domain.APIKey{},
 
 		// Restaurant models
 		&domain.Category{},
 		&domain.Product{},
 		&domain.Variation{},
+		&domain.BundleItem{},
+		&domain.BundleChoice{},
+		&domain.ModifierGroup{},
 
 		// Order processing models
 		&domain.Order{},
 		&domain.OrderDetail{},
+		&domain.OrderDetailComponent{},
 		&domain.Payment{},
 		&domain.Invoice{},
 
@@ -53,6 +76,74 @@ func RunMigrations(db *gorm.DB) error {
 		return err
 	}
 
+	// Store default permissions for built-in roles created before permissions were seeded
+	log.Println("Seeding role permissions...")
+	if err := SeedRolePermissions(db); err != nil {
+		return err
+	}
+
+	// Log access used to come with access:system
+	log.Println("Granting read:log to roles with system access...")
+	if err := grantLogReadPermission(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// grantLogReadPermission gives read:log to every role holding access:system, so roles
+// seeded before the permission existed keep access to the log viewer. It only runs
+// while read:log does not exist yet; afterwards the grant is managed like any other.
+func grantLogReadPermission(db *gorm.DB) error {
+	readLog := middleware.FormatPermission(middleware.PermissionRead, middleware.ResourceLog)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&domain.Permission{}).Where("name = ?", readLog).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var roles []domain.Role
+		if err := tx.Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Where("permissions.name = ?", middleware.PermissionAccessSystem).
+			Find(&roles).Error; err != nil {
+			return err
+		}
+
+		permission := domain.Permission{
+			ID:          uuid.New(),
+			Name:        readLog,
+			Description: validator.GetPermissionDescription(readLog),
+		}
+		if err := tx.Create(&permission).Error; err != nil {
+			return err
+		}
+		for i := range roles {
+			if err := tx.Model(&roles[i]).Association("Permissions").Append(&permission); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// dropSoftDeleteUniqueConstraints removes the unique constraints created by the former
+// `unique` tags, under both names GORM has used for them
+func dropSoftDeleteUniqueConstraints(db *gorm.DB) error {
+	for _, c := range []struct{ table, column string }{
+		{"users", "email"},
+		{"roles", "name"},
+		{"themes", "name"},
+	} {
+		for _, name := range []string{c.table + "_" + c.column + "_key", "uni_" + c.table + "_" + c.column} {
+			if err := db.Exec("ALTER TABLE IF EXISTS " + c.table + " DROP CONSTRAINT IF EXISTS " + name).Error; err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -127,7 +218,7 @@ func SeedData(db *gorm.DB) error {
 		}
 	}
 
-	return nil
+	return SeedRolePermissions(db)
 }
 
 // RunSeeds executes all seed functions to populate the database with initial data
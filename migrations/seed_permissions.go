@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/validator"
+	"gorm.io/gorm"
+)
+
+// builtInRoles are the roles whose default permissions are seeded
+var builtInRoles = []string{
+	middleware.RoleSystem,
+	middleware.RoleOwner,
+	middleware.RoleAdmin,
+	middleware.RoleCashier,
+	middleware.RoleKitchen,
+	middleware.RoleWaiter,
+}
+
+// SeedRolePermissions stores the default permissions of the built-in roles.
+// Each role is seeded only once; afterwards its permissions are managed through
+// the roles API, so an Owner can restrict Admins without a migration undoing it.
+func SeedRolePermissions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, roleName := range builtInRoles {
+			var role domain.Role
+			if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return err
+			}
+			if role.PermissionsSeeded {
+				continue
+			}
+
+			names := middleware.GetDefaultPermissionsForRole(roleName)
+			permissions := make([]*domain.Permission, 0, len(names))
+			for _, name := range names {
+				permission := domain.Permission{}
+				if err := tx.Where(domain.Permission{Name: name}).
+					Attrs(domain.Permission{ID: uuid.New(), Description: validator.GetPermissionDescription(name)}).
+					FirstOrCreate(&permission).Error; err != nil {
+					return err
+				}
+				permissions = append(permissions, &permission)
+			}
+
+			if err := tx.Model(&role).Association("Permissions").Append(permissions); err != nil {
+				return err
+			}
+			if err := tx.Model(&role).Update("permissions_seeded", true).Error; err != nil {
+				return err
+			}
+			log.Printf("Seeded %d permissions for role %s", len(permissions), roleName)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ImageRenditions maps a rendition name (thumbnail, card, full) to its public URL
+type ImageRenditions map[string]string
+
+func (r ImageRenditions) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ImageRenditions: %w", err)
+	}
+	return bytes, nil
+}
+
+func (r *ImageRenditions) Scan(src interface{}) error {
+	if src == nil {
+		*r = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := src.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("ImageRenditions scan: type assertion to []byte failed")
+	}
+
+	if err := json.Unmarshal(bytes, r); err != nil {
+		return fmt.Errorf("ImageRenditions scan: failed to unmarshal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ModifierOption is one add-on inside a modifier group, e.g. "Extra cheese" or "No onion"
+type ModifierOption struct {
+	Label string  `json:"label"`
+	Price float64 `json:"price,omitempty"`
+}
+
+// ModifierOptions is a custom JSONB wrapper for an array of ModifierOption
+type ModifierOptions []ModifierOption
+
+func (m ModifierOptions) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ModifierOptions: %w", err)
+	}
+	return bytes, nil
+}
+
+func (m *ModifierOptions) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+
+	bytes, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("ModifierOptions scan: type assertion to []byte failed")
+	}
+
+	if err := json.Unmarshal(bytes, m); err != nil {
+		return fmt.Errorf("ModifierOptions scan: failed to unmarshal: %w", err)
+	}
+	return nil
+}
+
+// SelectedModifier is a snapshot of a modifier chosen for an order line,
+// kept as ordered even if the modifier group changes later
+type SelectedModifier struct {
+	GroupID   string  `json:"group_id"`
+	GroupName string  `json:"group_name"`
+	Label     string  `json:"label"`
+	Price     float64 `json:"price"`
+}
+
+// SelectedModifiers is a custom JSONB wrapper for an array of SelectedModifier
+type SelectedModifiers []SelectedModifier
+
+func (m SelectedModifiers) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SelectedModifiers: %w", err)
+	}
+	return bytes, nil
+}
+
+func (m *SelectedModifiers) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+
+	bytes, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("SelectedModifiers scan: type assertion to []byte failed")
+	}
+
+	if err := json.Unmarshal(bytes, m); err != nil {
+		return fmt.Errorf("SelectedModifiers scan: failed to unmarshal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	scheduleDateLayout = "2006-01-02"
+	scheduleTimeLayout = "15:04"
+)
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// AvailabilityWindow is a daily time window, optionally limited to some days of the week.
+// Days use three-letter names ("mon" … "sun"); an empty list means every day.
+// When EndTime is not after StartTime the window runs past midnight into the next day.
+type AvailabilityWindow struct {
+	Days      []string `json:"days,omitempty"`
+	StartTime string   `json:"start_time"` // HH:MM
+	EndTime   string   `json:"end_time"`   // HH:MM
+}
+
+// AvailabilitySchedule restricts when a menu item can be ordered.
+// StartDate and EndDate (YYYY-MM-DD, inclusive) bound the schedule; within them the
+// item is available during any of the windows, or all day if there are none.
+type AvailabilitySchedule struct {
+	StartDate string               `json:"start_date,omitempty"`
+	EndDate   string               `json:"end_date,omitempty"`
+	Windows   []AvailabilityWindow `json:"windows,omitempty"`
+}
+
+// IsEmpty reports whether the schedule places no restriction at all
+func (s *AvailabilitySchedule) IsEmpty() bool {
+	return s == nil || (s.StartDate == "" && s.EndDate == "" && len(s.Windows) == 0)
+}
+
+// Validate checks dates, times and day names
+func (s *AvailabilitySchedule) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	var start, end time.Time
+	var err error
+	if s.StartDate != "" {
+		if start, err = time.Parse(scheduleDateLayout, s.StartDate); err != nil {
+			return fmt.Errorf("start_date must be in YYYY-MM-DD format")
+		}
+	}
+	if s.EndDate != "" {
+		if end, err = time.Parse(scheduleDateLayout, s.EndDate); err != nil {
+			return fmt.Errorf("end_date must be in YYYY-MM-DD format")
+		}
+	}
+	if s.StartDate != "" && s.EndDate != "" && end.Before(start) {
+		return fmt.Errorf("end_date cannot be before start_date")
+	}
+
+	for i, window := range s.Windows {
+		if _, err := parseScheduleClock(window.StartTime); err != nil {
+			return fmt.Errorf("windows[%d].start_time must be in HH:MM format", i)
+		}
+		if _, err := parseScheduleClock(window.EndTime); err != nil {
+			return fmt.Errorf("windows[%d].end_time must be in HH:MM format", i)
+		}
+		for _, day := range window.Days {
+			if _, ok := scheduleWeekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("windows[%d].days contains invalid day %q", i, day)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsAvailableAt reports whether the schedule allows ordering at t.
+// t must already be in the restaurant's timezone. A nil schedule is always available.
+func (s *AvailabilitySchedule) IsAvailableAt(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	date := t.Format(scheduleDateLayout)
+	if s.StartDate != "" && date < s.StartDate {
+		return false
+	}
+	if s.EndDate != "" && date > s.EndDate {
+		return false
+	}
+
+	if len(s.Windows) == 0 {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	for _, window := range s.Windows {
+		if window.contains(t.Weekday(), minute) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w AvailabilityWindow) contains(weekday time.Weekday, minute int) bool {
+	start, err := parseScheduleClock(w.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseScheduleClock(w.EndTime)
+	if err != nil {
+		return false
+	}
+
+	if start < end {
+		return minute >= start && minute < end && w.onDay(weekday)
+	}
+
+	// Overnight window: the late part belongs to today, the early part to yesterday's window
+	if minute >= start {
+		return w.onDay(weekday)
+	}
+	return minute < end && w.onDay((weekday+6)%7)
+}
+
+func (w AvailabilityWindow) onDay(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, day := range w.Days {
+		if d, ok := scheduleWeekdays[strings.ToLower(day)]; ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseScheduleClock converts HH:MM into minutes since midnight
+func parseScheduleClock(value string) (int, error) {
+	t, err := time.Parse(scheduleTimeLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (s AvailabilitySchedule) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal AvailabilitySchedule: %w", err)
+	}
+	return bytes, nil
+}
+
+func (s *AvailabilitySchedule) Scan(src interface{}) error {
+	if src == nil {
+		*s = AvailabilitySchedule{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := src.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("AvailabilitySchedule scan: unsupported type %T", src)
+	}
+
+	if err := json.Unmarshal(bytes, s); err != nil {
+		return fmt.Errorf("AvailabilitySchedule scan: failed to unmarshal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList stores a list of strings, such as permission names, in a jsonb column
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	bytes, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StringList: %w", err)
+	}
+	return bytes, nil
+}
+
+func (l *StringList) Scan(src interface{}) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := src.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("StringList scan: type assertion to []byte failed")
+	}
+
+	if err := json.Unmarshal(bytes, l); err != nil {
+		return fmt.Errorf("StringList scan: failed to unmarshal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Response DTOs
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyCreatedResponse carries the key itself, which is shown only once
+type APIKeyCreatedResponse struct {
+	APIKey APIKeyResponse `json:"api_key"`
+	Key    string         `json:"key"`
+}
@@ -2,39 +2,46 @@ package dto
 
 import (
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 )
 
 // --- Request DTOs ---
 type CreateCategoryRequest struct {
-	Name     string `json:"name" binding:"required"`
-	IsActive *bool  `json:"is_active,omitempty"`
-	Position *int   `json:"position,omitempty"`
+	Name     string                   `json:"name" binding:"required"`
+	IsActive *bool                    `json:"is_active,omitempty"`
+	Position *int                     `json:"position,omitempty"`
+	Schedule *db.AvailabilitySchedule `json:"schedule,omitempty"`
 }
 
 type UpdateCategoryRequest struct {
-	Name     *string `json:"name,omitempty"`
-	IsActive *bool   `json:"is_active,omitempty"`
-	Position *int    `json:"position,omitempty"`
+	Name     *string                  `json:"name,omitempty"`
+	IsActive *bool                    `json:"is_active,omitempty"`
+	Position *int                     `json:"position,omitempty"`
+	Schedule *db.AvailabilitySchedule `json:"schedule,omitempty"` // An empty schedule removes the restriction
 }
 
 // --- Response DTOs ---
 type CategoryResponse struct {
-	ID       uuid.UUID        `json:"id"`
-	Name     string           `json:"name"`
-	IsActive bool             `json:"is_active"`
-	Position int              `json:"position"`
-	Products []ProductSummary `json:"products,omitempty"`
+	ID       uuid.UUID                `json:"id"`
+	Name     string                   `json:"name"`
+	IsActive bool                     `json:"is_active"`
+	Position int                      `json:"position"`
+	Schedule *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	Products []ProductSummary         `json:"products,omitempty"`
 }
 
 type ProductSummary struct {
-	ID          uuid.UUID          `json:"id"`
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	ImageURL    string             `json:"image_url"`
-	BasePrice   float64            `json:"base_price"`
-	IsAvailable bool               `json:"is_available"`
-	Position    int                `json:"position"`
-	Variations  []VariationSummary `json:"variations,omitempty"`
+	ID          uuid.UUID                `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	ImageURL    string                   `json:"image_url"`
+	Images      *ProductImages           `json:"images,omitempty"`
+	BasePrice   float64                  `json:"base_price"`
+	IsAvailable bool                     `json:"is_available"`
+	Position    int                      `json:"position"`
+	Schedule    *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	IsBundle    bool                     `json:"is_bundle"`
+	Variations  []VariationSummary       `json:"variations,omitempty"`
 }
 
 type VariationSummary struct {
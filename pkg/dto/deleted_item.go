@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeletedItemResponse is a soft-deleted record that can be restored
+type DeletedItemResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Detail    string    `json:"detail,omitempty"` // Tells apart items of the same name, e.g. a user's email
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+)
+
+// LogFilter selects logs for the log viewer; zero fields do not filter
+type LogFilter struct {
+	Level     string
+	Source    string
+	Action    string
+	Entity    string
+	EntityID  *uuid.UUID
+	UserID    *uuid.UUID
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	Metadata  map[string]string // Top-level metadata keys and the text of their values
+	Cursor    string            // NextCursor of the previous page
+	Limit     int
+}
+
+// LogPageResponse is one page of logs, newest first
+type LogPageResponse struct {
+	Logs       []domain.Log `json:"logs"`
+	NextCursor string       `json:"next_cursor,omitempty"` // Empty on the last page
+}
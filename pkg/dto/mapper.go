@@ -12,6 +12,7 @@ func ToCategoryResponse(c *domain.Category) *CategoryResponse {
 		Name:     c.Name,
 		IsActive: c.IsActive,
 		Position: c.Position,
+		Schedule: normalizeSchedule(c.Schedule),
 	}
 
 	for _, product := range c.Products {
@@ -27,9 +28,12 @@ func ToProductSummary(p *domain.Product) ProductSummary {
 		Name:        p.Name,
 		Description: p.Description,
 		ImageURL:    p.ImageURL,
+		Images:      ToProductImages(p.Images),
 		BasePrice:   p.BasePrice,
 		IsAvailable: p.IsAvailable,
 		Position:    p.Position,
+		Schedule:    normalizeSchedule(p.Schedule),
+		IsBundle:    p.IsBundle,
 	}
 
 	for _, v := range p.Variations {
@@ -50,6 +54,14 @@ func ToVariationSummary(v *domain.Variation) VariationSummary {
 	}
 }
 
+// normalizeSchedule treats an empty schedule the same as no schedule
+func normalizeSchedule(schedule *db.AvailabilitySchedule) *db.AvailabilitySchedule {
+	if schedule.IsEmpty() {
+		return nil
+	}
+	return schedule
+}
+
 func toVariationOptions(options db.VariationOptions) []VariationOption {
 	var dtoOptions []VariationOption
 	for _, opt := range options {
@@ -66,15 +78,20 @@ func toVariationOptions(options db.VariationOptions) []VariationOption {
 // Product DTO
 func ToProductResponse(p *domain.Product) *ProductResponse {
 	product := &ProductResponse{
-		ID:           p.ID,
-		Name:         p.Name,
-		Description:  p.Description,
-		ImageURL:     p.ImageURL,
-		BasePrice:    p.BasePrice,
-		IsAvailable:  p.IsAvailable,
-		Position:     p.Position,
-		CategoryID:   p.CategoryID,
-		CategoryName: p.CategoryName,
+		ID:             p.ID,
+		Name:           p.Name,
+		Description:    p.Description,
+		ImageURL:       p.ImageURL,
+		Images:         ToProductImages(p.Images),
+		BasePrice:      p.BasePrice,
+		IsAvailable:    p.IsAvailable,
+		Position:       p.Position,
+		Schedule:       normalizeSchedule(p.Schedule),
+		IsBundle:       p.IsBundle,
+		BundleItems:    ToBundleItemSummaries(p.BundleItems),
+		ModifierGroups: ToModifierGroupResponses(p.ModifierGroups),
+		CategoryID:     p.CategoryID,
+		CategoryName:   p.CategoryName,
 	}
 
 	for _, v := range p.Variations {
@@ -83,6 +100,19 @@ func ToProductResponse(p *domain.Product) *ProductResponse {
 	return product
 }
 
+// ToProductImages maps stored renditions to their response form, or nil when the
+// product has no processed image
+func ToProductImages(images db.ImageRenditions) *ProductImages {
+	if len(images) == 0 {
+		return nil
+	}
+	return &ProductImages{
+		Thumbnail: images["thumbnail"],
+		Card:      images["card"],
+		Full:      images["full"],
+	}
+}
+
 func ToCreateProductRequest(p *domain.Product) *CreateProductRequest {
 	return &CreateProductRequest{
 		Name:        p.Name,
@@ -92,6 +122,7 @@ func ToCreateProductRequest(p *domain.Product) *CreateProductRequest {
 		IsAvailable: p.IsAvailable,
 		Position:    p.Position,
 		CategoryID:  p.CategoryID,
+		Schedule:    p.Schedule,
 	}
 }
 
@@ -104,6 +135,7 @@ func ToUpdateProductRequest(p *domain.Product) *UpdateProductRequest {
 		IsAvailable: &p.IsAvailable,
 		Position:    &p.Position,
 		CategoryID:  p.CategoryID,
+		Schedule:    p.Schedule,
 	}
 }
 
@@ -117,6 +149,9 @@ func ToProductDomainFromCreate(request *CreateProductRequest) *domain.Product {
 		IsAvailable: request.IsAvailable,
 		Position:    request.Position,
 		CategoryID:  request.CategoryID,
+		Schedule:    normalizeSchedule(request.Schedule),
+		IsBundle:    request.IsBundle,
+		BundleItems: ToBundleItemsDomain(request.BundleItems),
 	}
 }
 
@@ -147,10 +182,140 @@ func ToProductDomainFromUpdate(request *UpdateProductRequest, existingProduct *d
 	if request.CategoryID != nil {
 		updatedProduct.CategoryID = request.CategoryID
 	}
+	if request.Schedule != nil {
+		updatedProduct.Schedule = normalizeSchedule(request.Schedule)
+	}
+	if request.IsBundle != nil {
+		updatedProduct.IsBundle = *request.IsBundle
+	}
+	if request.BundleItems != nil {
+		updatedProduct.BundleItems = ToBundleItemsDomain(request.BundleItems)
+	}
 
 	return &updatedProduct
 }
 
+// Bundle DTO
+func ToBundleItemSummaries(items []domain.BundleItem) []BundleItemSummary {
+	var summaries []BundleItemSummary
+	for _, item := range items {
+		summary := BundleItemSummary{
+			ID:        item.ID,
+			Name:      item.Name,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Position:  item.Position,
+		}
+		if item.Product != nil {
+			summary.ProductName = item.Product.Name
+		}
+
+		for _, choice := range item.Choices {
+			choiceSummary := BundleChoiceSummary{
+				ProductID:     choice.ProductID,
+				PriceModifier: choice.PriceModifier,
+			}
+			if choice.Product != nil {
+				choiceSummary.ProductName = choice.Product.Name
+			}
+			summary.Choices = append(summary.Choices, choiceSummary)
+		}
+
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// Mapping DTO back to &Domain
+func ToBundleItemsDomain(requests []BundleItemRequest) []domain.BundleItem {
+	if requests == nil {
+		return nil
+	}
+
+	items := make([]domain.BundleItem, 0, len(requests))
+	for _, req := range requests {
+		item := domain.BundleItem{
+			Name:      req.Name,
+			ProductID: req.ProductID,
+			Quantity:  req.Quantity,
+			Position:  req.Position,
+		}
+		if req.ID != nil {
+			item.ID = *req.ID
+		}
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+
+		for _, choice := range req.Choices {
+			item.Choices = append(item.Choices, domain.BundleChoice{
+				ProductID:     choice.ProductID,
+				PriceModifier: choice.PriceModifier,
+			})
+		}
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// Modifier DTO
+func ToModifierGroupResponse(g *domain.ModifierGroup) ModifierGroupResponse {
+	response := ModifierGroupResponse{
+		ID:          g.ID,
+		ProductID:   g.ProductID,
+		Name:        g.Name,
+		MinSelect:   g.MinSelect,
+		MaxSelect:   g.MaxSelect,
+		IsAvailable: g.IsAvailable,
+		Position:    g.Position,
+		Options:     make([]ModifierOption, 0, len(g.Options)),
+	}
+
+	for _, opt := range g.Options {
+		response.Options = append(response.Options, ModifierOption{
+			Label: opt.Label,
+			Price: opt.Price,
+		})
+	}
+	return response
+}
+
+func ToModifierGroupResponses(groups []domain.ModifierGroup) []ModifierGroupResponse {
+	var responses []ModifierGroupResponse
+	for i := range groups {
+		responses = append(responses, ToModifierGroupResponse(&groups[i]))
+	}
+	return responses
+}
+
+// Mapping DTO back to &Domain
+func ToModifierGroupDomain(request *CreateModifierGroupRequest) *domain.ModifierGroup {
+	group := &domain.ModifierGroup{
+		Name:        request.Name,
+		MinSelect:   request.MinSelect,
+		MaxSelect:   request.MaxSelect,
+		IsAvailable: true,
+		Position:    request.Position,
+		Options:     ToModifierOptionsDomain(request.Options),
+	}
+	if request.IsAvailable != nil {
+		group.IsAvailable = *request.IsAvailable
+	}
+	return group
+}
+
+func ToModifierOptionsDomain(options []ModifierOption) db.ModifierOptions {
+	dbOptions := make(db.ModifierOptions, 0, len(options))
+	for _, opt := range options {
+		dbOptions = append(dbOptions, db.ModifierOption{
+			Label: opt.Label,
+			Price: opt.Price,
+		})
+	}
+	return dbOptions
+}
+
 // Variation DTO
 func ToVariationResponses(variations []*domain.Variation) []VariationSummary {
 	var variationResponses []VariationSummary
@@ -376,6 +541,29 @@ func MapToOrderResponseDTO(order *domain.Order) OrderResponseDTO {
 			imageURL = detail.Product.ImageURL
 		}
 
+		// Expand bundle contents for the kitchen
+		var components []OrderItemComponentDTO
+		for _, component := range detail.Components {
+			componentProductID := ""
+			if component.ProductID != nil {
+				componentProductID = component.ProductID.String()
+			}
+			components = append(components, OrderItemComponentDTO{
+				ProductID:   componentProductID,
+				ProductName: component.ProductName,
+				Quantity:    component.Quantity,
+			})
+		}
+
+		var modifiers []OrderItemModifierDTO
+		for _, modifier := range detail.Modifiers {
+			modifiers = append(modifiers, OrderItemModifierDTO{
+				Group: modifier.GroupName,
+				Label: modifier.Label,
+				Price: modifier.Price,
+			})
+		}
+
 		items = append(items, OrderItemDTO{
 			ID:          detail.ID.String(),
 			ProductID:   productID,
@@ -386,6 +574,8 @@ func MapToOrderResponseDTO(order *domain.Order) OrderResponseDTO {
 			TotalPrice:  totalPrice,
 			Note:        detail.Note,
 			ImageURL:    imageURL,
+			Components:  components,
+			Modifiers:   modifiers,
 		})
 	}
 
@@ -420,3 +610,51 @@ func MapToOrderResponseDTO(order *domain.Order) OrderResponseDTO {
 		PaymentMethods: paymentMethods,
 	}
 }
+
+// Public Menu DTO
+func ToPublicMenuResponse(categories []domain.Category) *PublicMenuResponse {
+	menu := &PublicMenuResponse{
+		Categories: make([]PublicMenuCategory, 0, len(categories)),
+	}
+
+	for _, c := range categories {
+		category := PublicMenuCategory{
+			ID:       c.ID,
+			Name:     c.Name,
+			Position: c.Position,
+			Schedule: normalizeSchedule(c.Schedule),
+			Products: make([]PublicMenuProduct, 0, len(c.Products)),
+		}
+
+		for _, p := range c.Products {
+			product := PublicMenuProduct{
+				ID:             p.ID,
+				Name:           p.Name,
+				Description:    p.Description,
+				ImageURL:       p.ImageURL,
+				Images:         ToProductImages(p.Images),
+				BasePrice:      p.BasePrice,
+				Position:       p.Position,
+				Schedule:       normalizeSchedule(p.Schedule),
+				IsBundle:       p.IsBundle,
+				BundleItems:    ToBundleItemSummaries(p.BundleItems),
+				ModifierGroups: ToModifierGroupResponses(p.ModifierGroups),
+			}
+
+			for _, v := range p.Variations {
+				product.Variations = append(product.Variations, PublicMenuVariation{
+					ID:            v.ID,
+					VariationType: v.VariationType,
+					IsRequired:    v.IsRequired,
+					Options:       toVariationOptions(v.Options),
+				})
+			}
+
+			category.Products = append(category.Products, product)
+		}
+
+		menu.Categories = append(menu.Categories, category)
+	}
+
+	return menu
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+)
+
+// --- Request DTOs ---
+type MenuImportRequest struct {
+	Categories []MenuCategoryItem `json:"categories"`
+}
+
+type MenuCategoryItem struct {
+	Name     string            `json:"name"`
+	IsActive *bool             `json:"is_active,omitempty"`
+	Position int               `json:"position"`
+	Products []MenuProductItem `json:"products,omitempty"`
+	Row      int               `json:"-"` // Source CSV row, 0 for JSON payloads
+}
+
+type MenuProductItem struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	ImageURL    string              `json:"image_url,omitempty"`
+	BasePrice   float64             `json:"base_price"`
+	IsAvailable *bool               `json:"is_available,omitempty"`
+	Position    int                 `json:"position"`
+	Variations  []MenuVariationItem `json:"variations,omitempty"`
+	Row         int                 `json:"-"`
+}
+
+type MenuVariationItem struct {
+	VariationType string            `json:"variation_type"`
+	IsDefault     bool              `json:"is_default"`
+	IsAvailable   *bool             `json:"is_available,omitempty"`
+	IsRequired    bool              `json:"is_required"`
+	Options       []VariationOption `json:"options"`
+	Row           int               `json:"-"`
+}
+
+// --- Response DTOs ---
+type MenuImportRowError struct {
+	Row     int    `json:"row,omitempty"`
+	Path    string `json:"path"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type MenuImportResult struct {
+	DryRun            bool                 `json:"dry_run"`
+	Applied           bool                 `json:"applied"`
+	CategoriesCreated int                  `json:"categories_created"`
+	CategoriesUpdated int                  `json:"categories_updated"`
+	ProductsCreated   int                  `json:"products_created"`
+	ProductsUpdated   int                  `json:"products_updated"`
+	VariationsCreated int                  `json:"variations_created"`
+	VariationsUpdated int                  `json:"variations_updated"`
+	Errors            []MenuImportRowError `json:"errors"`
+}
+
+type MenuExportResponse struct {
+	Categories []MenuCategoryItem `json:"categories"`
+}
+
+type PublicMenuResponse struct {
+	Categories []PublicMenuCategory `json:"categories"`
+}
+
+type PublicMenuCategory struct {
+	ID       uuid.UUID                `json:"id"`
+	Name     string                   `json:"name"`
+	Position int                      `json:"position"`
+	Schedule *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	Products []PublicMenuProduct      `json:"products"`
+}
+
+type PublicMenuProduct struct {
+	ID             uuid.UUID                `json:"id"`
+	Name           string                   `json:"name"`
+	Description    string                   `json:"description,omitempty"`
+	ImageURL       string                   `json:"image_url,omitempty"`
+	Images         *ProductImages           `json:"images,omitempty"`
+	BasePrice      float64                  `json:"base_price"`
+	Position       int                      `json:"position"`
+	Schedule       *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	IsBundle       bool                     `json:"is_bundle,omitempty"`
+	BundleItems    []BundleItemSummary      `json:"bundle_items,omitempty"`
+	ModifierGroups []ModifierGroupResponse  `json:"modifier_groups,omitempty"`
+	Variations     []PublicMenuVariation    `json:"variations,omitempty"`
+}
+
+type PublicMenuVariation struct {
+	ID            uuid.UUID         `json:"id"`
+	VariationType string            `json:"variation_type"`
+	IsRequired    bool              `json:"is_required"`
+	Options       []VariationOption `json:"options"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "github.com/google/uuid"
+
+// --- Request DTOs ---
+type CreateModifierGroupRequest struct {
+	Name        string           `json:"name" binding:"required"`
+	MinSelect   int              `json:"min_select"`
+	MaxSelect   int              `json:"max_select"` // 0 means no upper limit
+	IsAvailable *bool            `json:"is_available,omitempty"`
+	Position    int              `json:"position"`
+	Options     []ModifierOption `json:"options" binding:"required"`
+}
+
+type UpdateModifierGroupRequest struct {
+	Name        *string          `json:"name,omitempty"`
+	MinSelect   *int             `json:"min_select,omitempty"`
+	MaxSelect   *int             `json:"max_select,omitempty"`
+	IsAvailable *bool            `json:"is_available,omitempty"`
+	Position    *int             `json:"position,omitempty"`
+	Options     []ModifierOption `json:"options,omitempty"`
+}
+
+// --- Response DTOs ---
+type ModifierGroupResponse struct {
+	ID          uuid.UUID        `json:"id"`
+	ProductID   uuid.UUID        `json:"product_id"`
+	Name        string           `json:"name"`
+	MinSelect   int              `json:"min_select"`
+	MaxSelect   int              `json:"max_select"`
+	IsAvailable bool             `json:"is_available"`
+	Position    int              `json:"position"`
+	Options     []ModifierOption `json:"options"`
+}
+
+type ModifierOption struct {
+	Label string  `json:"label"`
+	Price float64 `json:"price"`
+}
@@ -30,15 +30,31 @@ type PaymentDTO struct {
 
 // --- Response DTOs ---
 type OrderItemDTO struct {
-	ID          string  `json:"id"`
-	ProductID   string  `json:"productId"`
-	ProductName string  `json:"productName"`
-	Variation   string  `json:"variation,omitempty"`
-	Quantity    int     `json:"quantity"`
-	UnitPrice   float64 `json:"unitPrice"`
-	TotalPrice  float64 `json:"totalPrice"`
-	Note        string  `json:"note,omitempty"`
-	ImageURL    string  `json:"imageUrl,omitempty"`
+	ID          string                  `json:"id"`
+	ProductID   string                  `json:"productId"`
+	ProductName string                  `json:"productName"`
+	Variation   string                  `json:"variation,omitempty"`
+	Quantity    int                     `json:"quantity"`
+	UnitPrice   float64                 `json:"unitPrice"`
+	TotalPrice  float64                 `json:"totalPrice"`
+	Note        string                  `json:"note,omitempty"`
+	ImageURL    string                  `json:"imageUrl,omitempty"`
+	Components  []OrderItemComponentDTO `json:"components,omitempty"`
+	Modifiers   []OrderItemModifierDTO  `json:"modifiers,omitempty"`
+}
+
+// OrderItemModifierDTO is an add-on chosen for an order item, shown on the kitchen ticket
+type OrderItemModifierDTO struct {
+	Group string  `json:"group"`
+	Label string  `json:"label"`
+	Price float64 `json:"price"`
+}
+
+// OrderItemComponentDTO is one product a bundle item expands into for the kitchen
+type OrderItemComponentDTO struct {
+	ProductID   string `json:"productId,omitempty"`
+	ProductName string `json:"productName"`
+	Quantity    int    `json:"quantity"`
 }
 
 type PaymentMethodDTO struct {
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/db"
 )
 
 // --- Request DTOs ---
@@ -13,6 +14,9 @@ type CreateProductRequest struct {
 	IsAvailable bool                     `json:"is_available" binding:"required"`
 	Position    int                      `json:"position" binding:"required"`
 	CategoryID  *uuid.UUID               `json:"category_id" binding:"required"`
+	Schedule    *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	IsBundle    bool                     `json:"is_bundle,omitempty"`
+	BundleItems []BundleItemRequest      `json:"bundle_items,omitempty"`
 	Variations  []CreateVariationRequest `json:"variations,omitempty"`
 }
 
@@ -24,20 +28,66 @@ type UpdateProductRequest struct {
 	IsAvailable           *bool                    `json:"is_available,omitempty"`
 	Position              *int                     `json:"position,omitempty"`
 	CategoryID            *uuid.UUID               `json:"category_id,omitempty"`
+	Schedule              *db.AvailabilitySchedule `json:"schedule,omitempty"` // An empty schedule removes the restriction
+	IsBundle              *bool                    `json:"is_bundle,omitempty"`
+	BundleItems           []BundleItemRequest      `json:"bundle_items,omitempty"` // Replaces the bundle contents when present
 	Variations            []UpdateVariationRequest `json:"variations,omitempty"`
 	RemoveOtherVariations *bool                    `json:"remove_other_variations,omitempty"`
 }
 
+// BundleItemRequest describes one bundle slot: a fixed product or a choice group
+type BundleItemRequest struct {
+	ID        *uuid.UUID            `json:"id,omitempty"` // Keeps an existing slot when updating
+	Name      string                `json:"name"`
+	ProductID *uuid.UUID            `json:"product_id,omitempty"`
+	Quantity  int                   `json:"quantity,omitempty"`
+	Position  int                   `json:"position"`
+	Choices   []BundleChoiceRequest `json:"choices,omitempty"`
+}
+
+type BundleChoiceRequest struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	PriceModifier float64   `json:"price_modifier,omitempty"`
+}
+
 // --- Response DTOs ---
 type ProductResponse struct {
-	ID           uuid.UUID          `json:"id"`
-	CategoryID   *uuid.UUID         `json:"category_id"`
-	CategoryName string             `json:"category_name"`
-	Name         string             `json:"name"`
-	Description  string             `json:"description"`
-	ImageURL     string             `json:"image_url"`
-	BasePrice    float64            `json:"base_price"`
-	IsAvailable  bool               `json:"is_available"`
-	Position     int                `json:"position"`
-	Variations   []VariationSummary `json:"variations,omitempty"`
+	ID             uuid.UUID                `json:"id"`
+	CategoryID     *uuid.UUID               `json:"category_id"`
+	CategoryName   string                   `json:"category_name"`
+	Name           string                   `json:"name"`
+	Description    string                   `json:"description"`
+	ImageURL       string                   `json:"image_url"`
+	Images         *ProductImages           `json:"images,omitempty"`
+	BasePrice      float64                  `json:"base_price"`
+	IsAvailable    bool                     `json:"is_available"`
+	Position       int                      `json:"position"`
+	Schedule       *db.AvailabilitySchedule `json:"schedule,omitempty"`
+	IsBundle       bool                     `json:"is_bundle"`
+	BundleItems    []BundleItemSummary      `json:"bundle_items,omitempty"`
+	ModifierGroups []ModifierGroupResponse  `json:"modifier_groups,omitempty"`
+	Variations     []VariationSummary       `json:"variations,omitempty"`
+}
+
+// ProductImages holds the URLs of the processed renditions of a product image
+type ProductImages struct {
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Card      string `json:"card,omitempty"`
+	Full      string `json:"full,omitempty"`
+}
+
+type BundleItemSummary struct {
+	ID          uuid.UUID             `json:"id"`
+	Name        string                `json:"name"`
+	ProductID   *uuid.UUID            `json:"product_id,omitempty"`
+	ProductName string                `json:"product_name,omitempty"`
+	Quantity    int                   `json:"quantity"`
+	Position    int                   `json:"position"`
+	Choices     []BundleChoiceSummary `json:"choices,omitempty"`
+}
+
+type BundleChoiceSummary struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	PriceModifier float64   `json:"price_modifier"`
 }
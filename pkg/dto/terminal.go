@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Request DTOs
+type RegisterTerminalRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type SetPinRequest struct {
+	Pin string `json:"pin" validate:"required,numeric,min=4,max=8"`
+}
+
+type PinLoginRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Pin    string    `json:"pin" validate:"required,numeric,min=4,max=8"`
+}
+
+// Response DTOs
+type TerminalResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TerminalRegistrationResponse carries the device key, which is shown only once
+type TerminalRegistrationResponse struct {
+	Terminal TerminalResponse `json:"terminal"`
+	Key      string           `json:"key"`
+}
+
+// TerminalStaffResponse is a staff member who can sign in on a terminal with a PIN
+type TerminalStaffResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role"`
+}
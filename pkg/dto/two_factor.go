@@ -0,0 +1,38 @@
+package dto
+
+// TwoFactorCodeRequest carries a code from the authenticator app, or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest completes a login that returned a two-factor challenge
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// TwoFactorEnrollmentRequest starts the enrollment required before a first login
+type TwoFactorEnrollmentRequest struct {
+	TwoFactorToken string `json:"two_factor_token" validate:"required"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI for a QR code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown once; each works a single time
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled            bool  `json:"enabled"`
+	Available          bool  `json:"available"` // The user's role may use two-factor authentication
+	Required           bool  `json:"required"`  // The user's role must use it
+	RecoveryCodesCount int64 `json:"recovery_codes_count"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- Request DTOs ---
+type PresignUploadRequest struct {
+	Target      string    `json:"target" binding:"required"` // "product" or "theme"
+	TargetID    uuid.UUID `json:"target_id" binding:"required"`
+	Field       string    `json:"field,omitempty"` // "logo" or "favicon" for themes
+	ContentType string    `json:"content_type" binding:"required"`
+	Size        int64     `json:"size" binding:"required"`
+}
+
+type ConfirmUploadRequest struct {
+	Target   string    `json:"target" binding:"required"`
+	TargetID uuid.UUID `json:"target_id" binding:"required"`
+	Field    string    `json:"field,omitempty"`
+	Key      string    `json:"key" binding:"required"`
+}
+
+// --- Response DTOs ---
+type PresignUploadResponse struct {
+	Key       string            `json:"key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"` // Must be sent unchanged with the upload
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type ConfirmUploadResponse struct {
+	Target   string    `json:"target"`
+	TargetID uuid.UUID `json:"target_id"`
+	Field    string    `json:"field,omitempty"`
+	Key      string    `json:"key"`
+	URL      string    `json:"url"`
+}
@@ -28,6 +28,28 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type PermissionCheckRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1,max=100,dive,required"`
+}
+
 // Response DTOs
 type UserResponse struct {
 	ID          uuid.UUID  `json:"id"`
@@ -40,8 +62,33 @@ type UserResponse struct {
 }
 
 type UserLoginResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token,omitempty"`
+	User         UserResponse `json:"user"`
+	Token        string       `json:"token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	ExpiresIn    int64        `json:"expires_in,omitempty"` // Access token lifetime in seconds
+	// Set instead of the tokens when the login needs a second factor; the two-factor
+	// token completes it at /auth/2fa/verify, or at /auth/2fa/enroll when enrollment is required
+	TwoFactorRequired           bool     `json:"two_factor_required,omitempty"`
+	TwoFactorEnrollmentRequired bool     `json:"two_factor_enrollment_required,omitempty"`
+	TwoFactorToken              string   `json:"two_factor_token,omitempty"`
+	RecoveryCodes               []string `json:"recovery_codes,omitempty"` // Issued when enrollment completes a login
+}
+
+// MeResponse describes the authenticated user and what they are allowed to do
+type MeResponse struct {
+	User               UserResponse `json:"user"`
+	Role               RoleInfo     `json:"role"`
+	Permissions        []string     `json:"permissions"`         // Effective, with wildcards and manage:<resource> expanded
+	GrantedPermissions []string     `json:"granted_permissions"` // As stored on the role
+}
+
+type PermissionCheckResponse struct {
+	Results map[string]bool `json:"results"`
+}
+
+// PasswordResetResponse confirms a reset link was sent; the token itself is only in the link
+type PasswordResetResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type RoleInfo struct {
@@ -9,41 +9,98 @@ import (
 	"github.com/google/uuid"
 )
 
+// Default token lifetimes, overridable with JWT_ACCESS_TOKEN_TTL, JWT_REFRESH_TOKEN_TTL
+// and JWT_TERMINAL_TOKEN_TTL
+const (
+	DefaultAccessTokenTTL   = 15 * time.Minute
+	DefaultRefreshTokenTTL  = 30 * 24 * time.Hour
+	DefaultTerminalTokenTTL = 12 * time.Hour // About one shift; terminal logins get no refresh token
+
+	// ChallengeTokenTTL bounds the time between the password and the second login step
+	ChallengeTokenTTL = 5 * time.Minute
+)
+
+func init() {
+	// Issue times carry microseconds, so a session cutoff can tell tokens issued
+	// within the same second apart
+	jwt.TimePrecision = time.Microsecond
+}
+
 type Claims struct {
 	UserID      uuid.UUID `json:"user_id"`
 	RoleID      uuid.UUID `json:"role_id,omitempty"`
 	RoleName    string    `json:"role_name,omitempty"`
 	IsStaff     bool      `json:"is_staff"`
 	Permissions []string  `json:"permissions,omitempty"`
+	// Set on PIN logins: the token is only accepted together with the terminal's key
+	TerminalID      uuid.UUID `json:"terminal_id,omitempty"`
+	TerminalKeyHash string    `json:"terminal_key_hash,omitempty"`
+	// Set on challenge tokens, which only complete a login step and are not access tokens
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func GenerateToken(userID uuid.UUID, roleID uuid.UUID, roleName string, isStaff bool, permissions []string) (string, error) {
-	// Get secret key from environment variable
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-	if secretKey == "" {
-		return "", fmt.Errorf("JWT_SECRET_KEY not set in environment")
+	// Create claims with user ID and standard claims
+	claims := &Claims{
+		UserID:           userID,
+		RoleID:           roleID,
+		RoleName:         roleName,
+		IsStaff:          isStaff,
+		Permissions:      permissions,
+		RegisteredClaims: registeredClaims(AccessTokenTTL()),
 	}
+	return signToken(claims)
+}
 
-	// Create claims with user ID and standard claims
+// GenerateTerminalToken issues a staff token bound to the terminal it was requested from
+func GenerateTerminalToken(userID, roleID uuid.UUID, roleName string, terminalID uuid.UUID, terminalKeyHash string) (string, error) {
 	claims := &Claims{
-		UserID:      userID,
-		RoleID:      roleID,
-		RoleName:    roleName,
-		IsStaff:     isStaff,
-		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token expires in 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		UserID:           userID,
+		RoleID:           roleID,
+		RoleName:         roleName,
+		IsStaff:          true,
+		TerminalID:       terminalID,
+		TerminalKeyHash:  terminalKeyHash,
+		RegisteredClaims: registeredClaims(TerminalTokenTTL()),
 	}
+	return signToken(claims)
+}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// GenerateChallengeToken issues a short-lived token that proves the first login step
+// passed, for the given purpose only
+func GenerateChallengeToken(userID uuid.UUID, purpose string) (string, error) {
+	claims := &Claims{
+		UserID:           userID,
+		Purpose:          purpose,
+		RegisteredClaims: registeredClaims(ChallengeTokenTTL),
+	}
+	return signToken(claims)
+}
+
+func registeredClaims(ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        uuid.New().String(), // Lets a single token be revoked before it expires
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+}
+
+func signToken(claims *Claims) (string, error) {
+	keys, err := loadKeys()
+	if err != nil {
+		return "", err
+	}
+
+	// Create token with claims; asymmetric keys name themselves in the kid header
+	token := jwt.NewWithClaims(keys.method, claims)
+	if keys.keyID != "" {
+		token.Header["kid"] = keys.keyID
+	}
 
-	// Sign token with secret key
-	tokenString, err := token.SignedString([]byte(secretKey))
+	tokenString, err := token.SignedString(keys.signingKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %v", err)
 	}
@@ -51,21 +108,39 @@ func GenerateToken(userID uuid.UUID, roleID uuid.UUID, roleName string, isStaff
 	return tokenString, nil
 }
 
+// ValidateToken validates an access token. Challenge tokens are rejected.
 func ValidateToken(tokenString string) (*Claims, error) {
-	// Get secret key from environment variable
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-	if secretKey == "" {
-		return nil, fmt.Errorf("JWT_SECRET_KEY not set in environment")
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("invalid token")
 	}
+	return claims, nil
+}
 
-	// Parse and validate token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secretKey), nil
-	})
+// ValidateChallengeToken validates a challenge token issued for the given purpose
+func ValidateChallengeToken(tokenString, purpose string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
 
+func parseToken(tokenString string) (*Claims, error) {
+	keys, err := loadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse and validate token
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.verificationKey,
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %v", err)
 	}
@@ -77,3 +152,26 @@ func ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// AccessTokenTTL returns how long access tokens stay valid
+func AccessTokenTTL() time.Duration {
+	return ttlFromEnv("JWT_ACCESS_TOKEN_TTL", DefaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL returns how long refresh tokens stay valid
+func RefreshTokenTTL() time.Duration {
+	return ttlFromEnv("JWT_REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL)
+}
+
+// TerminalTokenTTL returns how long tokens from terminal PIN logins stay valid
+func TerminalTokenTTL() time.Duration {
+	return ttlFromEnv("JWT_TERMINAL_TOKEN_TTL", DefaultTerminalTokenTTL)
+}
+
+func ttlFromEnv(name string, fallback time.Duration) time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || ttl <= 0 {
+		return fallback
+	}
+	return ttl
+}
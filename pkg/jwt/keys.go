@@ -0,0 +1,355 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms selectable with JWT_SIGNING_ALG
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const minRSAKeyBits = 2048
+
+// keySet holds the key tokens are signed with and every key they may be verified with.
+//
+// HS256 signs with JWT_SECRET_KEY. RS256 and EdDSA sign with the PEM private key in
+// JWT_PRIVATE_KEY_FILE and put its key ID in the kid header. JWT_PUBLIC_KEY_FILES lists
+// further PEM public keys, comma separated, that are still accepted: to rotate, sign
+// with the new key and keep the old public key listed until its tokens have expired.
+// Once an asymmetric key signs, HS256 tokens are rejected, since anyone holding the old
+// secret could mint them. To move from HS256 without logging everybody out, set
+// JWT_ACCEPT_HS256_UNTIL to an RFC 3339 time after the last HS256 token expires; the
+// secret verifies tokens until then.
+type keySet struct {
+	method      jwt.SigningMethod
+	signingKey  interface{}                 // []byte, *rsa.PrivateKey or ed25519.PrivateKey
+	keyID       string                      // Empty for HS256
+	secret      []byte                      // Nil when HS256 tokens are not accepted
+	secretUntil time.Time                   // Zero while HS256 signs
+	publicKeys  map[string]crypto.PublicKey // By key ID
+}
+
+var (
+	keysMu     sync.Mutex
+	keysSource string
+	keysCache  *keySet
+)
+
+// loadKeys returns the configured keys. They are parsed again only when the
+// environment changes; rewritten key files need a restart.
+func loadKeys() (*keySet, error) {
+	source := strings.Join([]string{
+		os.Getenv("JWT_SIGNING_ALG"),
+		os.Getenv("JWT_SECRET_KEY"),
+		os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		os.Getenv("JWT_PUBLIC_KEY_FILES"),
+		os.Getenv("JWT_ACCEPT_HS256_UNTIL"),
+	}, "\x00")
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if keysCache != nil && keysSource == source {
+		return keysCache, nil
+	}
+
+	keys, err := parseKeys()
+	if err != nil {
+		return nil, err
+	}
+	keysCache, keysSource = keys, source
+	return keys, nil
+}
+
+// CheckKeys reports a key configuration that cannot sign tokens, so the server can
+// refuse to start instead of failing every login
+func CheckKeys() error {
+	_, err := loadKeys()
+	return err
+}
+
+func parseKeys() (*keySet, error) {
+	keys := &keySet{publicKeys: make(map[string]crypto.PublicKey)}
+	if secret := os.Getenv("JWT_SECRET_KEY"); secret != "" {
+		keys.secret = []byte(secret)
+	}
+
+	alg := os.Getenv("JWT_SIGNING_ALG")
+	switch alg {
+	case "", AlgHS256:
+		if keys.secret == nil {
+			return nil, fmt.Errorf("JWT_SECRET_KEY not set in environment")
+		}
+		keys.method = jwt.SigningMethodHS256
+		keys.signingKey = keys.secret
+	case AlgRS256, AlgEdDSA:
+		path := os.Getenv("JWT_PRIVATE_KEY_FILE")
+		if path == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE not set in environment")
+		}
+		private, err := readPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		public := private.Public()
+		method, err := methodForKey(public)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if method.Alg() != alg {
+			return nil, fmt.Errorf("%s holds a %s key but JWT_SIGNING_ALG is %s", path, method.Alg(), alg)
+		}
+		keyID, err := KeyID(public)
+		if err != nil {
+			return nil, err
+		}
+		keys.method = method
+		keys.signingKey = private
+		keys.keyID = keyID
+		keys.publicKeys[keyID] = public
+
+		secret := keys.secret
+		keys.secret = nil
+		if raw := os.Getenv("JWT_ACCEPT_HS256_UNTIL"); raw != "" {
+			until, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_ACCEPT_HS256_UNTIL %q, use RFC 3339", raw)
+			}
+			if secret == nil {
+				return nil, fmt.Errorf("JWT_ACCEPT_HS256_UNTIL needs JWT_SECRET_KEY")
+			}
+			keys.secret = secret
+			keys.secretUntil = until
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_ALG %q, use %s, %s or %s", alg, AlgHS256, AlgRS256, AlgEdDSA)
+	}
+
+	for _, path := range strings.Split(os.Getenv("JWT_PUBLIC_KEY_FILES"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		public, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := methodForKey(public); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keyID, err := KeyID(public)
+		if err != nil {
+			return nil, err
+		}
+		keys.publicKeys[keyID] = public
+	}
+
+	return keys, nil
+}
+
+// verificationKey returns the key a token must be verified with
+func (k *keySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if k.secret == nil {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if !k.secretUntil.IsZero() && !time.Now().Before(k.secretUntil) {
+			return nil, fmt.Errorf("HS256 tokens are no longer accepted")
+		}
+		return k.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		keyID, _ := token.Header["kid"].(string)
+		public, ok := k.publicKeys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", keyID)
+		}
+		// A key only verifies tokens of its own algorithm
+		if method, err := methodForKey(public); err != nil || method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("signing key %q does not match algorithm %v", keyID, token.Header["alg"])
+		}
+		return public, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
+
+func methodForKey(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported private key type %T", path, key)
+	}
+	return signer, nil
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// JSONWebKey is the public part of a signing key, as published in a JWKS (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519 curve
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeySet returns the keys other services can verify our tokens with: the current
+// signing key first, then the keys kept for rotation. HS256 secrets are never published.
+func PublicKeySet() (*JSONWebKeySet, error) {
+	keys, err := loadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keyIDs := make([]string, 0, len(keys.publicKeys))
+	for keyID := range keys.publicKeys {
+		if keyID != keys.keyID {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+	sort.Strings(keyIDs)
+	if keys.keyID != "" {
+		keyIDs = append([]string{keys.keyID}, keyIDs...)
+	}
+
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keyIDs))}
+	for _, keyID := range keyIDs {
+		jwk, err := publicJWK(keys.publicKeys[keyID])
+		if err != nil {
+			return nil, err
+		}
+		jwk.Kid = keyID
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+func publicJWK(public crypto.PublicKey) (JSONWebKey, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: AlgRS256,
+			N:   encode(key.N.Bytes()),
+			E:   encode(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: AlgEdDSA,
+			Crv: "Ed25519",
+			X:   encode(key),
+		}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported key type %T", public)
+	}
+}
+
+// KeyID returns the RFC 7638 thumbprint of a public key. Deriving the kid from the key
+// keeps it identical on every instance without configuring it separately.
+func KeyID(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	// The thumbprint hashes the required members in lexicographic order
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// OverflowPolicy decides what happens to a log line while the buffer is full
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards the line so logging never delays a request; dropped
+	// lines are counted and reported
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock makes the caller wait until the worker has made room
+	OverflowBlock OverflowPolicy = "block"
+)
+
+var ErrPersisterClosed = errors.New("log persister is closed")
+
+// BatchConfig tunes a BatchPersister
+type BatchConfig struct {
+	BufferSize    int           // Lines held in memory waiting to be written
+	BatchSize     int           // Lines written per insert
+	FlushInterval time.Duration // Longest a line waits before it is written
+	Overflow      OverflowPolicy
+}
+
+// DefaultBatchConfig is used for the settings a BatchConfig leaves at zero
+var DefaultBatchConfig = BatchConfig{
+	BufferSize:    4096,
+	BatchSize:     100,
+	FlushInterval: time.Second,
+	Overflow:      OverflowDrop,
+}
+
+// BatchPersister persists logs from a background worker that writes them in
+// batches, so logging does not add a database round trip to the caller.
+// Close flushes what is still buffered.
+type BatchPersister struct {
+	db      *gorm.DB
+	config  BatchConfig
+	entries chan domain.Log
+	done    chan struct{}
+	dropped atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewBatchPersister starts a BatchPersister writing to db
+func NewBatchPersister(db *gorm.DB, config BatchConfig) *BatchPersister {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBatchConfig.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchConfig.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultBatchConfig.FlushInterval
+	}
+	if config.Overflow == "" {
+		config.Overflow = DefaultBatchConfig.Overflow
+	}
+
+	p := &BatchPersister{
+		db:      db,
+		config:  config,
+		entries: make(chan domain.Log, config.BufferSize),
+		done:    make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// PersistLog queues the log line for the worker
+func (p *BatchPersister) PersistLog(level, msg string, fields map[string]interface{}) error {
+	entry := newLogEntry(level, msg, fields)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPersisterClosed
+	}
+
+	if p.config.Overflow == OverflowBlock {
+		p.entries <- entry
+		return nil
+	}
+	select {
+	case p.entries <- entry:
+	default:
+		p.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped returns how many lines were discarded because the buffer was full
+func (p *BatchPersister) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// Close stops accepting lines and waits until the buffered ones are written, or
+// until ctx is done
+func (p *BatchPersister) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.entries)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *BatchPersister) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.Log, 0, p.config.BatchSize)
+	var reported int64
+	flush := func() {
+		if len(batch) > 0 {
+			// The logger cannot log its own failures, so they go to the console
+			if err := p.db.CreateInBatches(batch, p.config.BatchSize).Error; err != nil {
+				log.Printf("Failed to persist %d log entries: %v", len(batch), err)
+			}
+			batch = batch[:0]
+		}
+		if dropped := p.dropped.Load(); dropped > reported {
+			log.Printf("Dropped %d log entries because the log buffer was full", dropped-reported)
+			reported = dropped
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-p.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= p.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
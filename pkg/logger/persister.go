@@ -2,6 +2,7 @@ package logger
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,36 +23,46 @@ func NewLogServicePersister(DB *gorm.DB) *LogServicePersister {
 
 // PersistLog directly persists the log entry into the database
 func (p *LogServicePersister) PersistLog(level, msg string, fields map[string]interface{}) error {
-	log := domain.Log{
+	log := newLogEntry(level, msg, fields)
+
+	// Insert the log directly into the database
+	if err := p.DB.Create(&log).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newLogEntry builds the row stored for a log line
+func newLogEntry(level, msg string, fields map[string]interface{}) domain.Log {
+	return domain.Log{
 		ID:          generateUUID(), // Generate new UUID for the log
 		Timestamp:   time.Now(),     // Current timestamp
 		Level:       level,          // Log level (e.g., "info", "error")
 		Source:      toString(fields["source"], "unknown"),
+		UserID:      toUUIDPtr(fields["user_id"]),
+		IPAddress:   toStringPtr(fields["ip_address"]),
 		Action:      toString(fields["action"], "unknown"),
 		Entity:      toString(fields["entity"], "unknown"),
+		EntityID:    toUUIDPtr(fields["entity_id"]),
 		Description: msg,
 		Metadata:    mapToJSONB(fields),                // Use db.JSONB for metadata field
 		RequestID:   toStringPtr(fields["request_id"]), // Convert to *string
 		Environment: os.Getenv("APP_ENV"),
 		Application: os.Getenv("APP_NAME"),
-		Type:        "activity", // Default type for the log
-	}
-
-	// Insert the log directly into the database
-	if err := p.DB.Create(&log).Error; err != nil {
-		return err
+		Hostname:    hostname(),
+		Type:        toString(fields["type"], "activity"), // Activity unless the caller says otherwise
 	}
-
-	return nil
 }
 
-// mapToJSONB converts fields to a db.JSONB object for storage
+// mapToJSONB copies fields to a db.JSONB object for storage. The copy keeps entries
+// that are written later from seeing changes the caller makes to its map.
 func mapToJSONB(fields map[string]interface{}) db.JSONB {
-	if len(fields) > 0 {
-		// Assuming db.JSONB is a custom type that handles JSON objects
-		return db.JSONB(fields)
+	metadata := make(db.JSONB, len(fields))
+	for k, v := range fields {
+		metadata[k] = v
 	}
-	return db.JSONB{} // Return an empty JSONB object if fields are empty
+	return metadata
 }
 
 // toString safely converts any value to a string, using a fallback value if nil or not a string
@@ -71,6 +82,32 @@ func toStringPtr(val interface{}) *string {
 	return &str // Return a pointer to the string
 }
 
+// toUUIDPtr accepts a uuid.UUID or its string form, as callers log either
+func toUUIDPtr(val interface{}) *uuid.UUID {
+	switch v := val.(type) {
+	case uuid.UUID:
+		if v != uuid.Nil {
+			return &v
+		}
+	case *uuid.UUID:
+		return v
+	case string:
+		if id, err := uuid.Parse(v); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// hostname returns the machine's hostname, or nil if it cannot be determined
+var hostname = sync.OnceValue(func() *string {
+	name, err := os.Hostname()
+	if err != nil {
+		return nil
+	}
+	return &name
+})
+
 // generateUUID generates a new UUID and returns it as a uuid.UUID type
 func generateUUID() uuid.UUID {
 	return uuid.New() // Return uuid.UUID type
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictPermissions(t *testing.T) {
+	assert.Equal(t, []string{"read:order"}, middleware.RestrictPermissions([]string{"manage:order", "read:menu"}, []string{"read:order"}))
+	assert.Equal(t, []string{"read:order"}, middleware.RestrictPermissions([]string{"read:order", "read:menu"}, []string{"manage:order"}))
+	assert.Equal(t, []string{"read:menu"}, middleware.RestrictPermissions([]string{"full:access"}, []string{"read:menu"}))
+	assert.Empty(t, middleware.RestrictPermissions([]string{"read:menu"}, []string{"read:order"}))
+}
+
+func TestAPIKeys(t *testing.T) {
+	db := setupRouteTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE "api_keys" ("id" TEXT PRIMARY KEY, "name" TEXT, "prefix" TEXT, "key_hash" TEXT UNIQUE,
+		"user_id" TEXT, "scopes" TEXT, "expires_at" DATETIME, "created_at" DATETIME, "last_used_at" DATETIME, "revoked_at" DATETIME)`).Error)
+	_, token := createRoleUser(t, db, middleware.RoleOwner)
+	app := SetupTestApp(db)
+	t.Cleanup(func() { middleware.SetAPIKeyAuthenticator(nil) })
+
+	request := func(method, path, auth string, body interface{}) (int, []byte) {
+		var reader *bytes.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(encoded)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", "Bearer "+auth)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = buf.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, buf.Bytes()
+	}
+
+	createKey := func(scopes []string, expiresAt *time.Time) string {
+		status, body := request(fiber.MethodPost, "/api/v1/api-keys", token, dto.CreateAPIKeyRequest{Name: "Accounting sync", Scopes: scopes, ExpiresAt: expiresAt})
+		require.Equal(t, fiber.StatusCreated, status, string(body))
+		var created struct {
+			Data dto.APIKeyCreatedResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &created))
+		assert.True(t, len(created.Data.Key) > len(created.Data.APIKey.Prefix))
+		assert.Equal(t, created.Data.APIKey.Prefix, created.Data.Key[:len(created.Data.APIKey.Prefix)])
+		return created.Data.Key
+	}
+
+	key := createKey([]string{"read:order"}, nil)
+
+	t.Run("Scopes limit what the key can do", func(t *testing.T) {
+		status, _ := request(fiber.MethodGet, "/api/v1/orders", key, nil)
+		assert.NotContains(t, []int{fiber.StatusUnauthorized, fiber.StatusForbidden}, status)
+
+		// The owner may read the menu, the key may not
+		status, _ = request(fiber.MethodGet, "/api/v1/menu/export", key, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+
+		status, body := request(fiber.MethodPost, "/api/v1/me/can", key, dto.PermissionCheckRequest{Permissions: []string{"read:order", "read:menu"}})
+		require.Equal(t, fiber.StatusOK, status)
+		var can struct {
+			Data dto.PermissionCheckResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &can))
+		assert.Equal(t, map[string]bool{"read:order": true, "read:menu": false}, can.Data.Results)
+	})
+
+	t.Run("Keys cannot change credentials or issue keys", func(t *testing.T) {
+		status, _ := request(fiber.MethodPost, "/api/v1/me/password", key, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = request(fiber.MethodPost, "/api/v1/api-keys", key, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("Scopes cannot exceed the owner's permissions", func(t *testing.T) {
+		status, _ := request(fiber.MethodPost, "/api/v1/api-keys", token, dto.CreateAPIKeyRequest{Name: "Too much", Scopes: []string{"delete:setting"}})
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("Expired keys are rejected", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		expiring := createKey([]string{"read:order"}, &expiresAt)
+		require.NoError(t, db.Exec(`UPDATE api_keys SET expires_at = ? WHERE expires_at IS NOT NULL`, time.Now().Add(-time.Minute)).Error)
+
+		status, _ := request(fiber.MethodGet, "/api/v1/orders", expiring, nil)
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+
+	t.Run("Last use is recorded at most once a minute", func(t *testing.T) {
+		lastUsedAt := func() time.Time {
+			var keys []struct{ LastUsedAt time.Time }
+			require.NoError(t, db.Raw(`SELECT last_used_at FROM api_keys WHERE expires_at IS NULL`).Scan(&keys).Error)
+			require.Len(t, keys, 1)
+			return keys[0].LastUsedAt
+		}
+
+		recent := time.Now().Add(-30 * time.Second)
+		require.NoError(t, db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE expires_at IS NULL`, recent).Error)
+		request(fiber.MethodGet, "/api/v1/orders", key, nil)
+		assert.WithinDuration(t, recent, lastUsedAt(), time.Millisecond)
+
+		stale := time.Now().Add(-2 * time.Minute)
+		require.NoError(t, db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE expires_at IS NULL`, stale).Error)
+		request(fiber.MethodGet, "/api/v1/orders", key, nil)
+		assert.WithinDuration(t, time.Now(), lastUsedAt(), 5*time.Second)
+	})
+
+	t.Run("Keys are only listed and revoked by their owner", func(t *testing.T) {
+		_, otherToken := createRoleUser(t, db, middleware.RoleSystem)
+		list := func(path, auth string) []dto.APIKeyResponse {
+			status, body := request(fiber.MethodGet, path, auth, nil)
+			require.Equal(t, fiber.StatusOK, status, string(body))
+			var listed struct {
+				Data []dto.APIKeyResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(body, &listed))
+			return listed.Data
+		}
+
+		assert.Empty(t, list("/api/v1/api-keys", otherToken))
+		owned := list("/api/v1/api-keys", token)
+		require.Len(t, owned, 2)
+		status, _ := request(fiber.MethodDelete, "/api/v1/api-keys/"+owned[0].ID.String(), otherToken, nil)
+		assert.Equal(t, fiber.StatusNotFound, status)
+
+		// Administrators see and revoke every key on the admin path
+		assert.Len(t, list("/api/v1/admin/api-keys", otherToken), 2)
+		_, cashierToken := createRoleUser(t, db, middleware.RoleCashier)
+		status, _ = request(fiber.MethodGet, "/api/v1/admin/api-keys", cashierToken, nil)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = request(fiber.MethodDelete, "/api/v1/admin/api-keys/"+owned[0].ID.String(), otherToken, nil)
+		assert.Equal(t, fiber.StatusOK, status)
+	})
+
+	t.Run("Revoked keys are rejected", func(t *testing.T) {
+		status, body := request(fiber.MethodGet, "/api/v1/api-keys", token, nil)
+		require.Equal(t, fiber.StatusOK, status)
+		var list struct {
+			Data []dto.APIKeyResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &list))
+		require.Len(t, list.Data, 2)
+		for _, listed := range list.Data {
+			status, _ = request(fiber.MethodDelete, "/api/v1/api-keys/"+listed.ID.String(), token, nil)
+			require.Equal(t, fiber.StatusOK, status)
+		}
+
+		status, _ = request(fiber.MethodGet, "/api/v1/orders", key, nil)
+		assert.Equal(t, fiber.StatusUnauthorized, status)
+	})
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTrail(t *testing.T) {
+	db := setupRouteTestDB(t)
+	for _, stmt := range []string{
+		`CREATE TABLE "refresh_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "family_id" TEXT,
+			"token_hash" TEXT UNIQUE, "expires_at" DATETIME, "created_at" DATETIME, "revoked_at" DATETIME)`,
+		`CREATE TABLE "password_reset_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "token_hash" TEXT UNIQUE,
+			"expires_at" DATETIME, "created_at" DATETIME, "used_at" DATETIME)`,
+		`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER, "last_failed_at" DATETIME, "locked_until" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+	createLogsTable(t, db)
+	ownerID, token := createRoleUser(t, db, middleware.RoleOwner)
+	_, kitchenToken := createRoleUser(t, db, middleware.RoleKitchen)
+	app := SetupTestApp(db)
+	t.Cleanup(func() { middleware.SetAuditRecorder(nil) })
+
+	roleID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name, position) VALUES (?, ?, ?)`, roleID, middleware.RoleCashier, 4).Error)
+	userID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, "Dana", "dana@example.com", "x", true, roleID, time.Now()).Error)
+
+	request := func(method, path, auth string, body interface{}) int {
+		encoded, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+		req.Header.Set("Authorization", "Bearer "+auth)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HeaderRequestID, "req-"+method)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	auditEntries := func() []domain.Log {
+		var logs []domain.Log
+		require.NoError(t, db.Where("type = ?", "audit").Order("timestamp").Find(&logs).Error)
+		return logs
+	}
+
+	t.Run("Updates record the actor and a diff", func(t *testing.T) {
+		require.Equal(t, fiber.StatusOK, request(fiber.MethodPut, "/api/v1/users/"+userID.String(), token, map[string]string{"name": "Dana Smith"}))
+
+		logs := auditEntries()
+		require.Len(t, logs, 1)
+		entry := logs[0]
+		assert.Equal(t, "user.updated", entry.Action)
+		assert.Equal(t, "user", entry.Entity)
+		require.NotNil(t, entry.EntityID)
+		assert.Equal(t, userID, *entry.EntityID)
+		require.NotNil(t, entry.UserID)
+		assert.Equal(t, ownerID, *entry.UserID)
+		require.NotNil(t, entry.IPAddress)
+		require.NotNil(t, entry.RequestID)
+		assert.Equal(t, "req-PUT", *entry.RequestID)
+		assert.Equal(t, "/api/v1/users/:id", entry.Metadata["route"])
+
+		diff, ok := entry.Metadata["diff"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"name": map[string]interface{}{"before": "Dana", "after": "Dana Smith"}}, diff)
+	})
+
+	t.Run("Failed and forbidden requests are not recorded", func(t *testing.T) {
+		assert.Equal(t, fiber.StatusForbidden, request(fiber.MethodDelete, "/api/v1/users/"+userID.String(), kitchenToken, nil))
+		assert.Equal(t, fiber.StatusNotFound, request(fiber.MethodDelete, "/api/v1/users/"+uuid.NewString(), token, nil))
+		assert.Len(t, auditEntries(), 1)
+	})
+
+	t.Run("Deletes keep the state before", func(t *testing.T) {
+		require.Equal(t, fiber.StatusOK, request(fiber.MethodDelete, "/api/v1/users/"+userID.String(), token, nil))
+
+		logs := auditEntries()
+		require.Len(t, logs, 2)
+		entry := logs[1]
+		assert.Equal(t, "user.deleted", entry.Action)
+		before, ok := entry.Metadata["before"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "dana@example.com", before["email"])
+		assert.Nil(t, entry.Metadata["after"])
+		assert.NotContains(t, before, "password")
+	})
+
+	t.Run("Credential changes are recorded against the user", func(t *testing.T) {
+		password, err := crypto.HashPassword("owner-password1")
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`UPDATE users SET password = ? WHERE id = ?`, password, ownerID).Error)
+
+		// Only a higher role may send the owner a reset link
+		_, systemToken := createRoleUser(t, db, middleware.RoleSystem)
+		require.Equal(t, fiber.StatusOK, request(fiber.MethodPost, "/api/v1/users/"+ownerID.String()+"/password-reset", systemToken, nil))
+		require.Equal(t, fiber.StatusOK, request(fiber.MethodPost, "/api/v1/me/password", token,
+			map[string]string{"current_password": "owner-password1", "new_password": "changed-password2"}))
+		// Stands in for a reset link, whose token only reaches the console; changing the
+		// password above used up the one just sent
+		require.NoError(t, db.Exec(`INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+			uuid.New(), ownerID, crypto.HashToken("audit-reset-token"), time.Now().Add(time.Hour), time.Now()).Error)
+		require.Equal(t, fiber.StatusOK, request(fiber.MethodPost, "/api/v1/auth/password-reset", "",
+			map[string]string{"token": "audit-reset-token", "new_password": "reset-password3"}))
+
+		logs := auditEntries()
+		require.Len(t, logs, 5)
+		for i, action := range []string{"user.password_reset_requested", "user.password_changed", "user.password_reset"} {
+			entry := logs[2+i]
+			assert.Equal(t, action, entry.Action)
+			require.NotNil(t, entry.EntityID)
+			assert.Equal(t, ownerID, *entry.EntityID)
+			assert.NotNil(t, entry.Metadata["before"], action)
+		}
+	})
+}
@@ -54,6 +54,7 @@ func (s *AuthTestSuite) SetupSuite() {
 		"is_staff" BOOLEAN DEFAULT false,
 		"created_at" DATETIME,
 		"updated_at" DATETIME,
+		"deleted_at" DATETIME,
 		"role_id" TEXT
 	)`)
 
@@ -63,7 +64,8 @@ func (s *AuthTestSuite) SetupSuite() {
 		"name" TEXT NOT NULL UNIQUE,
 		"description" TEXT,
 		"created_at" DATETIME,
-		"updated_at" DATETIME
+		"updated_at" DATETIME,
+		"deleted_at" DATETIME
 	)`)
 
 	// Create simplified permissions table
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/config"
+	"github.com/latoulicious/siresto-backend/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBatchPersisterDB(t *testing.T) *gorm.DB {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// Every connection to :memory: opens a new database, and the worker writes from its own goroutine
+	sqlDB, err := database.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	createLogsTable(t, database)
+	return database
+}
+
+func countLogs(t *testing.T, database *gorm.DB) int64 {
+	var count int64
+	require.NoError(t, database.Table("logs").Count(&count).Error)
+	return count
+}
+
+func closePersister(t *testing.T, persister *logger.BatchPersister) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, persister.Close(ctx))
+}
+
+func TestBatchPersister(t *testing.T) {
+	fields := map[string]interface{}{"source": "test", "action": "batch.test", "entity": "log"}
+
+	t.Run("Close flushes buffered lines", func(t *testing.T) {
+		database := setupBatchPersisterDB(t)
+		persister := logger.NewBatchPersister(database, logger.BatchConfig{BatchSize: 1000, FlushInterval: time.Hour})
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, persister.PersistLog("info", "buffered", fields))
+		}
+		assert.Equal(t, int64(0), countLogs(t, database))
+
+		closePersister(t, persister)
+		assert.Equal(t, int64(10), countLogs(t, database))
+		assert.ErrorIs(t, persister.PersistLog("info", "too late", fields), logger.ErrPersisterClosed)
+	})
+
+	t.Run("Full batches are written without waiting", func(t *testing.T) {
+		database := setupBatchPersisterDB(t)
+		persister := logger.NewBatchPersister(database, logger.BatchConfig{BatchSize: 5, FlushInterval: time.Hour})
+		defer closePersister(t, persister)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, persister.PersistLog("info", "batched", fields))
+		}
+		assert.Eventually(t, func() bool { return countLogs(t, database) == 5 }, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Partial batches are written after the flush interval", func(t *testing.T) {
+		database := setupBatchPersisterDB(t)
+		persister := logger.NewBatchPersister(database, logger.BatchConfig{BatchSize: 1000, FlushInterval: 20 * time.Millisecond})
+		defer closePersister(t, persister)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, persister.PersistLog("info", "ticked", fields))
+		}
+		assert.Eventually(t, func() bool { return countLogs(t, database) == 3 }, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Lines are dropped while the buffer is full", func(t *testing.T) {
+		database := setupBatchPersisterDB(t)
+		persister := logger.NewBatchPersister(database, logger.BatchConfig{BufferSize: 2, BatchSize: 1, FlushInterval: time.Hour})
+
+		// Holding the only connection stalls the worker at its first write
+		tx := database.Begin()
+		require.NoError(t, tx.Error)
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, persister.PersistLog("info", "flood", fields))
+		}
+		dropped := persister.Dropped()
+		assert.GreaterOrEqual(t, dropped, int64(7))
+
+		require.NoError(t, tx.Rollback().Error)
+		closePersister(t, persister)
+		assert.Equal(t, 10-dropped, countLogs(t, database))
+	})
+}
+
+func TestLogBatchConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_BUFFER_SIZE", "")
+	t.Setenv("LOG_BATCH_SIZE", "50")
+	t.Setenv("LOG_FLUSH_INTERVAL", "250ms")
+	t.Setenv("LOG_OVERFLOW", "block")
+
+	cfg, err := config.NewLogBatchConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, logger.BatchConfig{
+		BufferSize:    logger.DefaultBatchConfig.BufferSize,
+		BatchSize:     50,
+		FlushInterval: 250 * time.Millisecond,
+		Overflow:      logger.OverflowBlock,
+	}, cfg)
+
+	for name, value := range map[string]string{
+		"LOG_BATCH_SIZE":     "0",
+		"LOG_FLUSH_INTERVAL": "soon",
+		"LOG_OVERFLOW":       "spill",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := config.NewLogBatchConfigFromEnv()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// bundleMenu is a combo of a fixed main and a drink picked from a choice group
+type bundleMenu struct {
+	db                              *gorm.DB
+	products                        *service.ProductService
+	orders                          *service.OrderService
+	categoryID                      uuid.UUID
+	combo, burger, tea, juice, soda uuid.UUID
+	fries                           uuid.UUID
+	mainSlot, drinkSlot             uuid.UUID
+}
+
+func setupBundleMenu(t *testing.T) *bundleMenu {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createOrderTables(t, db)
+
+	m := &bundleMenu{
+		db:         db,
+		products:   &service.ProductService{Repo: &repository.ProductRepository{DB: db}},
+		categoryID: uuid.New(),
+	}
+	m.orders = &service.OrderService{
+		Repo:          &repository.OrderRepository{DB: db},
+		ProductRepo:   m.products.Repo,
+		VariationRepo: &repository.VariationRepository{DB: db},
+	}
+	require.NoError(t, db.Exec(`INSERT INTO categories (id, name) VALUES (?, ?)`, m.categoryID, "Combos").Error)
+
+	m.burger = m.createProduct(t, &domain.Product{Name: "Burger", BasePrice: 40000})
+	m.tea = m.createProduct(t, &domain.Product{Name: "Iced Tea", BasePrice: 8000})
+	m.juice = m.createProduct(t, &domain.Product{Name: "Orange Juice", BasePrice: 15000})
+	m.soda = m.createProduct(t, &domain.Product{Name: "Soda", BasePrice: 10000})
+	m.fries = m.createProduct(t, &domain.Product{Name: "Fries", BasePrice: 12000})
+	require.NoError(t, db.Model(&domain.Product{}).Where("id = ?", m.soda).Update("is_available", false).Error)
+
+	m.combo = m.createProduct(t, &domain.Product{
+		Name:      "Burger Combo",
+		BasePrice: 50000,
+		IsBundle:  true,
+		BundleItems: []domain.BundleItem{
+			{Name: "Main", ProductID: &m.burger, Quantity: 2, Position: 0},
+			{Name: "Drink", Quantity: 1, Position: 1, Choices: []domain.BundleChoice{
+				{ProductID: m.tea},
+				{ProductID: m.juice, PriceModifier: 5000},
+				{ProductID: m.soda},
+			}},
+		},
+	})
+
+	combo, err := m.products.GetProductByID(m.combo)
+	require.NoError(t, err)
+	require.Len(t, combo.BundleItems, 2)
+	m.mainSlot = combo.BundleItems[0].ID
+	m.drinkSlot = combo.BundleItems[1].ID
+	return m
+}
+
+// createProduct stores p the way the product handler does, validating it first
+func (m *bundleMenu) createProduct(t *testing.T, p *domain.Product) uuid.UUID {
+	t.Helper()
+	p.CategoryID = &m.categoryID
+	require.NoError(t, service.ValidateProduct(m.db, p))
+	created, _, err := m.products.CreateProductWithVariations(p, nil)
+	require.NoError(t, err)
+	return created.ID
+}
+
+// comboLine is an order line for the combo with the drink picked from its choice group
+func (m *bundleMenu) comboLine(quantity int, drink uuid.UUID) domain.OrderDetail {
+	return domain.OrderDetail{
+		ProductID: &m.combo,
+		Quantity:  quantity,
+		Components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot, ProductID: &drink},
+		},
+	}
+}
+
+func (m *bundleMenu) placeOrder(details ...domain.OrderDetail) (*domain.Order, error) {
+	order := &domain.Order{CustomerName: "Budi", CustomerPhone: "08123456789", TableNumber: 4}
+	return m.orders.CreateOrder(order, details)
+}
+
+func TestBundleOrderExpandsComponents(t *testing.T) {
+	m := setupBundleMenu(t)
+
+	order, err := m.placeOrder(m.comboLine(3, m.juice))
+	require.NoError(t, err)
+	require.Len(t, order.OrderDetails, 1)
+
+	line := order.OrderDetails[0]
+	assert.Equal(t, "Burger Combo", line.ProductName)
+	require.Len(t, line.Components, 2)
+
+	components := make(map[uuid.UUID]domain.OrderDetailComponent, len(line.Components))
+	for _, component := range line.Components {
+		require.NotNil(t, component.BundleItemID)
+		components[*component.BundleItemID] = component
+	}
+
+	main := components[m.mainSlot]
+	assert.Equal(t, m.burger, *main.ProductID)
+	assert.Equal(t, "Burger", main.ProductName)
+	assert.Equal(t, 6, main.Quantity, "slot quantity is multiplied by the line quantity")
+
+	drink := components[m.drinkSlot]
+	assert.Equal(t, m.juice, *drink.ProductID)
+	assert.Equal(t, "Orange Juice", drink.ProductName)
+	assert.Equal(t, 3, drink.Quantity)
+	assert.Equal(t, 5000.0, drink.PriceModifier)
+}
+
+func TestBundleOrderPricesChoiceSurcharges(t *testing.T) {
+	m := setupBundleMenu(t)
+
+	order, err := m.placeOrder(m.comboLine(3, m.juice), m.comboLine(2, m.tea))
+	require.NoError(t, err)
+	require.Len(t, order.OrderDetails, 2)
+
+	prices := make(map[uuid.UUID]domain.OrderDetail, 2)
+	for _, line := range order.OrderDetails {
+		for _, component := range line.Components {
+			if *component.BundleItemID == m.drinkSlot {
+				prices[*component.ProductID] = line
+			}
+		}
+	}
+
+	withJuice := prices[m.juice]
+	assert.Equal(t, 55000.0, withJuice.UnitPrice, "the surcharge of the chosen drink is added to the bundle price")
+	assert.Equal(t, 165000.0, withJuice.TotalPrice)
+
+	withTea := prices[m.tea]
+	assert.Equal(t, 50000.0, withTea.UnitPrice)
+	assert.Equal(t, 100000.0, withTea.TotalPrice)
+
+	assert.Equal(t, 265000.0, order.TotalAmount)
+}
+
+func TestBundleOrderRejectsInvalidSelections(t *testing.T) {
+	m := setupBundleMenu(t)
+	unknownSlot := uuid.New()
+
+	tests := []struct {
+		name       string
+		components []domain.OrderDetailComponent
+	}{
+		{name: "Missing Required Choice", components: nil},
+		{name: "Choice Outside Group", components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot, ProductID: &m.fries},
+		}},
+		{name: "Unavailable Choice", components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot, ProductID: &m.soda},
+		}},
+		{name: "Unknown Bundle Item", components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot, ProductID: &m.tea},
+			{BundleItemID: &unknownSlot, ProductID: &m.tea},
+		}},
+		{name: "Choice Without Product", components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := domain.OrderDetail{ProductID: &m.combo, Quantity: 1, Components: tt.components}
+			_, err := m.placeOrder(line)
+			assert.ErrorIs(t, err, service.ErrInvalidBundleSelection)
+		})
+	}
+
+	t.Run("Choices For A Product That Is Not A Bundle", func(t *testing.T) {
+		line := domain.OrderDetail{ProductID: &m.burger, Quantity: 1, Components: []domain.OrderDetailComponent{
+			{BundleItemID: &m.drinkSlot, ProductID: &m.tea},
+		}}
+		_, err := m.placeOrder(line)
+		assert.ErrorIs(t, err, service.ErrInvalidBundleSelection)
+	})
+
+	var count int64
+	require.NoError(t, m.db.Model(&domain.Order{}).Count(&count).Error)
+	assert.Zero(t, count, "rejected orders are not stored")
+}
+
+func TestValidateBundleRejectsInvalidContents(t *testing.T) {
+	m := setupBundleMenu(t)
+	missing := uuid.New()
+
+	tests := []struct {
+		name    string
+		product domain.Product
+	}{
+		{name: "Bundle Without Items", product: domain.Product{IsBundle: true}},
+		{name: "Items On A Regular Product", product: domain.Product{
+			BundleItems: []domain.BundleItem{{Name: "Main", ProductID: &m.burger, Quantity: 1}},
+		}},
+		{name: "Empty Slot Name", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: " ", ProductID: &m.burger, Quantity: 1}},
+		}},
+		{name: "Zero Quantity", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Main", ProductID: &m.burger}},
+		}},
+		{name: "Product And Choices", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Main", ProductID: &m.burger, Quantity: 1,
+				Choices: []domain.BundleChoice{{ProductID: m.fries}}}},
+		}},
+		{name: "Neither Product Nor Choices", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Main", Quantity: 1}},
+		}},
+		{name: "Duplicate Choice", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Drink", Quantity: 1,
+				Choices: []domain.BundleChoice{{ProductID: m.tea}, {ProductID: m.tea, PriceModifier: 1000}}}},
+		}},
+		{name: "Unknown Component", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Main", ProductID: &missing, Quantity: 1}},
+		}},
+		{name: "Nested Bundle", product: domain.Product{IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Combo", ProductID: &m.combo, Quantity: 1}},
+		}},
+		{name: "Slot Of Another Bundle", product: domain.Product{ID: uuid.New(), IsBundle: true,
+			BundleItems: []domain.BundleItem{{ID: m.mainSlot, Name: "Main", ProductID: &m.burger, Quantity: 1}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := tt.product
+			product.Name = "Invalid Combo"
+			product.BasePrice = 50000
+			product.CategoryID = &m.categoryID
+			assert.ErrorIs(t, service.ValidateProduct(m.db, &product), service.ErrInvalidBundle)
+		})
+	}
+
+	t.Run("Bundle Containing Itself", func(t *testing.T) {
+		product := domain.Product{ID: m.combo, Name: "Burger Combo", BasePrice: 50000, IsBundle: true,
+			BundleItems: []domain.BundleItem{{Name: "Combo", ProductID: &m.combo, Quantity: 1}},
+		}
+		assert.ErrorIs(t, service.ValidateProductForUpdate(m.db, &product), service.ErrInvalidBundle)
+	})
+}
+
+func TestBundleUpdateSyncsItems(t *testing.T) {
+	m := setupBundleMenu(t)
+
+	// Keep the drink slot with a new set of choices, drop the main and add a side
+	update := &domain.Product{
+		Name:      "Burger Combo",
+		BasePrice: 55000,
+		IsBundle:  true,
+		BundleItems: []domain.BundleItem{
+			{ID: m.drinkSlot, Name: "Drink", Quantity: 1, Position: 0, Choices: []domain.BundleChoice{
+				{ProductID: m.juice, PriceModifier: 3000},
+			}},
+			{Name: "Side", ProductID: &m.fries, Quantity: 1, Position: 1},
+		},
+	}
+	update.ID = m.combo
+	require.NoError(t, service.ValidateProductForUpdate(m.db, update))
+	updated, _, err := m.products.UpdateProductWithVariations(m.combo, update, nil, false)
+	require.NoError(t, err)
+
+	require.Len(t, updated.BundleItems, 2)
+	assert.Equal(t, m.drinkSlot, updated.BundleItems[0].ID, "slots sent with their ID are kept")
+	require.Len(t, updated.BundleItems[0].Choices, 1)
+	assert.Equal(t, m.juice, updated.BundleItems[0].Choices[0].ProductID)
+	assert.Equal(t, 3000.0, updated.BundleItems[0].Choices[0].PriceModifier)
+	assert.Equal(t, "Side", updated.BundleItems[1].Name)
+
+	var count int64
+	require.NoError(t, m.db.Model(&domain.BundleItem{}).Where("id = ?", m.mainSlot).Count(&count).Error)
+	assert.Zero(t, count, "slots left out of the update are removed")
+	require.NoError(t, m.db.Model(&domain.BundleChoice{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "choices of replaced and removed slots are removed")
+
+	// Turning the bundle into a regular product removes its contents
+	update = &domain.Product{Name: "Burger Combo", BasePrice: 55000}
+	_, _, err = m.products.UpdateProductWithVariations(m.combo, update, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, m.db.Model(&domain.BundleItem{}).Where("bundle_id = ?", m.combo).Count(&count).Error)
+	assert.Zero(t, count)
+}
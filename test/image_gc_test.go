@@ -0,0 +1,65 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestImageGCRemovesUnreferencedImages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	// Only the columns the collector reads
+	require.NoError(t, db.Exec(`CREATE TABLE "products" ("id" TEXT PRIMARY KEY, "deleted_at" DATETIME, "image_url" TEXT, "images" TEXT)`).Error)
+	require.NoError(t, db.Exec(`CREATE TABLE "themes" ("id" TEXT PRIMARY KEY, "deleted_at" DATETIME, "logo_url" TEXT, "favicon_url" TEXT)`).Error)
+
+	uploader := utils.NewMemoryUploader("/uploads")
+	for _, key := range []string{
+		"products/a/full.webp",
+		"products/a/thumbnail.webp",
+		"products/logo.png",
+		"products/orphan.jpg",
+		"products/recent.jpg",
+		"themes/other.png",
+	} {
+		_, err := uploader.Upload(strings.NewReader("x"), key)
+		require.NoError(t, err)
+		uploader.SetModTime(key, time.Now().Add(-48*time.Hour))
+	}
+	uploader.SetModTime("products/recent.jpg", time.Now())
+
+	require.NoError(t, db.Exec(`INSERT INTO products (id, image_url, images) VALUES (?, ?, ?)`,
+		"5d1f5b3e-4c34-4c8e-9a55-0c6f3f4b2a11", "/uploads/products/a/full.webp", `{"thumbnail":"/uploads/products/a/thumbnail.webp","full":"/uploads/products/a/full.webp"}`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO themes (id, logo_url, favicon_url) VALUES (?, ?, ?)`,
+		"8b0e7f52-1b8a-4f7e-b8f4-3f0c2d9e6a77", "/uploads/products/logo.png", "data:image/png;base64,AAAA").Error)
+
+	gc := &service.ImageGCService{DB: db, Uploader: uploader, MinAge: time.Hour}
+
+	result, err := gc.Run(true)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Scanned)
+	require.Len(t, result.Orphaned, 1)
+	assert.Equal(t, "products/orphan.jpg", result.Orphaned[0].Key)
+	assert.Equal(t, 0, result.Deleted)
+	exists, _ := uploader.Exists("products/orphan.jpg")
+	assert.True(t, exists, "dry run must not delete anything")
+
+	result, err = gc.Run(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, []string{
+		"products/a/full.webp",
+		"products/a/thumbnail.webp",
+		"products/logo.png",
+		"products/recent.jpg",
+		"themes/other.png",
+	}, uploader.Keys())
+}
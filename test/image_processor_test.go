@@ -0,0 +1,81 @@
+package test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "golang.org/x/image/webp"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDetectImageFormat(t *testing.T) {
+	format, err := utils.DetectImageFormat([]byte{0xFF, 0xD8, 0xFF, 0xE0})
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+
+	format, err = utils.DetectImageFormat([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "))
+	require.NoError(t, err)
+	assert.Equal(t, "webp", format)
+
+	_, err = utils.DetectImageFormat([]byte("GIF89a"))
+	assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+}
+
+func TestProcessImageRenditions(t *testing.T) {
+	renditions := []utils.ImageRendition{
+		{Name: "thumbnail", MaxWidth: 50, MaxHeight: 50},
+		{Name: "full", MaxWidth: 1000, MaxHeight: 1000},
+	}
+	limits := utils.ImageLimits{MaxBytes: 1 << 20, MinWidth: 10, MinHeight: 10, MaxWidth: 500, MaxHeight: 500}
+
+	processed, err := utils.ProcessImage(bytes.NewReader(encodeTestPNG(t, 200, 100)), limits, renditions)
+	require.NoError(t, err)
+	require.Len(t, processed, 2)
+
+	// Scaled down keeping the aspect ratio
+	assert.Equal(t, 50, processed[0].Width)
+	assert.Equal(t, 25, processed[0].Height)
+	// Never scaled up
+	assert.Equal(t, 200, processed[1].Width)
+	assert.Equal(t, 100, processed[1].Height)
+
+	for _, p := range processed {
+		assert.Equal(t, "image/webp", p.ContentType)
+		config, format, err := image.DecodeConfig(bytes.NewReader(p.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "webp", format)
+		assert.Equal(t, p.Width, config.Width)
+	}
+}
+
+func TestProcessImageRejectsInvalidUploads(t *testing.T) {
+	limits := utils.ImageLimits{MaxBytes: 1 << 20, MinWidth: 100, MinHeight: 100, MaxWidth: 500, MaxHeight: 500}
+
+	_, err := utils.ProcessImage(bytes.NewReader(encodeTestPNG(t, 50, 50)), limits, utils.ProductImageRenditions)
+	assert.ErrorIs(t, err, utils.ErrInvalidImageSize)
+
+	_, err = utils.ProcessImage(bytes.NewReader([]byte("<svg></svg>")), limits, utils.ProductImageRenditions)
+	assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+
+	limits.MaxBytes = 16
+	_, err = utils.ProcessImage(bytes.NewReader(encodeTestPNG(t, 200, 200)), limits, utils.ProductImageRenditions)
+	assert.ErrorIs(t, err, utils.ErrImageTooLarge)
+}
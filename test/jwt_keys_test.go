@@ -0,0 +1,159 @@
+package test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeypair stores a keypair the way cmd/keygen does and returns the key files and ID
+func writeKeypair(t *testing.T, private crypto.Signer) (privatePath, publicPath, keyID string) {
+	keyID, err := jwt.KeyID(private.Public())
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath = filepath.Join(dir, keyID+".key")
+	publicPath = filepath.Join(dir, keyID+".pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644))
+	return privatePath, publicPath, keyID
+}
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return private
+}
+
+func issueToken(t *testing.T) string {
+	token, err := jwt.GenerateToken(uuid.New(), uuid.New(), "Admin", false, nil)
+	require.NoError(t, err)
+	return token
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	parsed, _, err := gojwt.NewParser().ParseUnverified(token, &jwt.Claims{})
+	require.NoError(t, err)
+	keyID, _ := parsed.Header["kid"].(string)
+	return keyID
+}
+
+func TestAsymmetricSigning(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for alg, private := range map[string]crypto.Signer{jwt.AlgRS256: rsaKey, jwt.AlgEdDSA: newEd25519Key(t)} {
+		t.Run(alg, func(t *testing.T) {
+			privatePath, _, keyID := writeKeypair(t, private)
+			t.Setenv("JWT_SECRET_KEY", "")
+			t.Setenv("JWT_SIGNING_ALG", alg)
+			t.Setenv("JWT_PRIVATE_KEY_FILE", privatePath)
+			t.Setenv("JWT_PUBLIC_KEY_FILES", "")
+			t.Setenv("JWT_ACCEPT_HS256_UNTIL", "")
+			require.NoError(t, jwt.CheckKeys())
+
+			token := issueToken(t)
+			assert.Equal(t, keyID, tokenKeyID(t, token))
+			_, err := jwt.ValidateToken(token)
+			require.NoError(t, err)
+
+			set, err := jwt.PublicKeySet()
+			require.NoError(t, err)
+			require.Len(t, set.Keys, 1)
+			assert.Equal(t, keyID, set.Keys[0].Kid)
+			assert.Equal(t, alg, set.Keys[0].Alg)
+		})
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	oldPrivate, oldPublic, oldKeyID := writeKeypair(t, newEd25519Key(t))
+	newPrivate, _, newKeyID := writeKeypair(t, newEd25519Key(t))
+
+	t.Setenv("JWT_SECRET_KEY", "rotation-test-secret")
+	t.Setenv("JWT_SIGNING_ALG", jwt.AlgHS256)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_PUBLIC_KEY_FILES", "")
+	t.Setenv("JWT_ACCEPT_HS256_UNTIL", "")
+	hmacToken := issueToken(t)
+
+	t.Setenv("JWT_SIGNING_ALG", jwt.AlgEdDSA)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", oldPrivate)
+	oldToken := issueToken(t)
+
+	t.Setenv("JWT_PRIVATE_KEY_FILE", newPrivate)
+	assert.Equal(t, newKeyID, tokenKeyID(t, issueToken(t)))
+
+	t.Run("Tokens of an unlisted key are rejected", func(t *testing.T) {
+		_, err := jwt.ValidateToken(oldToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Tokens of a listed previous key are accepted", func(t *testing.T) {
+		t.Setenv("JWT_PUBLIC_KEY_FILES", oldPublic)
+		_, err := jwt.ValidateToken(oldToken)
+		require.NoError(t, err)
+
+		set, err := jwt.PublicKeySet()
+		require.NoError(t, err)
+		require.Len(t, set.Keys, 2)
+		assert.Equal(t, newKeyID, set.Keys[0].Kid, "the signing key is listed first")
+		assert.Equal(t, oldKeyID, set.Keys[1].Kid)
+	})
+
+	t.Run("HS256 tokens are only accepted during the rotation window", func(t *testing.T) {
+		_, err := jwt.ValidateToken(hmacToken)
+		assert.Error(t, err, "rejected by default once an asymmetric key signs")
+
+		t.Setenv("JWT_ACCEPT_HS256_UNTIL", time.Now().Add(time.Hour).Format(time.RFC3339))
+		_, err = jwt.ValidateToken(hmacToken)
+		require.NoError(t, err)
+
+		t.Setenv("JWT_ACCEPT_HS256_UNTIL", time.Now().Add(-time.Second).Format(time.RFC3339))
+		_, err = jwt.ValidateToken(hmacToken)
+		assert.Error(t, err, "rejected after the window")
+	})
+}
+
+func TestInvalidSigningKeys(t *testing.T) {
+	privatePath, _, _ := writeKeypair(t, newEd25519Key(t))
+	t.Setenv("JWT_SECRET_KEY", "")
+	t.Setenv("JWT_PUBLIC_KEY_FILES", "")
+	t.Setenv("JWT_ACCEPT_HS256_UNTIL", "")
+
+	t.Setenv("JWT_SIGNING_ALG", jwt.AlgRS256)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", privatePath)
+	assert.Error(t, jwt.CheckKeys(), "the key must match the algorithm")
+
+	t.Setenv("JWT_SIGNING_ALG", "none")
+	assert.Error(t, jwt.CheckKeys())
+
+	t.Setenv("JWT_SIGNING_ALG", jwt.AlgHS256)
+	assert.Error(t, jwt.CheckKeys(), "HS256 needs a secret")
+
+	t.Setenv("JWT_SIGNING_ALG", jwt.AlgEdDSA)
+	t.Setenv("JWT_ACCEPT_HS256_UNTIL", "2030-01-01T00:00:00Z")
+	assert.Error(t, jwt.CheckKeys(), "the HS256 window needs a secret")
+
+	t.Setenv("JWT_SECRET_KEY", "invalid-keys-secret")
+	t.Setenv("JWT_ACCEPT_HS256_UNTIL", "next week")
+	assert.Error(t, jwt.CheckKeys())
+}
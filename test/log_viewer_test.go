@@ -0,0 +1,125 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createLogsTable adds the logs table to a test database
+func createLogsTable(t *testing.T, database *gorm.DB) {
+	require.NoError(t, database.Exec(`CREATE TABLE "logs" ("id" TEXT PRIMARY KEY, "timestamp" DATETIME, "level" TEXT, "source" TEXT,
+		"user_id" TEXT, "ip_address" TEXT, "action" TEXT, "entity" TEXT, "entity_id" TEXT, "description" TEXT, "metadata" TEXT,
+		"request_id" TEXT, "environment" TEXT, "application" TEXT, "hostname" TEXT, "type" TEXT)`).Error)
+}
+
+func TestLogViewer(t *testing.T) {
+	database := setupRouteTestDB(t)
+	createLogsTable(t, database)
+	_, token := createRoleUser(t, database, middleware.RoleOwner)
+	_, adminToken := createRoleUser(t, database, middleware.RoleAdmin)
+	app := SetupTestApp(database)
+
+	productID := uuid.New()
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		entry := domain.Log{
+			ID:        uuid.New(),
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Level:     "info",
+			Source:    "handler",
+			Action:    "order.created",
+			Entity:    "order",
+			Metadata:  db.JSONB{"route": "/api/v1/orders"},
+			Type:      "activity",
+		}
+		if i == 3 {
+			entry.Level, entry.Action, entry.Entity, entry.EntityID, entry.Type = "audit", "product.updated", "product", &productID, "audit"
+			entry.Metadata = db.JSONB{"route": "/api/v1/products/:id"}
+		}
+		require.NoError(t, database.Create(&entry).Error)
+	}
+
+	list := func(auth string, query url.Values) (int, dto.LogPageResponse) {
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/logs?"+query.Encode(), nil)
+		req.Header.Set("Authorization", "Bearer "+auth)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var page struct {
+			Data dto.LogPageResponse `json:"data"`
+		}
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.Unmarshal(body, &page))
+		}
+		return resp.StatusCode, page.Data
+	}
+
+	t.Run("Requires read:log", func(t *testing.T) {
+		status, _ := list(adminToken, url.Values{})
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("Filters on columns and metadata", func(t *testing.T) {
+		status, page := list(token, url.Values{"entity": {"product"}, "entity_id": {productID.String()}})
+		require.Equal(t, fiber.StatusOK, status)
+		require.Len(t, page.Logs, 1)
+		assert.Equal(t, "product.updated", page.Logs[0].Action)
+
+		status, page = list(token, url.Values{"metadata.route": {"/api/v1/products/:id"}})
+		require.Equal(t, fiber.StatusOK, status)
+		require.Len(t, page.Logs, 1)
+		assert.Equal(t, productID, *page.Logs[0].EntityID)
+
+		status, page = list(token, url.Values{"level": {"info"}, "from": {start.Add(90 * time.Second).Format(time.RFC3339)}})
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Len(t, page.Logs, 2)
+	})
+
+	t.Run("Pages follow the cursor newest first", func(t *testing.T) {
+		var seen []time.Time
+		query := url.Values{"source": {"handler"}, "limit": {"2"}}
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 5)
+			status, page := list(token, query)
+			require.Equal(t, fiber.StatusOK, status)
+			for _, entry := range page.Logs {
+				seen = append(seen, entry.Timestamp)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", page.NextCursor)
+		}
+		require.Len(t, seen, 5)
+		for i := 1; i < len(seen); i++ {
+			assert.True(t, seen[i].Before(seen[i-1]))
+		}
+	})
+
+	t.Run("Rejects invalid filters", func(t *testing.T) {
+		for _, query := range []url.Values{
+			{"cursor": {"not-a-cursor"}},
+			{"user_id": {"42"}},
+			{"from": {"yesterday"}},
+			{"metadata.route') OR ('1": {"x"}},
+		} {
+			status, _ := list(token, query)
+			assert.Equal(t, fiber.StatusBadRequest, status, query.Encode())
+		}
+	})
+}
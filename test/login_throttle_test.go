@@ -0,0 +1,214 @@
+package test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// recordingLogger keeps the fields and action of every log entry
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []map[string]interface{}
+	actions []string
+}
+
+func (l *recordingLogger) record(fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fields)
+	if action, ok := fields["action"].(string); ok {
+		l.actions = append(l.actions, action)
+	}
+}
+
+func (l *recordingLogger) LogDebug(msg string, fields map[string]interface{}) { l.record(fields) }
+func (l *recordingLogger) LogInfo(msg string, fields map[string]interface{})  { l.record(fields) }
+func (l *recordingLogger) LogError(msg string, fields map[string]interface{}) { l.record(fields) }
+
+func setupLoginThrottle(t *testing.T) (*service.UserService, *gorm.DB, *recordingLogger) {
+	t.Setenv("JWT_SECRET_KEY", "login-throttle-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// Every connection to :memory: opens a new database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER, "last_failed_at" DATETIME, "locked_until" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	for _, email := range []string{"cashier@example.com", "waiter@example.com"} {
+		password, err := crypto.HashPassword("correct-password")
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New(), email, email, password, true, time.Now()).Error)
+	}
+
+	logger := &recordingLogger{}
+	policy := service.LoginPolicy{MaxFailures: 3, BaseLockout: time.Minute, MaxLockout: time.Hour, FailureWindow: 15 * time.Minute}
+	return &service.UserService{
+		Repo: &repository.UserRepository{DB: db},
+		Throttle: &service.LoginThrottle{
+			Repo:          &repository.LoginFailureRepository{DB: db},
+			Logger:        logger,
+			AccountPolicy: policy,
+			IPPolicy:      service.LoginPolicy{MaxFailures: 5, BaseLockout: time.Minute, MaxLockout: time.Hour, FailureWindow: 15 * time.Minute},
+		},
+	}, db, logger
+}
+
+func lockedUntil(t *testing.T, db *gorm.DB, key string) time.Time {
+	var failure domain.LoginFailure
+	require.NoError(t, db.Where("key = ?", key).First(&failure).Error)
+	require.NotNil(t, failure.LockedUntil)
+	return *failure.LockedUntil
+}
+
+func TestAccountLockout(t *testing.T) {
+	users, db, logger := setupLoginThrottle(t)
+	wrong := &dto.LoginRequest{Email: "cashier@example.com", Password: "wrong"}
+	right := &dto.LoginRequest{Email: "cashier@example.com", Password: "correct-password"}
+
+	for i := 0; i < 3; i++ {
+		_, err := users.LoginUser(wrong, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+	firstLock := time.Until(lockedUntil(t, db, "account:cashier@example.com"))
+	assert.InDelta(t, time.Minute.Seconds(), firstLock.Seconds(), 5)
+
+	// Locked out even with the right password, from another address too
+	_, err := users.LoginUser(right, "10.0.0.2")
+	var locked *service.LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+
+	assert.Contains(t, logger.actions, "auth.login_failed")
+	assert.Contains(t, logger.actions, "auth.login_locked")
+	assert.Contains(t, logger.actions, "auth.login_blocked")
+
+	// Each further failure doubles the lockout
+	require.NoError(t, db.Exec(`UPDATE login_failures SET locked_until = ? WHERE key = ?`,
+		time.Now().Add(-time.Second), "account:cashier@example.com").Error)
+	_, err = users.LoginUser(wrong, "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	secondLock := time.Until(lockedUntil(t, db, "account:cashier@example.com"))
+	assert.InDelta(t, (2 * time.Minute).Seconds(), secondLock.Seconds(), 5)
+
+	// An admin unlock lets the user in again
+	var userID string
+	require.NoError(t, db.Raw(`SELECT id FROM users WHERE email = ?`, "cashier@example.com").Scan(&userID).Error)
+	require.NoError(t, users.UnlockUser(builtInRolePositions[middleware.RoleAdmin], uuid.MustParse(userID)))
+	_, err = users.LoginUser(right, "10.0.0.3")
+	require.NoError(t, err)
+	assert.Contains(t, logger.actions, "auth.login_unlocked")
+}
+
+func TestIPLockout(t *testing.T) {
+	users, _, _ := setupLoginThrottle(t)
+
+	// Spread over two accounts so neither account reaches its own limit
+	for i := 0; i < 5; i++ {
+		email := "cashier@example.com"
+		if i%2 == 1 {
+			email = "waiter@example.com"
+		}
+		_, err := users.LoginUser(&dto.LoginRequest{Email: email, Password: "wrong"}, "10.0.0.9")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+
+	_, err := users.LoginUser(&dto.LoginRequest{Email: "waiter@example.com", Password: "correct-password"}, "10.0.0.9")
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+
+	// Other addresses are unaffected
+	_, err = users.LoginUser(&dto.LoginRequest{Email: "waiter@example.com", Password: "correct-password"}, "10.0.0.10")
+	assert.NoError(t, err)
+}
+
+func TestConcurrentLoginFailures(t *testing.T) {
+	users, db, _ := setupLoginThrottle(t)
+	wrong := &dto.LoginRequest{Email: "cashier@example.com", Password: "wrong"}
+
+	errs := make(chan error, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := users.LoginUser(wrong, "10.0.0.1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Only the attempts the policy allows get their password checked
+	var invalid, locked int
+	for err := range errs {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			invalid++
+		case errors.Is(err, service.ErrLoginLocked):
+			locked++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 3, invalid)
+	assert.Equal(t, 17, locked)
+
+	for _, key := range []string{"account:cashier@example.com", "ip:10.0.0.1"} {
+		var failure domain.LoginFailure
+		require.NoError(t, db.Where("key = ?", key).First(&failure).Error)
+		assert.Equal(t, 3, failure.Failures, key)
+	}
+}
+
+func TestUnlockRequiresHigherRole(t *testing.T) {
+	db := setupRouteTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER,
+		"last_failed_at" DATETIME, "locked_until" DATETIME)`).Error)
+	ownerID, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+	_, adminToken := createRoleUser(t, db, middleware.RoleAdmin)
+	require.NoError(t, db.Exec(`INSERT INTO login_failures ("key", failures, last_failed_at, locked_until) VALUES (?, ?, ?, ?)`,
+		"account:owner@example.com", 5, time.Now(), time.Now().Add(time.Hour)).Error)
+	app := SetupTestApp(db)
+
+	unlock := func(token string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/api/v1/users/"+ownerID.String()+"/unlock", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, unlock(adminToken))
+	assert.Equal(t, fiber.StatusForbidden, unlock(ownerToken), "users cannot lift their own lockout")
+	var locks int64
+	require.NoError(t, db.Table("login_failures").Count(&locks).Error)
+	assert.Equal(t, int64(1), locks)
+
+	_, systemToken := createRoleUser(t, db, middleware.RoleSystem)
+	assert.Equal(t, fiber.StatusOK, unlock(systemToken))
+	require.NoError(t, db.Table("login_failures").Count(&locks).Error)
+	assert.Zero(t, locks)
+}
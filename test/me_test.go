@@ -0,0 +1,82 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPermissions(t *testing.T) {
+	known := []string{"read:menu", "update:menu", "read:order", "read:table"}
+
+	assert.Equal(t, []string{"read:menu", "read:order", "read:table"},
+		middleware.ExpandPermissions([]string{"read:*"}, known))
+	assert.Equal(t, []string{"create:order", "delete:order", "manage:order", "read:order", "update:order"},
+		middleware.ExpandPermissions([]string{"manage:order"}, known))
+	assert.Equal(t, []string{"full:access", "read:menu", "read:order", "read:table", "update:menu"},
+		middleware.ExpandPermissions([]string{"full:access"}, known))
+	assert.Equal(t, []string{"manage:roles", "manage:users"},
+		middleware.ExpandPermissions([]string{"manage:users", "manage:roles"}, known))
+	assert.Empty(t, middleware.ExpandPermissions(nil, known))
+}
+
+func TestMeEndpoints(t *testing.T) {
+	db := setupRouteTestDB(t)
+	userID, token := createRoleUser(t, db, middleware.RoleWaiter)
+	app := SetupTestApp(db)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/api/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var me struct {
+		Data dto.MeResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+	assert.Equal(t, userID, me.Data.User.ID)
+	assert.Equal(t, middleware.RoleWaiter, me.Data.Role.Name)
+	assert.ElementsMatch(t, middleware.GetDefaultPermissionsForRole(middleware.RoleWaiter), me.Data.Permissions)
+
+	body, err := json.Marshal(dto.PermissionCheckRequest{Permissions: []string{"read:menu", "delete:menu"}})
+	require.NoError(t, err)
+	req = httptest.NewRequest(fiber.MethodPost, "/api/v1/me/can", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var can struct {
+		Data dto.PermissionCheckResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&can))
+	assert.Equal(t, map[string]bool{"read:menu": true, "delete:menu": false}, can.Data.Results)
+
+	t.Run("Special permissions do not expand into CRUD permissions", func(t *testing.T) {
+		_, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var owner struct {
+			Data dto.MeResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&owner))
+		assert.ElementsMatch(t, middleware.GetDefaultPermissionsForRole(middleware.RoleOwner), owner.Data.Permissions)
+		for _, phantom := range []string{"read:users", "create:roles", "delete:permissions"} {
+			assert.NotContains(t, owner.Data.Permissions, phantom)
+		}
+	})
+}
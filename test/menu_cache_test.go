@@ -0,0 +1,35 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMenuCacheInvalidate(t *testing.T) {
+	cache := &service.MenuCache{}
+	loads := 0
+	load := func() (*dto.PublicMenuResponse, error) {
+		loads++
+		return &dto.PublicMenuResponse{
+			Categories: []dto.PublicMenuCategory{{Name: "Drinks", Position: loads}},
+		}, nil
+	}
+
+	first, err := cache.GetOrLoad(load)
+	require.NoError(t, err)
+	cached, err := cache.GetOrLoad(load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loads)
+	assert.Same(t, first, cached)
+
+	cache.Invalidate()
+
+	reloaded, err := cache.GetOrLoad(load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads)
+	assert.Equal(t, 2, reloaded.Categories[0].Position)
+}
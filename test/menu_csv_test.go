@@ -0,0 +1,111 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const sampleMenuCSV = `category,category_position,category_active,product,description,base_price,product_available,product_position,variation_type,variation_required,option_label,option_price_modifier,option_default
+Drinks,1,true,Iced Tea,Fresh brewed,15000,true,1,Size,true,Regular,,true
+Drinks,,,Iced Tea,,,,,Size,,Large,5000,false
+Drinks,,,Coffee,Hot coffee,20000,true,2,,,,,
+Desserts,2,false,,,,,,,,,,
+`
+
+func TestParseMenuCSVGroupsRows(t *testing.T) {
+	req, rowErrors, err := service.ParseMenuCSV(strings.NewReader(sampleMenuCSV))
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+
+	require.Len(t, req.Categories, 2)
+	drinks := req.Categories[0]
+	assert.Equal(t, "Drinks", drinks.Name)
+	assert.Equal(t, 1, drinks.Position)
+	require.Len(t, drinks.Products, 2)
+
+	icedTea := drinks.Products[0]
+	assert.Equal(t, 15000.0, icedTea.BasePrice)
+	require.Len(t, icedTea.Variations, 1)
+	assert.True(t, icedTea.Variations[0].IsRequired)
+	require.Len(t, icedTea.Variations[0].Options, 2)
+	assert.Equal(t, "Large", icedTea.Variations[0].Options[1].Label)
+	assert.Equal(t, 5000.0, *icedTea.Variations[0].Options[1].PriceModifier)
+
+	desserts := req.Categories[1]
+	require.NotNil(t, desserts.IsActive)
+	assert.False(t, *desserts.IsActive)
+	assert.Empty(t, desserts.Products)
+}
+
+func TestParseMenuCSVReportsRowErrors(t *testing.T) {
+	input := "category,product,base_price\nDrinks,Tea,abc\n,Coffee,1000\n"
+
+	_, rowErrors, err := service.ParseMenuCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rowErrors, 2)
+	assert.Equal(t, 2, rowErrors[0].Row)
+	assert.Equal(t, "base_price", rowErrors[0].Field)
+	assert.Equal(t, 3, rowErrors[1].Row)
+	assert.Equal(t, "category", rowErrors[1].Field)
+}
+
+func TestMenuCSVRoundTrip(t *testing.T) {
+	req, _, err := service.ParseMenuCSV(strings.NewReader(sampleMenuCSV))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.WriteMenuCSV(&buf, &dto.MenuExportResponse{Categories: req.Categories}))
+
+	again, rowErrors, err := service.ParseMenuCSV(&buf)
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Equal(t, len(req.Categories), len(again.Categories))
+	assert.Equal(t, len(req.Categories[0].Products[0].Variations[0].Options), len(again.Categories[0].Products[0].Variations[0].Options))
+}
+
+func TestMenuImportKeepsUnavailableFlags(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createOrderTables(t, db)
+	menuService := &service.MenuService{DB: db}
+
+	unavailable := false
+	result, err := menuService.ImportMenu(&dto.MenuImportRequest{Categories: []dto.MenuCategoryItem{{
+		Name:     "Seasonal",
+		IsActive: &unavailable,
+		Products: []dto.MenuProductItem{{
+			Name:        "Mango Sticky Rice",
+			BasePrice:   25000,
+			IsAvailable: &unavailable,
+			Variations: []dto.MenuVariationItem{{
+				VariationType: "Size",
+				IsAvailable:   &unavailable,
+				Options:       []dto.VariationOption{{Label: "Regular", IsDefault: true}},
+			}},
+		}},
+	}}}, false)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	require.True(t, result.Applied)
+
+	var category domain.Category
+	require.NoError(t, db.First(&category, "name = ?", "Seasonal").Error)
+	assert.False(t, category.IsActive)
+
+	var product domain.Product
+	require.NoError(t, db.First(&product, "name = ?", "Mango Sticky Rice").Error)
+	assert.False(t, product.IsAvailable)
+
+	var variation domain.Variation
+	require.NoError(t, db.First(&variation, "product_id = ?", product.ID).Error)
+	assert.False(t, variation.IsAvailable)
+}
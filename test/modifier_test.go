@@ -0,0 +1,183 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// modifierMenu is a pizza with toppings, sauces and an unavailable crust group,
+// next to a pasta whose extras cannot be picked for the pizza
+type modifierMenu struct {
+	db                              *gorm.DB
+	modifiers                       *service.ModifierService
+	orders                          *service.OrderService
+	pizza, pasta                    uuid.UUID
+	toppings, sauces, crust, extras *domain.ModifierGroup
+}
+
+func setupModifierMenu(t *testing.T) *modifierMenu {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createOrderTables(t, database)
+
+	m := &modifierMenu{
+		db:        database,
+		modifiers: &service.ModifierService{Repo: &repository.ModifierRepository{DB: database}},
+		orders: &service.OrderService{
+			Repo:          &repository.OrderRepository{DB: database},
+			ProductRepo:   &repository.ProductRepository{DB: database},
+			VariationRepo: &repository.VariationRepository{DB: database},
+		},
+		pizza: uuid.New(),
+		pasta: uuid.New(),
+	}
+	require.NoError(t, database.Exec(`INSERT INTO products (id, name, base_price) VALUES (?, ?, ?), (?, ?, ?)`,
+		m.pizza, "Pizza", 60000, m.pasta, "Pasta", 45000).Error)
+
+	m.toppings = m.createGroup(t, m.pizza, &domain.ModifierGroup{Name: "Toppings", MinSelect: 1, MaxSelect: 2, IsAvailable: true,
+		Options: db.ModifierOptions{{Label: "Cheese", Price: 5000}, {Label: "Mushroom", Price: 4000}, {Label: "Olive", Price: 3000}}})
+	m.sauces = m.createGroup(t, m.pizza, &domain.ModifierGroup{Name: "Sauces", IsAvailable: true, Position: 1,
+		Options: db.ModifierOptions{{Label: "Chili"}, {Label: "Garlic", Price: 2000}}})
+	m.crust = m.createGroup(t, m.pizza, &domain.ModifierGroup{Name: "Crust", MinSelect: 1, IsAvailable: false, Position: 2,
+		Options: db.ModifierOptions{{Label: "Thin", Price: 7000}}})
+	m.extras = m.createGroup(t, m.pasta, &domain.ModifierGroup{Name: "Extras", IsAvailable: true,
+		Options: db.ModifierOptions{{Label: "Bacon", Price: 9000}}})
+	return m
+}
+
+func (m *modifierMenu) createGroup(t *testing.T, productID uuid.UUID, group *domain.ModifierGroup) *domain.ModifierGroup {
+	t.Helper()
+	created, err := m.modifiers.CreateProductModifierGroup(productID, group)
+	require.NoError(t, err)
+	return created
+}
+
+// pizzaLine is an order line for the pizza with the given picks
+func (m *modifierMenu) pizzaLine(quantity int, picks ...db.SelectedModifier) domain.OrderDetail {
+	return domain.OrderDetail{ProductID: &m.pizza, Quantity: quantity, Modifiers: picks}
+}
+
+func (m *modifierMenu) placeOrder(details ...domain.OrderDetail) (*domain.Order, error) {
+	order := &domain.Order{CustomerName: "Budi", CustomerPhone: "08123456789", TableNumber: 2}
+	return m.orders.CreateOrder(order, details)
+}
+
+func pick(group *domain.ModifierGroup, label string) db.SelectedModifier {
+	return db.SelectedModifier{GroupID: group.ID.String(), Label: label}
+}
+
+func TestModifierOrderAddsOptionPrices(t *testing.T) {
+	m := setupModifierMenu(t)
+
+	order, err := m.placeOrder(m.pizzaLine(2, pick(m.toppings, "cheese"), pick(m.toppings, "Mushroom"), pick(m.sauces, "Garlic")))
+	require.NoError(t, err)
+	require.Len(t, order.OrderDetails, 1)
+
+	line := order.OrderDetails[0]
+	assert.Equal(t, 71000.0, line.UnitPrice, "option prices are added to the base price")
+	assert.Equal(t, 142000.0, line.TotalPrice)
+	assert.Equal(t, 142000.0, order.TotalAmount)
+
+	require.Len(t, line.Modifiers, 3)
+	assert.Equal(t, db.SelectedModifier{GroupID: m.toppings.ID.String(), GroupName: "Toppings", Label: "Cheese", Price: 5000}, line.Modifiers[0],
+		"picks are stored with the group name, price and label as configured")
+	assert.Equal(t, "Sauces", line.Modifiers[2].GroupName)
+	assert.Equal(t, 2000.0, line.Modifiers[2].Price)
+
+	// Free options and groups without a minimum add nothing
+	order, err = m.placeOrder(m.pizzaLine(1, pick(m.toppings, "Olive"), pick(m.sauces, "Chili")))
+	require.NoError(t, err)
+	assert.Equal(t, 63000.0, order.OrderDetails[0].UnitPrice)
+}
+
+func TestModifierOrderRejectsInvalidSelections(t *testing.T) {
+	m := setupModifierMenu(t)
+
+	tests := []struct {
+		name  string
+		picks []db.SelectedModifier
+	}{
+		{name: "Below Minimum", picks: []db.SelectedModifier{pick(m.sauces, "Chili")}},
+		{name: "Above Maximum", picks: []db.SelectedModifier{
+			pick(m.toppings, "Cheese"), pick(m.toppings, "Mushroom"), pick(m.toppings, "Olive"),
+		}},
+		{name: "Duplicate Option", picks: []db.SelectedModifier{pick(m.toppings, "Cheese"), pick(m.toppings, " cheese ")}},
+		{name: "Unknown Group", picks: []db.SelectedModifier{
+			pick(m.toppings, "Cheese"), {GroupID: uuid.New().String(), Label: "Cheese"},
+		}},
+		{name: "Unknown Option", picks: []db.SelectedModifier{pick(m.toppings, "Pineapple")}},
+		{name: "Unavailable Group", picks: []db.SelectedModifier{pick(m.toppings, "Cheese"), pick(m.crust, "Thin")}},
+		{name: "Group Of Another Product", picks: []db.SelectedModifier{pick(m.toppings, "Cheese"), pick(m.extras, "Bacon")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.placeOrder(m.pizzaLine(1, tt.picks...))
+			assert.ErrorIs(t, err, service.ErrInvalidModifierSelection)
+		})
+	}
+
+	var count int64
+	require.NoError(t, m.db.Model(&domain.Order{}).Count(&count).Error)
+	assert.Zero(t, count, "rejected orders are not stored")
+}
+
+func TestModifierGroupValidation(t *testing.T) {
+	m := setupModifierMenu(t)
+	options := func() db.ModifierOptions {
+		return db.ModifierOptions{{Label: "Small"}, {Label: "Large", Price: 10000}}
+	}
+
+	tests := []struct {
+		name  string
+		group domain.ModifierGroup
+	}{
+		{name: "Empty Name", group: domain.ModifierGroup{Name: " ", Options: options()}},
+		{name: "No Options", group: domain.ModifierGroup{Name: "Size"}},
+		{name: "Empty Option Label", group: domain.ModifierGroup{Name: "Size", Options: db.ModifierOptions{{Label: ""}}}},
+		{name: "Duplicate Option", group: domain.ModifierGroup{Name: "Size", Options: db.ModifierOptions{{Label: "Large"}, {Label: "large "}}}},
+		{name: "Negative Price", group: domain.ModifierGroup{Name: "Size", Options: db.ModifierOptions{{Label: "Small", Price: -1000}}}},
+		{name: "Negative Minimum", group: domain.ModifierGroup{Name: "Size", MinSelect: -1, Options: options()}},
+		{name: "Minimum Above Maximum", group: domain.ModifierGroup{Name: "Size", MinSelect: 2, MaxSelect: 1, Options: options()}},
+		{name: "Minimum Above Option Count", group: domain.ModifierGroup{Name: "Size", MinSelect: 3, Options: options()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := tt.group
+			_, err := m.modifiers.CreateProductModifierGroup(m.pizza, &group)
+			assert.ErrorIs(t, err, service.ErrInvalidModifierGroup)
+		})
+	}
+
+	t.Run("Unknown Product", func(t *testing.T) {
+		_, err := m.modifiers.CreateProductModifierGroup(uuid.New(), &domain.ModifierGroup{Name: "Size", Options: options()})
+		assert.ErrorIs(t, err, service.ErrModifierProductNotFound)
+	})
+
+	t.Run("Update To Minimum Above Maximum", func(t *testing.T) {
+		minSelect := 3
+		_, err := m.modifiers.UpdateProductModifierGroup(m.pizza, m.toppings.ID, &dto.UpdateModifierGroupRequest{MinSelect: &minSelect})
+		assert.ErrorIs(t, err, service.ErrInvalidModifierGroup)
+
+		stored, err := m.modifiers.Repo.GetModifierGroupByID(m.toppings.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored.MinSelect, "rejected updates are not stored")
+	})
+
+	t.Run("Update Through Another Product", func(t *testing.T) {
+		name := "Pasta Toppings"
+		_, err := m.modifiers.UpdateProductModifierGroup(m.pasta, m.toppings.ID, &dto.UpdateModifierGroupRequest{Name: &name})
+		assert.ErrorIs(t, err, service.ErrModifierGroupWrongProduct)
+	})
+}
@@ -0,0 +1,268 @@
+package test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/validator"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// capturingNotifier keeps the last reset link instead of delivering it
+type capturingNotifier struct {
+	link string
+}
+
+func (n *capturingNotifier) SendPasswordReset(user *domain.User, resetLink string, expiresAt time.Time) error {
+	n.link = resetLink
+	return nil
+}
+
+func (n *capturingNotifier) token(t *testing.T) string {
+	link, err := url.Parse(n.link)
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func setupPasswordService(t *testing.T) (*service.PasswordService, *capturingNotifier, *gorm.DB, uuid.UUID) {
+	t.Setenv("JWT_SECRET_KEY", "password-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "refresh_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "family_id" TEXT,
+			"token_hash" TEXT UNIQUE, "expires_at" DATETIME, "created_at" DATETIME, "revoked_at" DATETIME)`,
+		`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER, "last_failed_at" DATETIME, "locked_until" DATETIME)`,
+		`CREATE TABLE "password_reset_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "token_hash" TEXT UNIQUE,
+			"expires_at" DATETIME, "created_at" DATETIME, "used_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	userID := uuid.New()
+	password, err := crypto.HashPassword("old-password1")
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, "Cashier", "cashier@example.com", password, true, time.Now()).Error)
+
+	userRepo := &repository.UserRepository{DB: db}
+	notifier := &capturingNotifier{}
+	return &service.PasswordService{
+		Users:  userRepo,
+		Resets: &repository.PasswordResetRepository{DB: db},
+		Sessions: &service.SessionService{
+			Repo:     &repository.SessionRepository{DB: db},
+			UserRepo: userRepo,
+		},
+		Throttle: &service.LoginThrottle{Repo: &repository.LoginFailureRepository{DB: db}},
+		Notifier: notifier,
+		ResetURL: "https://pos.example.com/reset-password",
+		ResetTTL: time.Hour,
+	}, notifier, db, userID
+}
+
+func storedPasswordMatches(t *testing.T, db *gorm.DB, userID uuid.UUID, password string) bool {
+	var hash string
+	require.NoError(t, db.Raw(`SELECT password FROM users WHERE id = ?`, userID).Scan(&hash).Error)
+	return crypto.CheckPassword(password, hash)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"Letters and digits", "correct-horse-7", true},
+		{"Too short", "abc12", false},
+		{"No digit", "correct-horse", false},
+		{"No letter", "1234567890", false},
+		{"Common password", "Password123", false},
+		{"Contains the email name", "cashier2024", false},
+		{"Longer than bcrypt accepts", strings.Repeat("a1", 40), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.ValidatePassword(tt.password, "cashier@example.com")
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, validator.ErrWeakPassword)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	passwords, _, db, userID := setupPasswordService(t)
+
+	err := passwords.ChangePassword(userID, &dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password2"})
+	assert.ErrorIs(t, err, service.ErrIncorrectPassword)
+
+	err = passwords.ChangePassword(userID, &dto.ChangePasswordRequest{CurrentPassword: "old-password1", NewPassword: "weak"})
+	assert.ErrorIs(t, err, service.ErrWeakPassword)
+
+	err = passwords.ChangePassword(userID, &dto.ChangePasswordRequest{CurrentPassword: "old-password1", NewPassword: "old-password1"})
+	assert.ErrorIs(t, err, service.ErrPasswordUnchanged)
+
+	require.NoError(t, passwords.ChangePassword(userID, &dto.ChangePasswordRequest{CurrentPassword: "old-password1", NewPassword: "new-password2"}))
+	assert.True(t, storedPasswordMatches(t, db, userID, "new-password2"))
+
+	// Every existing session ends with the old password
+	var revokedAt *time.Time
+	require.NoError(t, db.Raw(`SELECT sessions_revoked_at FROM users WHERE id = ?`, userID).Scan(&revokedAt).Error)
+	assert.NotNil(t, revokedAt)
+}
+
+func TestPasswordReset(t *testing.T) {
+	passwords, notifier, db, userID := setupPasswordService(t)
+
+	_, err := passwords.RequestPasswordReset(builtInRolePositions[middleware.RoleOwner], uuid.New())
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+
+	reset, err := passwords.RequestPasswordReset(builtInRolePositions[middleware.RoleOwner], userID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), reset.ExpiresAt, time.Minute)
+	token := notifier.token(t)
+	require.NotEmpty(t, token)
+
+	t.Run("A weak password keeps the link usable", func(t *testing.T) {
+		err := passwords.ResetPassword(&dto.ResetPasswordRequest{Token: token, NewPassword: "short"})
+		assert.ErrorIs(t, err, service.ErrWeakPassword)
+	})
+
+	t.Run("The link resets the password once", func(t *testing.T) {
+		lockedKeys := []string{"account:cashier@example.com", "account:pin:" + userID.String()}
+		for _, key := range lockedKeys {
+			require.NoError(t, db.Exec(`INSERT INTO login_failures (key, failures, last_failed_at, locked_until) VALUES (?, ?, ?, ?)`,
+				key, 5, time.Now(), time.Now().Add(time.Hour)).Error)
+		}
+
+		require.NoError(t, passwords.ResetPassword(&dto.ResetPasswordRequest{Token: token, NewPassword: "reset-password3"}))
+		assert.True(t, storedPasswordMatches(t, db, userID, "reset-password3"))
+
+		// Both the password and the PIN lockouts are lifted
+		var locks int64
+		require.NoError(t, db.Table("login_failures").Where("key IN ?", lockedKeys).Count(&locks).Error)
+		assert.Zero(t, locks)
+
+		err := passwords.ResetPassword(&dto.ResetPasswordRequest{Token: token, NewPassword: "another-password4"})
+		assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+	})
+
+	t.Run("A newer link replaces the previous one", func(t *testing.T) {
+		_, err := passwords.RequestPasswordReset(builtInRolePositions[middleware.RoleOwner], userID)
+		require.NoError(t, err)
+		first := notifier.token(t)
+
+		_, err = passwords.RequestPasswordReset(builtInRolePositions[middleware.RoleOwner], userID)
+		require.NoError(t, err)
+
+		err = passwords.ResetPassword(&dto.ResetPasswordRequest{Token: first, NewPassword: "another-password4"})
+		assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+		require.NoError(t, passwords.ResetPassword(&dto.ResetPasswordRequest{Token: notifier.token(t), NewPassword: "another-password4"}))
+	})
+
+	t.Run("Expired links are rejected", func(t *testing.T) {
+		_, err := passwords.RequestPasswordReset(builtInRolePositions[middleware.RoleOwner], userID)
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`UPDATE password_reset_tokens SET expires_at = ?`, time.Now().Add(-time.Minute)).Error)
+
+		err = passwords.ResetPassword(&dto.ResetPasswordRequest{Token: notifier.token(t), NewPassword: "expired-password5"})
+		assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+	})
+}
+
+func TestConsoleNotifierKeepsResetLinksOutOfTheLog(t *testing.T) {
+	logger := &recordingLogger{}
+	var console strings.Builder
+	notifier := &service.ConsoleNotifier{Logger: logger, Out: &console}
+
+	user := &domain.User{ID: uuid.New(), Email: "cashier@example.com"}
+	link := "https://pos.example.com/reset-password?token=live-reset-token"
+	require.NoError(t, notifier.SendPasswordReset(user, link, time.Now().Add(time.Hour)))
+
+	assert.Contains(t, console.String(), link)
+	require.Len(t, logger.entries, 1)
+	assert.Equal(t, user.ID.String(), logger.entries[0]["user_id"])
+	assert.NotContains(t, fmt.Sprint(logger.entries[0]), "live-reset-token")
+}
+
+func TestPasswordResetRequestRequiresHigherRole(t *testing.T) {
+	db := setupRouteTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE "password_reset_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "token_hash" TEXT UNIQUE,
+		"expires_at" DATETIME, "created_at" DATETIME, "used_at" DATETIME)`).Error)
+	ownerID, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+	adminID, adminToken := createRoleUser(t, db, middleware.RoleAdmin)
+	app := SetupTestApp(db)
+
+	requestReset := func(userID uuid.UUID, token string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/api/v1/users/"+userID.String()+"/password-reset", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, requestReset(ownerID, adminToken))
+	assert.Equal(t, fiber.StatusForbidden, requestReset(adminID, adminToken))
+	assert.Equal(t, fiber.StatusOK, requestReset(adminID, ownerToken))
+
+	var tokens int64
+	require.NoError(t, db.Table("password_reset_tokens").Count(&tokens).Error)
+	assert.Equal(t, int64(1), tokens, "refused requests issue no link")
+}
+
+func TestAdminPasswordChangeEndsSessions(t *testing.T) {
+	db := setupRouteTestDB(t)
+	for _, stmt := range []string{
+		`CREATE TABLE "refresh_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "family_id" TEXT,
+			"token_hash" TEXT UNIQUE, "expires_at" DATETIME, "created_at" DATETIME, "revoked_at" DATETIME)`,
+		`CREATE TABLE "password_reset_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "token_hash" TEXT UNIQUE,
+			"expires_at" DATETIME, "created_at" DATETIME, "used_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+	_, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+	cashierID, cashierToken := createRoleUser(t, db, middleware.RoleCashier)
+	require.NoError(t, db.Exec(`INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+		uuid.New(), cashierID, crypto.HashToken("outstanding-reset-token"), time.Now().Add(time.Hour), time.Now()).Error)
+	app := SetupTestApp(db)
+
+	request := func(method, path, token string, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, fiber.StatusOK, request(fiber.MethodGet, "/api/v1/me", cashierToken, ""))
+	require.Equal(t, fiber.StatusOK, request(fiber.MethodPut, "/api/v1/users/"+cashierID.String(), ownerToken, `{"password":"admin-chosen-password1"}`))
+
+	assert.Equal(t, fiber.StatusUnauthorized, request(fiber.MethodGet, "/api/v1/me", cashierToken, ""), "sessions from before the change are revoked")
+	assert.Equal(t, fiber.StatusBadRequest, request(fiber.MethodPost, "/api/v1/auth/password-reset", "",
+		`{"token":"outstanding-reset-token","new_password":"attacker-password1"}`), "outstanding reset links stop working")
+
+	var password string
+	require.NoError(t, db.Raw(`SELECT password FROM users WHERE id = ?`, cashierID).Scan(&password).Error)
+	assert.True(t, crypto.CheckPassword("admin-chosen-password1", password))
+}
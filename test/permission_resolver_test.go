@@ -0,0 +1,69 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPermissionResolver(t *testing.T) (*service.PermissionResolver, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "permissions" ("id" TEXT PRIMARY KEY, "name" TEXT UNIQUE, "description" TEXT)`,
+		`CREATE TABLE "role_permissions" ("role_id" TEXT, "permission_id" TEXT, PRIMARY KEY ("role_id", "permission_id"))`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	return &service.PermissionResolver{Repo: &repository.RoleRepository{DB: db}, TTL: time.Hour}, db
+}
+
+func insertPermission(t *testing.T, db *gorm.DB, roleID uuid.UUID, name string) {
+	permissionID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO permissions (id, name) VALUES (?, ?)`, permissionID, name).Error)
+	require.NoError(t, db.Exec(`INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)`, roleID, permissionID).Error)
+}
+
+func TestPermissionResolverCustomRole(t *testing.T) {
+	resolver, db := setupPermissionResolver(t)
+
+	roleID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name) VALUES (?, ?)`, roleID, "Barista").Error)
+	insertPermission(t, db, roleID, "read:product")
+
+	permissions, err := resolver.PermissionsForRole(roleID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read:product"}, permissions)
+
+	// Cached until the role is invalidated
+	insertPermission(t, db, roleID, "update:order")
+	permissions, err = resolver.PermissionsForRole(roleID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read:product"}, permissions)
+
+	resolver.Invalidate(roleID)
+	permissions, err = resolver.PermissionsForRole(roleID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"read:product", "update:order"}, permissions)
+}
+
+func TestPermissionResolverUnknownRole(t *testing.T) {
+	resolver, _ := setupPermissionResolver(t)
+
+	permissions, err := resolver.PermissionsForRole(uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+
+	permissions, err = resolver.PermissionsForRole(uuid.Nil)
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+}
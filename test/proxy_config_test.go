@@ -0,0 +1,40 @@
+package test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/latoulicious/siresto-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyConfigFromEnv(t *testing.T) {
+	clientIP := func(trustedProxies string) string {
+		t.Setenv("TRUSTED_PROXIES", trustedProxies)
+		t.Setenv("PROXY_HEADER", "")
+
+		var cfg fiber.Config
+		require.NoError(t, config.ApplyProxyConfigFromEnv(&cfg))
+		app := fiber.New(cfg)
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(c.IP()) })
+
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set("X-Real-IP", "203.0.113.7")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// app.Test connects from 0.0.0.0
+	assert.Equal(t, "0.0.0.0", clientIP(""))
+	assert.Equal(t, "203.0.113.7", clientIP("0.0.0.0/8, 10.0.0.1"))
+	assert.Equal(t, "0.0.0.0", clientIP("10.0.0.1"), "the header of an untrusted peer is ignored")
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1,proxy.local")
+	assert.Error(t, config.ApplyProxyConfigFromEnv(&fiber.Config{}))
+}
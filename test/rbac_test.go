@@ -0,0 +1,113 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/domain"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/validator"
+	"github.com/latoulicious/siresto-backend/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedRolePermissionsRunsOnce(t *testing.T) {
+	resolver, db := setupPermissionResolver(t)
+
+	adminID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name, permissions_seeded) VALUES (?, ?, ?)`, adminID, middleware.RoleAdmin, false).Error)
+	require.NoError(t, migrations.SeedRolePermissions(db))
+
+	permissions, err := resolver.PermissionsForRole(adminID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, middleware.GetDefaultPermissionsForRole(middleware.RoleAdmin), permissions)
+
+	// A permission removed by the Owner is not granted back by later migrations
+	require.NoError(t, db.Exec(`DELETE FROM role_permissions WHERE role_id = ? AND permission_id IN
+		(SELECT id FROM permissions WHERE name = ?)`, adminID, "delete:menu").Error)
+	require.NoError(t, migrations.SeedRolePermissions(db))
+
+	resolver.InvalidateAll()
+	permissions, err = resolver.PermissionsForRole(adminID)
+	require.NoError(t, err)
+	assert.NotContains(t, permissions, "delete:menu")
+	assert.Contains(t, permissions, "update:menu")
+}
+
+func TestRequirePermissionUsesStoredPermissions(t *testing.T) {
+	resolver, db := setupPermissionResolver(t)
+
+	adminID := uuid.New()
+	systemID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name) VALUES (?, ?), (?, ?)`,
+		adminID, middleware.RoleAdmin, systemID, middleware.RoleSystem).Error)
+	insertPermission(t, db, adminID, "read:menu")
+	insertPermission(t, db, systemID, middleware.PermissionFullAccess)
+
+	middleware.SetPermissionResolver(resolver)
+	t.Cleanup(func() { middleware.SetPermissionResolver(nil) })
+
+	request := func(roleID uuid.UUID, roleName, permission string) int {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("roleID", roleID)
+			c.Locals("roleName", roleName)
+			return c.Next()
+		})
+		app.Get("/", middleware.RequirePermission(permission), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Admin is no longer allowed everything by name
+	assert.Equal(t, fiber.StatusOK, request(adminID, middleware.RoleAdmin, "read:menu"))
+	assert.Equal(t, fiber.StatusForbidden, request(adminID, middleware.RoleAdmin, "delete:menu"))
+	assert.Equal(t, fiber.StatusOK, request(systemID, middleware.RoleSystem, "delete:menu"))
+}
+
+func TestPermissionGrants(t *testing.T) {
+	cases := []struct {
+		granted, required string
+		want              bool
+	}{
+		{"read:order", "read:order", true},
+		{"read:order", "update:order", false},
+		{"full:access", "delete:user", true},
+		{"*:order", "delete:order", true},
+		{"*:order", "delete:table", false},
+		{"read:*", "read:report", true},
+		{"read:*", "update:report", false},
+		{"*:*", "manage:roles", true},
+		{"manage:order", "read:order", true},
+		{"manage:order", "delete:order", true},
+		{"manage:order", "export:order", false},
+		{"manage:order", "read:table", false},
+		{"manage:*", "update:menu", true},
+		{"manage:users", "read:users", false},
+		{"manage:roles", "delete:roles", false},
+		{"manage:permissions", "create:permissions", false},
+		{"manage:*", "manage:users", true},
+		{"order", "read:order", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, middleware.PermissionGrants(tc.granted, tc.required), "%s grants %s", tc.granted, tc.required)
+	}
+
+	assert.True(t, middleware.HasPermission([]string{"read:menu", "manage:order"}, "update:order"))
+	assert.False(t, middleware.HasPermission(nil, "read:menu"))
+}
+
+func TestValidateWildcardPermissions(t *testing.T) {
+	for _, name := range []string{"read:order", "*:order", "read:*", "manage:order", "full:access"} {
+		assert.NoError(t, validator.ValidatePermission(&domain.Permission{Name: name}), name)
+	}
+	for _, name := range []string{"", "read", "read:**", "**:order", "bogus:order", "manage:Order!"} {
+		assert.Error(t, validator.ValidatePermission(&domain.Permission{Name: name}), name)
+	}
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/migrations"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupRouteTestDB creates the tables the auth and RBAC middleware read from
+func setupRouteTestDB(t *testing.T) *gorm.DB {
+	t.Setenv("JWT_SECRET_KEY", "route-permissions-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "permissions" ("id" TEXT PRIMARY KEY, "name" TEXT UNIQUE, "description" TEXT)`,
+		`CREATE TABLE "role_permissions" ("role_id" TEXT, "permission_id" TEXT, PRIMARY KEY ("role_id", "permission_id"))`,
+		`CREATE TABLE "revoked_tokens" ("jti" TEXT PRIMARY KEY, "user_id" TEXT, "expires_at" DATETIME, "created_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+	t.Cleanup(func() { middleware.SetPermissionResolver(nil) })
+	return db
+}
+
+// builtInRolePositions are the positions the built-in roles are seeded with
+var builtInRolePositions = map[string]int{
+	middleware.RoleSystem:  1,
+	middleware.RoleOwner:   2,
+	middleware.RoleAdmin:   3,
+	middleware.RoleCashier: 10,
+	middleware.RoleKitchen: 11,
+	middleware.RoleWaiter:  12,
+}
+
+// createRoleUser adds a user with a built-in role and its seeded permissions, and returns their access token
+func createRoleUser(t *testing.T, db *gorm.DB, roleName string) (uuid.UUID, string) {
+	roleID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name, position, permissions_seeded) VALUES (?, ?, ?, ?)`,
+		roleID, roleName, builtInRolePositions[roleName], false).Error)
+	require.NoError(t, migrations.SeedRolePermissions(db))
+
+	userID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, roleName, roleName+"@example.com", "x", true, roleID, time.Now()).Error)
+
+	token, err := jwt.GenerateToken(userID, roleID, roleName, true, nil)
+	require.NoError(t, err)
+	return userID, token
+}
+
+func TestRoutePermissions(t *testing.T) {
+	db := setupRouteTestDB(t)
+	_, token := createRoleUser(t, db, middleware.RoleKitchen)
+
+	// Registering the routes runs the startup check, which panics on an unguarded route
+	app := SetupTestApp(db)
+
+	request := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, request(fiber.MethodDelete, "/api/v1/products/"+uuid.NewString()))
+	assert.Equal(t, fiber.StatusForbidden, request(fiber.MethodPost, "/api/v1/qr-codes"))
+	assert.Equal(t, fiber.StatusForbidden, request(fiber.MethodGet, "/api/v1/logs"))
+	assert.Equal(t, fiber.StatusForbidden, request(fiber.MethodGet, "/api/v1/users"))
+
+	// Kitchen staff may read orders; the request gets past RBAC to the handler
+	assert.NotContains(t, []int{fiber.StatusUnauthorized, fiber.StatusForbidden}, request(fiber.MethodGet, "/api/v1/orders"))
+}
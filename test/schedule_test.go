@@ -0,0 +1,51 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/siresto-backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailabilityScheduleWindows(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	breakfast := &db.AvailabilitySchedule{
+		Windows: []db.AvailabilityWindow{{StartTime: "07:00", EndTime: "11:00"}},
+	}
+	assert.True(t, breakfast.IsAvailableAt(time.Date(2025, 3, 3, 8, 30, 0, 0, loc)))
+	assert.False(t, breakfast.IsAvailableAt(time.Date(2025, 3, 3, 19, 0, 0, 0, loc)))
+	assert.False(t, breakfast.IsAvailableAt(time.Date(2025, 3, 3, 11, 0, 0, 0, loc)))
+
+	// 2025-03-01 is a Saturday
+	weekendLateNight := &db.AvailabilitySchedule{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-31",
+		Windows:   []db.AvailabilityWindow{{Days: []string{"sat", "sun"}, StartTime: "22:00", EndTime: "02:00"}},
+	}
+	assert.True(t, weekendLateNight.IsAvailableAt(time.Date(2025, 3, 1, 23, 0, 0, 0, loc)))
+	assert.True(t, weekendLateNight.IsAvailableAt(time.Date(2025, 3, 3, 1, 0, 0, 0, loc)), "Sunday's window runs into Monday")
+	assert.False(t, weekendLateNight.IsAvailableAt(time.Date(2025, 3, 4, 1, 0, 0, 0, loc)))
+	assert.False(t, weekendLateNight.IsAvailableAt(time.Date(2025, 4, 5, 23, 0, 0, 0, loc)), "outside the date range")
+
+	var always *db.AvailabilitySchedule
+	assert.True(t, always.IsAvailableAt(time.Now()))
+}
+
+func TestAvailabilityScheduleValidate(t *testing.T) {
+	assert.NoError(t, (&db.AvailabilitySchedule{
+		Windows: []db.AvailabilityWindow{{Days: []string{"Mon"}, StartTime: "07:00", EndTime: "11:00"}},
+	}).Validate())
+
+	assert.Error(t, (&db.AvailabilitySchedule{StartDate: "2025-03-10", EndDate: "2025-03-01"}).Validate())
+	assert.Error(t, (&db.AvailabilitySchedule{
+		Windows: []db.AvailabilityWindow{{StartTime: "7am", EndTime: "11:00"}},
+	}).Validate())
+	assert.Error(t, (&db.AvailabilitySchedule{
+		Windows: []db.AvailabilityWindow{{Days: []string{"funday"}, StartTime: "07:00", EndTime: "11:00"}},
+	}).Validate())
+}
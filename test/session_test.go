@@ -0,0 +1,118 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupSessionService(t *testing.T) (*service.SessionService, uuid.UUID) {
+	t.Setenv("JWT_SECRET_KEY", "session-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "refresh_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "family_id" TEXT,
+			"token_hash" TEXT UNIQUE, "expires_at" DATETIME, "created_at" DATETIME, "revoked_at" DATETIME)`,
+		`CREATE TABLE "revoked_tokens" ("jti" TEXT PRIMARY KEY, "user_id" TEXT, "expires_at" DATETIME, "created_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	userID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, "Cashier", "cashier@example.com", "x", true, time.Now()).Error)
+
+	return &service.SessionService{
+		Repo:     &repository.SessionRepository{DB: db},
+		UserRepo: &repository.UserRepository{DB: db},
+	}, userID
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	sessions, userID := setupSessionService(t)
+
+	first, err := sessions.CreateRefreshToken(userID, uuid.New())
+	require.NoError(t, err)
+
+	refreshed, err := sessions.Refresh(first)
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.Token)
+	assert.NotEqual(t, first, refreshed.RefreshToken)
+
+	// Reusing a rotated token fails and ends the whole login
+	_, err = sessions.Refresh(first)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	_, err = sessions.Refresh(refreshed.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	_, err = sessions.Refresh("not-a-token")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	sessions, userID := setupSessionService(t)
+
+	refreshToken, err := sessions.CreateRefreshToken(userID, uuid.New())
+	require.NoError(t, err)
+	accessToken, err := jwt.GenerateToken(userID, uuid.Nil, "", true, nil)
+	require.NoError(t, err)
+	claims, err := jwt.ValidateToken(accessToken)
+	require.NoError(t, err)
+
+	revoked, err := sessions.IsRevoked(claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, sessions.Logout(accessToken, refreshToken))
+
+	revoked, err = sessions.IsRevoked(claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	_, err = sessions.Refresh(refreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	sessions, userID := setupSessionService(t)
+
+	refreshToken, err := sessions.CreateRefreshToken(userID, uuid.New())
+	require.NoError(t, err)
+	accessToken, err := jwt.GenerateToken(userID, uuid.Nil, "", true, nil)
+	require.NoError(t, err)
+	claims, err := jwt.ValidateToken(accessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, sessions.RevokeUserSessions(userID))
+
+	revoked, err := sessions.IsRevoked(claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	_, err = sessions.Refresh(refreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// Tokens issued after the revocation stay valid, even within the same second
+	accessToken, err = jwt.GenerateToken(userID, uuid.Nil, "", true, nil)
+	require.NoError(t, err)
+	newClaims, err := jwt.ValidateToken(accessToken)
+	require.NoError(t, err)
+	revoked, err = sessions.IsRevoked(newClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Tokens of users that no longer exist are rejected
+	claims.UserID = uuid.New()
+	claims.ID = ""
+	revoked, err = sessions.IsRevoked(claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
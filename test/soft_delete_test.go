@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDeleteAndRestoreUser(t *testing.T) {
+	db := setupRouteTestDB(t)
+	// Deleting a user ends their sessions
+	require.NoError(t, db.Exec(`CREATE TABLE "refresh_tokens" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "family_id" TEXT,
+		"token_hash" TEXT UNIQUE, "expires_at" DATETIME, "created_at" DATETIME, "revoked_at" DATETIME)`).Error)
+	_, token := createRoleUser(t, db, middleware.RoleOwner)
+	_, kitchenToken := createRoleUser(t, db, middleware.RoleKitchen)
+	app := SetupTestApp(db)
+
+	request := func(method, path, auth string) (int, []byte) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+auth)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		buf := new(bytes.Buffer)
+		_, err = buf.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, buf.Bytes()
+	}
+
+	roleID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name, position) VALUES (?, ?, ?)`, roleID, middleware.RoleCashier, 4).Error)
+	userID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, "Dana", "dana@example.com", "x", true, roleID, time.Now()).Error)
+
+	status, body := request(fiber.MethodDelete, "/api/v1/users/"+userID.String(), token)
+	require.Equal(t, fiber.StatusOK, status, string(body))
+
+	// The row is kept, only hidden
+	var rows int64
+	require.NoError(t, db.Raw(`SELECT COUNT(*) FROM users WHERE id = ? AND deleted_at IS NOT NULL`, userID).Scan(&rows).Error)
+	assert.Equal(t, int64(1), rows)
+
+	status, body = request(fiber.MethodGet, "/api/v1/users/deleted", token)
+	require.Equal(t, fiber.StatusOK, status, string(body))
+	var deleted struct {
+		Data []dto.DeletedItemResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &deleted))
+	require.Len(t, deleted.Data, 1)
+	assert.Equal(t, userID, deleted.Data[0].ID)
+	assert.Equal(t, "dana@example.com", deleted.Data[0].Detail)
+
+	t.Run("Only admins see deleted items", func(t *testing.T) {
+		status, _ := request(fiber.MethodGet, "/api/v1/users/deleted", kitchenToken)
+		assert.Equal(t, fiber.StatusForbidden, status)
+		status, _ = request(fiber.MethodPost, "/api/v1/users/"+userID.String()+"/restore", kitchenToken)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+
+	t.Run("Restore is refused while the email is taken", func(t *testing.T) {
+		otherID := uuid.New()
+		require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			otherID, "Dana Two", "dana@example.com", "x", true, roleID, time.Now()).Error)
+
+		status, _ := request(fiber.MethodPost, "/api/v1/users/"+userID.String()+"/restore", token)
+		assert.Equal(t, fiber.StatusConflict, status)
+
+		require.NoError(t, db.Exec(`DELETE FROM users WHERE id = ?`, otherID).Error)
+	})
+
+	t.Run("Restore brings the user back", func(t *testing.T) {
+		status, body := request(fiber.MethodPost, "/api/v1/users/"+userID.String()+"/restore", token)
+		require.Equal(t, fiber.StatusOK, status, string(body))
+
+		status, _ = request(fiber.MethodPost, "/api/v1/users/"+userID.String()+"/restore", token)
+		assert.Equal(t, fiber.StatusNotFound, status)
+
+		status, body = request(fiber.MethodGet, "/api/v1/users/deleted", token)
+		require.Equal(t, fiber.StatusOK, status)
+		require.NoError(t, json.Unmarshal(body, &deleted))
+		assert.Empty(t, deleted.Data)
+	})
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalUploader(t *testing.T) {
+	dir := t.TempDir()
+	uploader, err := utils.NewLocalUploader(dir, "http://localhost:8080/uploads/")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads", uploader.RoutePrefix())
+
+	url, err := uploader.Upload(strings.NewReader("image"), "products/abc/full.webp")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/uploads/products/abc/full.webp", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "products", "abc", "full.webp"))
+	require.NoError(t, err)
+	assert.Equal(t, "image", string(data))
+
+	exists, err := uploader.Exists("products/abc/full.webp")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, uploader.Delete("products/abc/full.webp"))
+	exists, err = uploader.Exists("products/abc/full.webp")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Deleting a missing file is not an error
+	assert.NoError(t, uploader.Delete("products/abc/full.webp"))
+
+	_, err = uploader.Upload(strings.NewReader("x"), "../escape.txt")
+	assert.Error(t, err)
+}
+
+func TestMemoryUploader(t *testing.T) {
+	var uploader utils.Uploader = utils.NewMemoryUploader("/uploads")
+
+	url, err := uploader.Upload(strings.NewReader("image"), "products/abc/card.webp")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/products/abc/card.webp", url)
+
+	exists, err := uploader.Exists("products/abc/card.webp")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, uploader.Delete("products/abc/card.webp"))
+	assert.Empty(t, uploader.(*utils.MemoryUploader).Keys())
+}
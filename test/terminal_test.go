@@ -0,0 +1,172 @@
+package test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTerminals(t *testing.T) (*service.TerminalService, *service.SessionService, *gorm.DB) {
+	t.Setenv("JWT_SECRET_KEY", "terminal-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER, "last_failed_at" DATETIME, "locked_until" DATETIME)`,
+		`CREATE TABLE "revoked_tokens" ("jti" TEXT PRIMARY KEY, "user_id" TEXT, "expires_at" DATETIME, "created_at" DATETIME)`,
+		`CREATE TABLE "terminals" ("id" TEXT PRIMARY KEY, "name" TEXT, "key_hash" TEXT UNIQUE, "created_at" DATETIME,
+			"last_used_at" DATETIME, "revoked_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	userRepo := &repository.UserRepository{DB: db}
+	terminalRepo := &repository.TerminalRepository{DB: db}
+	terminals := &service.TerminalService{
+		Repo:     terminalRepo,
+		UserRepo: userRepo,
+		Throttle: &service.LoginThrottle{
+			Repo:          &repository.LoginFailureRepository{DB: db},
+			AccountPolicy: service.LoginPolicy{MaxFailures: 3, BaseLockout: time.Minute, MaxLockout: time.Hour, FailureWindow: 15 * time.Minute},
+		},
+	}
+	sessions := &service.SessionService{
+		Repo:      &repository.SessionRepository{DB: db},
+		UserRepo:  userRepo,
+		Terminals: terminalRepo,
+	}
+	return terminals, sessions, db
+}
+
+// createPinUser inserts a user with the given role and PIN
+func createPinUser(t *testing.T, db *gorm.DB, roleName, pin string) uuid.UUID {
+	roleID, userID := uuid.New(), uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name) VALUES (?, ?)`, roleID, roleName).Error)
+
+	pinHash, err := crypto.HashPassword(pin)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, pin_hash, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, roleName, roleName+"@example.com", "unused", pinHash, true, roleID, time.Now()).Error)
+	return userID
+}
+
+func TestPinLogin(t *testing.T) {
+	terminals, sessions, db := setupTerminals(t)
+	cashierID := createPinUser(t, db, middleware.RoleCashier, "1234")
+	adminID := createPinUser(t, db, middleware.RoleAdmin, "4321")
+
+	registration, err := terminals.RegisterTerminal(&dto.RegisterTerminalRequest{Name: "Front counter"})
+	require.NoError(t, err)
+	require.NotEmpty(t, registration.Key)
+
+	t.Run("Lists staff with a PIN", func(t *testing.T) {
+		staff, err := terminals.ListStaff(registration.Key)
+		require.NoError(t, err)
+		require.Len(t, staff, 1)
+		assert.Equal(t, cashierID, staff[0].ID)
+	})
+
+	t.Run("Cashier signs in with the right PIN", func(t *testing.T) {
+		resp, err := terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Empty(t, resp.RefreshToken)
+
+		claims, err := jwt.ValidateToken(resp.Token)
+		require.NoError(t, err)
+		assert.Equal(t, registration.Terminal.ID, claims.TerminalID)
+		assert.Equal(t, crypto.HashToken(registration.Key), claims.TerminalKeyHash)
+
+		revoked, err := sessions.IsRevoked(claims)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("Wrong PIN is rejected", func(t *testing.T) {
+		_, err := terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "0000"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	})
+
+	t.Run("Non-staff roles cannot use a PIN", func(t *testing.T) {
+		_, err := terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: adminID, Pin: "4321"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrPinLoginNotAllowed)
+	})
+
+	t.Run("Unknown terminal key is rejected", func(t *testing.T) {
+		_, err := terminals.PinLogin("not-a-terminal", &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTerminal)
+	})
+
+	t.Run("Revoking the terminal ends its sessions", func(t *testing.T) {
+		resp, err := terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.1")
+		require.NoError(t, err)
+		claims, err := jwt.ValidateToken(resp.Token)
+		require.NoError(t, err)
+
+		require.NoError(t, terminals.RevokeTerminal(registration.Terminal.ID))
+
+		revoked, err := sessions.IsRevoked(claims)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		_, err = terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTerminal)
+	})
+}
+
+func TestPinLoginLockout(t *testing.T) {
+	terminals, _, db := setupTerminals(t)
+	cashierID := createPinUser(t, db, middleware.RoleCashier, "1234")
+
+	registration, err := terminals.RegisterTerminal(&dto.RegisterTerminalRequest{Name: "Bar"})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "9999"}, "10.0.0.2")
+		require.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+
+	_, err = terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.2")
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+
+	// An admin unlock lifts the PIN lockout too
+	users := &service.UserService{Repo: &repository.UserRepository{DB: db}, Throttle: terminals.Throttle}
+	require.NoError(t, users.UnlockUser(builtInRolePositions[middleware.RoleAdmin], cashierID))
+	_, err = terminals.PinLogin(registration.Key, &dto.PinLoginRequest{UserID: cashierID, Pin: "1234"}, "10.0.0.2")
+	assert.NoError(t, err)
+}
+
+func TestSetPinRequiresHigherRole(t *testing.T) {
+	db := setupRouteTestDB(t)
+	ownerID, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+	_, adminToken := createRoleUser(t, db, middleware.RoleAdmin)
+	cashierID, _ := createRoleUser(t, db, middleware.RoleCashier)
+	app := SetupTestApp(db)
+
+	setPin := func(userID uuid.UUID, token string) int {
+		req := httptest.NewRequest(fiber.MethodPut, "/api/v1/users/"+userID.String()+"/pin", strings.NewReader(`{"pin":"4321"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, setPin(ownerID, adminToken))
+	assert.Equal(t, fiber.StatusOK, setPin(cashierID, ownerToken))
+	assert.Equal(t, fiber.StatusOK, setPin(cashierID, adminToken))
+}
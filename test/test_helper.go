@@ -1,12 +1,15 @@
 package test
 
 import (
+	"testing"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/latoulicious/siresto-backend/internal/domain"
 	"github.com/latoulicious/siresto-backend/internal/middleware"
 	"github.com/latoulicious/siresto-backend/internal/routes"
 	"github.com/latoulicious/siresto-backend/pkg/logger"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -28,6 +31,60 @@ func SetupTestApp(db *gorm.DB) *fiber.App {
 	return app
 }
 
+// createUserTables creates the users and roles tables the way the models declare them,
+// including the unique indexes that only cover rows that are not deleted
+func createUserTables(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, stmt := range []string{
+		`CREATE TABLE "users" ("id" TEXT PRIMARY KEY, "deleted_at" DATETIME, "name" TEXT, "email" TEXT, "password" TEXT, "pin_hash" TEXT,
+			"is_staff" BOOLEAN, "role_id" TEXT, "created_at" DATETIME, "last_login_at" DATETIME, "sessions_revoked_at" DATETIME,
+			"totp_secret" TEXT, "totp_enabled_at" DATETIME, "totp_last_step" INTEGER NOT NULL DEFAULT 0)`,
+		`CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email") WHERE "deleted_at" IS NULL`,
+		`CREATE TABLE "roles" ("id" TEXT PRIMARY KEY, "deleted_at" DATETIME, "name" TEXT, "description" TEXT,
+			"position" INTEGER NOT NULL DEFAULT 100, "is_system" BOOLEAN NOT NULL DEFAULT false, "is_staff" BOOLEAN NOT NULL DEFAULT true,
+			"permissions_seeded" BOOLEAN NOT NULL DEFAULT false, "created_at" DATETIME, "updated_at" DATETIME)`,
+		`CREATE UNIQUE INDEX "idx_roles_name" ON "roles" ("name") WHERE "deleted_at" IS NULL`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+}
+
+// sqliteUUID stands in for uuid_generate_v4() on columns that rows are created without an ID for
+const sqliteUUID = `(lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' ||
+	hex(randomblob(2)) || '-' || hex(randomblob(6))))`
+
+// createOrderTables creates the menu and order tables that placing an order reads and writes
+func createOrderTables(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, stmt := range []string{
+		`CREATE TABLE "categories" ("id" TEXT PRIMARY KEY, "name" TEXT, "is_active" BOOLEAN DEFAULT true, "position" INTEGER DEFAULT 0,
+			"schedule" TEXT, "deleted_at" DATETIME)`,
+		`CREATE TABLE "products" ("id" TEXT PRIMARY KEY, "category_id" TEXT, "name" TEXT, "description" TEXT, "image_url" TEXT,
+			"images" TEXT, "base_price" NUMERIC, "is_available" BOOLEAN DEFAULT true, "position" INTEGER DEFAULT 0, "schedule" TEXT,
+			"is_bundle" BOOLEAN DEFAULT false, "deleted_at" DATETIME)`,
+		`CREATE TABLE "variations" ("id" TEXT PRIMARY KEY DEFAULT ` + sqliteUUID + `, "product_id" TEXT, "is_default" BOOLEAN DEFAULT false,
+			"is_available" BOOLEAN DEFAULT true, "is_required" BOOLEAN DEFAULT false, "variation_type" TEXT, "options" TEXT, "deleted_at" DATETIME)`,
+		`CREATE TABLE "bundle_items" ("id" TEXT PRIMARY KEY, "bundle_id" TEXT, "name" TEXT, "product_id" TEXT, "quantity" INTEGER DEFAULT 1,
+			"position" INTEGER DEFAULT 0)`,
+		`CREATE TABLE "bundle_choices" ("id" TEXT PRIMARY KEY, "bundle_item_id" TEXT, "product_id" TEXT, "price_modifier" NUMERIC DEFAULT 0)`,
+		`CREATE TABLE "modifier_groups" ("id" TEXT PRIMARY KEY, "product_id" TEXT, "name" TEXT, "min_select" INTEGER DEFAULT 0,
+			"max_select" INTEGER DEFAULT 0, "is_available" BOOLEAN DEFAULT true, "position" INTEGER DEFAULT 0, "options" TEXT NOT NULL)`,
+		`CREATE TABLE "orders" ("id" TEXT PRIMARY KEY DEFAULT ` + sqliteUUID + `, "customer_name" TEXT NOT NULL, "customer_phone" TEXT NOT NULL,
+			"table_number" INTEGER NOT NULL, "status" TEXT NOT NULL DEFAULT 'PENDING', "dish_status" TEXT NOT NULL DEFAULT 'Diterima',
+			"total_amount" NUMERIC DEFAULT 0, "notes" TEXT, "created_at" DATETIME DEFAULT CURRENT_TIMESTAMP, "paid_at" DATETIME, "cancelled_at" DATETIME)`,
+		`CREATE TABLE "order_details" ("id" TEXT PRIMARY KEY DEFAULT ` + sqliteUUID + `, "order_id" TEXT NOT NULL, "product_name" TEXT NOT NULL,
+			"variation_name" TEXT, "note" TEXT, "unit_price" NUMERIC NOT NULL, "quantity" INTEGER NOT NULL, "total_price" NUMERIC NOT NULL,
+			"product_id" TEXT, "variation_id" TEXT, "modifiers" TEXT)`,
+		`CREATE TABLE "order_detail_components" ("id" TEXT PRIMARY KEY DEFAULT ` + sqliteUUID + `, "order_detail_id" TEXT NOT NULL,
+			"bundle_item_id" TEXT, "product_id" TEXT, "product_name" TEXT NOT NULL, "quantity" INTEGER NOT NULL, "price_modifier" NUMERIC DEFAULT 0)`,
+		`CREATE TABLE "payments" ("id" TEXT PRIMARY KEY, "order_id" TEXT, "method" TEXT, "amount" NUMERIC, "status" TEXT,
+			"transaction_ref" TEXT, "paid_at" DATETIME)`,
+		`CREATE TABLE "invoices" ("id" TEXT PRIMARY KEY, "order_id" TEXT)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+}
+
 // SetupProtectedRoute creates a test route with JWT protection
 func SetupProtectedRoute(app *fiber.App, method, path string, handler fiber.Handler) {
 	app.Add(method, path, middleware.Protected(), handler)
@@ -0,0 +1,235 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/middleware"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/pkg/crypto"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/latoulicious/siresto-backend/pkg/jwt"
+	"github.com/latoulicious/siresto-backend/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTOTPCodes(t *testing.T) {
+	// RFC 6238 appendix B test vectors for SHA-1, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code)
+	}
+
+	now := time.Unix(1111111109, 0)
+	_, ok := totp.Validate(secret, "081804", now)
+	assert.True(t, ok)
+	_, ok = totp.Validate(secret, "081804", now.Add(2*totp.Period))
+	assert.False(t, ok, "codes older than the allowed skew are rejected")
+	_, ok = totp.Validate(secret, "000000", now)
+	assert.False(t, ok)
+}
+
+func setupTwoFactor(t *testing.T, enforced bool) (*service.UserService, *gorm.DB) {
+	t.Setenv("JWT_SECRET_KEY", "two-factor-test-secret")
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createUserTables(t, db)
+	for _, stmt := range []string{
+		`CREATE TABLE "login_failures" ("key" TEXT PRIMARY KEY, "failures" INTEGER, "last_failed_at" DATETIME, "locked_until" DATETIME)`,
+		`CREATE TABLE "recovery_codes" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "code_hash" TEXT UNIQUE, "created_at" DATETIME, "used_at" DATETIME)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	for name, position := range map[string]int{middleware.RoleOwner: 2, middleware.RoleAdmin: 3, middleware.RoleCashier: 10} {
+		roleID := uuid.New()
+		require.NoError(t, db.Exec(`INSERT INTO roles (id, name, position) VALUES (?, ?, ?)`, roleID, name, position).Error)
+
+		password, err := crypto.HashPassword("correct-password1")
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(`INSERT INTO users (id, name, email, password, is_staff, role_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			uuid.New(), name, name+"@example.com", password, true, roleID, time.Now()).Error)
+	}
+
+	userRepo := &repository.UserRepository{DB: db}
+	throttle := &service.LoginThrottle{Repo: &repository.LoginFailureRepository{DB: db}}
+	return &service.UserService{
+		Repo:     userRepo,
+		Throttle: throttle,
+		TwoFactor: &service.TwoFactorService{
+			Repo:     &repository.TwoFactorRepository{DB: db},
+			Users:    userRepo,
+			Roles:    &repository.RoleRepository{DB: db},
+			Throttle: throttle,
+			Issuer:   "Siresto",
+			Enforced: enforced,
+		},
+	}, db
+}
+
+func userIDByEmail(t *testing.T, db *gorm.DB, email string) uuid.UUID {
+	var id string
+	require.NoError(t, db.Raw(`SELECT id FROM users WHERE email = ?`, email).Scan(&id).Error)
+	return uuid.MustParse(id)
+}
+
+func currentCode(t *testing.T, secret string, offset int64) string {
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	users, db := setupTwoFactor(t, false)
+	ownerID := userIDByEmail(t, db, "Owner@example.com")
+	login := &dto.LoginRequest{Email: "Owner@example.com", Password: "correct-password1"}
+
+	// Without enrollment the password alone completes the login
+	resp, err := users.LoginUser(login, "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.False(t, resp.TwoFactorRequired)
+
+	enrollment, err := users.TwoFactor.BeginEnrollment(ownerID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/")
+
+	_, err = users.TwoFactor.ConfirmEnrollment(ownerID, "000000", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+	recoveryCodes, err := users.TwoFactor.ConfirmEnrollment(ownerID, currentCode(t, enrollment.Secret, -1), "10.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, 10)
+
+	t.Run("Password alone only yields a challenge", func(t *testing.T) {
+		resp, err := users.LoginUser(login, "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, resp.TwoFactorRequired)
+		assert.Empty(t, resp.Token)
+		assert.Empty(t, resp.RefreshToken)
+
+		_, err = jwt.ValidateToken(resp.TwoFactorToken)
+		assert.Error(t, err, "a challenge token is not an access token")
+	})
+
+	t.Run("A TOTP code completes the login once", func(t *testing.T) {
+		challenge, err := users.LoginUser(login, "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: "000000"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+
+		code := currentCode(t, enrollment.Secret, 0)
+		resp, err := users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: code}, "10.0.0.1")
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Token)
+
+		_, err = users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: code}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode, "a code cannot be replayed")
+	})
+
+	t.Run("A recovery code works once", func(t *testing.T) {
+		challenge, err := users.LoginUser(login, "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: recoveryCodes[0]}, "10.0.0.1")
+		require.NoError(t, err)
+		_, err = users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: recoveryCodes[0]}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+
+		status, err := users.TwoFactor.Status(ownerID)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, int64(9), status.RecoveryCodesCount)
+	})
+
+	t.Run("Challenge tokens are not interchangeable", func(t *testing.T) {
+		_, err := users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: resp.Token, Code: "000000"}, "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorToken)
+	})
+}
+
+func TestTwoFactorAvailability(t *testing.T) {
+	users, db := setupTwoFactor(t, false)
+
+	_, err := users.TwoFactor.BeginEnrollment(userIDByEmail(t, db, "Cashier@example.com"))
+	assert.ErrorIs(t, err, service.ErrTwoFactorNotAvailable)
+
+	_, err = users.TwoFactor.BeginEnrollment(userIDByEmail(t, db, "Admin@example.com"))
+	assert.NoError(t, err)
+}
+
+func TestEnforcedTwoFactorEnrollment(t *testing.T) {
+	users, db := setupTwoFactor(t, true)
+	adminID := userIDByEmail(t, db, "Admin@example.com")
+
+	// Staff roles are unaffected by enforcement
+	resp, err := users.LoginUser(&dto.LoginRequest{Email: "Cashier@example.com", Password: "correct-password1"}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	challenge, err := users.LoginUser(&dto.LoginRequest{Email: "Admin@example.com", Password: "correct-password1"}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, challenge.TwoFactorEnrollmentRequired)
+	assert.Empty(t, challenge.Token)
+
+	_, err = users.CompleteTwoFactorLogin(&dto.TwoFactorLoginRequest{TwoFactorToken: challenge.TwoFactorToken, Code: "000000"}, "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidTwoFactorToken, "an enrollment token cannot skip enrollment")
+
+	enrollment, err := users.BeginTwoFactorEnrollment(&dto.TwoFactorEnrollmentRequest{TwoFactorToken: challenge.TwoFactorToken})
+	require.NoError(t, err)
+
+	resp, err = users.CompleteTwoFactorEnrollment(&dto.TwoFactorLoginRequest{
+		TwoFactorToken: challenge.TwoFactorToken,
+		Code:           currentCode(t, enrollment.Secret, 0),
+	}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.Len(t, resp.RecoveryCodes, 10)
+
+	err = users.TwoFactor.Disable(adminID, &dto.DisableTwoFactorRequest{Password: "correct-password1", Code: resp.RecoveryCodes[0]}, "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrTwoFactorRequired)
+
+	// An administrator reset sends the user back through enrollment, but only from a higher role
+	assert.ErrorIs(t, users.TwoFactor.Reset(builtInRolePositions[middleware.RoleAdmin], adminID), service.ErrInsufficientRank)
+	require.NoError(t, users.TwoFactor.Reset(builtInRolePositions[middleware.RoleOwner], adminID))
+	challenge, err = users.LoginUser(&dto.LoginRequest{Email: "Admin@example.com", Password: "correct-password1"}, "10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, challenge.TwoFactorEnrollmentRequired)
+}
+
+func TestTwoFactorResetRequiresHigherRole(t *testing.T) {
+	db := setupRouteTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE "recovery_codes" ("id" TEXT PRIMARY KEY, "user_id" TEXT, "code_hash" TEXT UNIQUE,
+		"created_at" DATETIME, "used_at" DATETIME)`).Error)
+	ownerID, ownerToken := createRoleUser(t, db, middleware.RoleOwner)
+	adminID, adminToken := createRoleUser(t, db, middleware.RoleAdmin)
+	app := SetupTestApp(db)
+
+	reset := func(userID uuid.UUID, token string) int {
+		req := httptest.NewRequest(fiber.MethodDelete, "/api/v1/users/"+userID.String()+"/2fa", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusForbidden, reset(ownerID, adminToken))
+	assert.Equal(t, fiber.StatusForbidden, reset(adminID, adminToken), "users of the same role cannot reset each other")
+	assert.Equal(t, fiber.StatusOK, reset(adminID, ownerToken))
+}
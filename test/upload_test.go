@@ -0,0 +1,153 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/latoulicious/siresto-backend/internal/repository"
+	"github.com/latoulicious/siresto-backend/internal/service"
+	"github.com/latoulicious/siresto-backend/internal/utils"
+	"github.com/latoulicious/siresto-backend/pkg/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPresignedThemeLogoUpload(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE "themes" (
+		"id" TEXT PRIMARY KEY, "name" TEXT, "primary_color" TEXT, "secondary_color" TEXT,
+		"accent_color" TEXT, "background_color" TEXT, "logo_url" TEXT, "favicon_url" TEXT, "is_default" BOOLEAN, "deleted_at" DATETIME
+	)`).Error)
+
+	themeID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO themes (id, name, logo_url) VALUES (?, ?, ?)`,
+		themeID, "Default", "/uploads/themes/"+themeID.String()+"/logo-old.png").Error)
+
+	uploader := utils.NewMemoryUploader("/uploads")
+	_, err = uploader.Upload(strings.NewReader("old"), "themes/"+themeID.String()+"/logo-old.png")
+	require.NoError(t, err)
+
+	uploadService := &service.UploadService{
+		Uploader:     uploader,
+		ThemeService: &service.ThemeService{Repo: &repository.ThemeRepository{DB: db}},
+	}
+
+	_, err = uploadService.PresignUpload(&dto.PresignUploadRequest{
+		Target: service.UploadTargetTheme, TargetID: themeID, Field: "logo", ContentType: "image/gif", Size: 10,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidUploadRequest)
+
+	_, err = uploadService.PresignUpload(&dto.PresignUploadRequest{
+		Target: service.UploadTargetTheme, TargetID: uuid.New(), Field: "logo", ContentType: "image/png", Size: 10,
+	})
+	assert.ErrorIs(t, err, service.ErrUploadTargetNotFound)
+
+	presigned, err := uploadService.PresignUpload(&dto.PresignUploadRequest{
+		Target: service.UploadTargetTheme, TargetID: themeID, Field: "logo", ContentType: "image/png", Size: 10,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(presigned.Key, "themes/"+themeID.String()+"/logo-"))
+	assert.Equal(t, "PUT", presigned.Method)
+
+	confirm := &dto.ConfirmUploadRequest{Target: service.UploadTargetTheme, TargetID: themeID, Field: "logo", Key: presigned.Key}
+
+	// Nothing was uploaded yet
+	_, err = uploadService.ConfirmUpload(confirm)
+	assert.ErrorIs(t, err, service.ErrUploadNotFound)
+
+	// Keys issued for another target are rejected
+	_, err = uploadService.ConfirmUpload(&dto.ConfirmUploadRequest{
+		Target: service.UploadTargetTheme, TargetID: themeID, Field: "favicon", Key: presigned.Key,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidUploadRequest)
+
+	_, err = uploader.Upload(strings.NewReader("new"), presigned.Key)
+	require.NoError(t, err)
+
+	confirmed, err := uploadService.ConfirmUpload(confirm)
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/"+presigned.Key, confirmed.URL)
+
+	var logoURL string
+	require.NoError(t, db.Raw(`SELECT logo_url FROM themes WHERE id = ?`, themeID).Scan(&logoURL).Error)
+	assert.Equal(t, confirmed.URL, logoURL)
+	assert.Equal(t, []string{presigned.Key}, uploader.Keys(), "the replaced logo is removed from storage")
+}
+
+func TestPresignedProductImageUpload(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE "products" ("id" TEXT PRIMARY KEY, "category_id" TEXT, "name" TEXT, "description" TEXT, "image_url" TEXT,
+			"images" TEXT, "base_price" NUMERIC, "is_available" BOOLEAN, "position" INTEGER, "schedule" TEXT, "is_bundle" BOOLEAN, "deleted_at" DATETIME)`,
+		`CREATE TABLE "variations" ("id" TEXT PRIMARY KEY, "product_id" TEXT, "position" INTEGER, "deleted_at" DATETIME)`,
+		`CREATE TABLE "bundle_items" ("id" TEXT PRIMARY KEY, "bundle_id" TEXT, "position" INTEGER)`,
+		`CREATE TABLE "modifier_groups" ("id" TEXT PRIMARY KEY, "product_id" TEXT, "position" INTEGER)`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	productID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO products (id, name, image_url) VALUES (?, ?, ?)`,
+		productID, "Latte", "/uploads/products/"+productID.String()+"/old.webp").Error)
+
+	uploader := utils.NewMemoryUploader("/uploads")
+	_, err = uploader.Upload(strings.NewReader("old"), "products/"+productID.String()+"/old.webp")
+	require.NoError(t, err)
+
+	uploadService := &service.UploadService{
+		Uploader: uploader,
+		ProductService: &service.ProductService{
+			Repo:     &repository.ProductRepository{DB: db},
+			Uploader: uploader,
+		},
+	}
+	presign := func() *dto.ConfirmUploadRequest {
+		presigned, err := uploadService.PresignUpload(&dto.PresignUploadRequest{
+			Target: service.UploadTargetProduct, TargetID: productID, ContentType: "image/png", Size: 1024,
+		})
+		require.NoError(t, err)
+		return &dto.ConfirmUploadRequest{Target: service.UploadTargetProduct, TargetID: productID, Key: presigned.Key}
+	}
+
+	t.Run("Files that are not images are rejected and removed", func(t *testing.T) {
+		confirm := presign()
+		_, err := uploader.Upload(strings.NewReader("not an image"), confirm.Key)
+		require.NoError(t, err)
+
+		_, err = uploadService.ConfirmUpload(confirm)
+		assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+		assert.NotContains(t, uploader.Keys(), confirm.Key)
+
+		var imageURL string
+		require.NoError(t, db.Raw(`SELECT image_url FROM products WHERE id = ?`, productID).Scan(&imageURL).Error)
+		assert.Equal(t, "/uploads/products/"+productID.String()+"/old.webp", imageURL)
+	})
+
+	t.Run("Images are stored as renditions", func(t *testing.T) {
+		confirm := presign()
+		_, err := uploader.Upload(bytes.NewReader(encodeTestPNG(t, 1600, 900)), confirm.Key)
+		require.NoError(t, err)
+
+		confirmed, err := uploadService.ConfirmUpload(confirm)
+		require.NoError(t, err)
+
+		prefix := "products/" + productID.String() + "/"
+		assert.Equal(t, "/uploads/"+prefix+"full.webp", confirmed.URL)
+		assert.ElementsMatch(t, []string{prefix + "thumbnail.webp", prefix + "card.webp", prefix + "full.webp"}, uploader.Keys(),
+			"the original and the replaced image are removed from storage")
+
+		var product struct {
+			ImageURL string
+			Images   string
+		}
+		require.NoError(t, db.Raw(`SELECT image_url, images FROM products WHERE id = ?`, productID).Scan(&product).Error)
+		assert.Equal(t, confirmed.URL, product.ImageURL)
+		assert.Contains(t, product.Images, prefix+"thumbnail.webp")
+	})
+}